@@ -0,0 +1,165 @@
+// Command gofns exposes lib/converters on the command line.
+//
+//	gofns convert [flags] [input]
+//
+// convert reads a file, or stdin when the input is "-" or missing, in any registered format
+// and writes it in another to stdout or the --out file. Formats default to the file
+// extensions, and the input format is detected from the content when it has none.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/skhatri/go-fns/lib/converters"
+	"github.com/skhatri/go-fns/lib/fs"
+)
+
+const usage = `usage: gofns <command> [flags]
+
+commands:
+  convert   convert between JSON, JSON5, YAML, TOML, XML, .env, properties and other registered formats
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes a command and returns the process exit code: 0 on success, 1 when the command
+// fails and 2 for usage errors.
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	switch args[0] {
+	case "convert":
+		return convert(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "gofns: unknown command %q\n%s", args[0], usage)
+		return 2
+	}
+}
+
+func convert(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	from := flags.String("from", "", "input format, extension or MIME type; defaults to the input extension, then detection")
+	to := flags.String("to", "", "output format, extension or MIME type; defaults to the --out extension")
+	out := flags.String("out", "", "output file, written atomically; stdout when empty")
+	flags.StringVar(out, "o", "", "shorthand for --out")
+	opts := converters.ConvertOptions{}
+	flags.BoolVar(&opts.Pretty, "pretty", false, "indent JSON output")
+	flags.BoolVar(&opts.SortKeys, "sort-keys", false, "sort mapping keys")
+	flags.BoolVar(&opts.Array, "array", false, "wrap all documents in a single list")
+	flags.IntVar(&opts.Document, "document", 0, "convert only this 1-based document of a multi-document input")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gofns convert [flags] [input]")
+		flags.PrintDefaults()
+	}
+
+	// Flags may follow the input, as in "gofns convert config.yaml --to json".
+	inputs := make([]string, 0)
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return 0
+			}
+			return 2
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		inputs = append(inputs, args[0])
+		args = args[1:]
+	}
+	if len(inputs) > 1 {
+		fmt.Fprintf(stderr, "gofns convert: expected one input, got %d\n", len(inputs))
+		return 2
+	}
+	input := "-"
+	if len(inputs) == 1 {
+		input = inputs[0]
+	}
+
+	if *from == "" && input != "-" {
+		_, inner, _ := converters.CompressionForFile(input)
+		if codec, ok := converters.CodecForFile(inner); ok {
+			*from = string(codec.Format)
+		}
+	}
+	var compression converters.Compression
+	compressed := false
+	if *out != "" {
+		var inner string
+		compression, inner, compressed = converters.CompressionForFile(*out)
+		if *to == "" {
+			if codec, ok := converters.CodecForFile(inner); ok {
+				*to = string(codec.Format)
+			}
+		}
+	}
+	if *to == "" {
+		fmt.Fprintln(stderr, "gofns convert: --to is required unless the --out extension names a format")
+		return 2
+	}
+
+	content, err := readInput(input, stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "gofns convert: %v\n", err)
+		return 1
+	}
+	converted, err := converters.Convert(content, *from, *to, opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "gofns convert: input: [%s], error: [%v]\n", input, err)
+		return 1
+	}
+	if *out == "" {
+		if _, err := stdout.Write(converted); err != nil {
+			fmt.Fprintf(stderr, "gofns convert: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	if compressed {
+		if converted, err = converters.Compress(compression, converted); err != nil {
+			fmt.Fprintf(stderr, "gofns convert: file: [%s], error: [%v]\n", *out, err)
+			return 1
+		}
+	}
+	if err := fs.WriteFileAtomic(*out, converted); err != nil {
+		fmt.Fprintf(stderr, "gofns convert: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// readInput reads a file, or stdin for "-", decompressing gzip and other registered formats.
+func readInput(input string, stdin io.Reader) ([]byte, error) {
+	src := stdin
+	if input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		src = file
+	}
+	reader, err := converters.DecompressReader(src)
+	if err != nil {
+		return nil, fmt.Errorf("input: [%s], error: [%v]", input, err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("input: [%s], error: [%v]", input, err)
+	}
+	return content, nil
+}
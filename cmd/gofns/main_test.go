@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skhatri/go-fns/lib/converters"
+)
+
+func runCommand(stdin string, args ...string) (int, string, string) {
+	stdout, stderr := bytes.Buffer{}, bytes.Buffer{}
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestConvertStdin(t *testing.T) {
+	code, out, errOut := runCommand("b: 1\na: [x, y]\n", "convert", "--to", "json", "--sort-keys", "--pretty")
+	expected := "{\n  \"a\": [\n    \"x\",\n    \"y\"\n  ],\n  \"b\": 1\n}\n"
+	if code != 0 || out != expected {
+		t.Errorf("Expected %q, got %d %q %q", expected, code, out, errOut)
+	}
+
+	code, out, _ = runCommand("a: 1\n---\na: 2\n", "convert", "-", "-to", "json", "-document", "2")
+	if code != 0 || out != "{\"a\":2}\n" {
+		t.Errorf("Unexpected document output %d %q", code, out)
+	}
+
+	code, out, _ = runCommand("{\"a\":1}\n{\"a\":2}\n", "convert", "--to", "yaml", "--array")
+	if code != 0 || out != "- a: 1\n- a: 2\n" {
+		t.Errorf("Unexpected array output %d %q", code, out)
+	}
+}
+
+func TestConvertFiles(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "app.toml")
+	if err := os.WriteFile(input, []byte("[db]\nhost = \"localhost\"\nport = 5432\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(dir, "app.properties")
+	if code, _, errOut := runCommand("", "convert", input, "-o", output); code != 0 {
+		t.Fatalf("Expected success, got %d %s", code, errOut)
+	}
+	content, err := os.ReadFile(output)
+	if err != nil || string(content) != "db.host=localhost\ndb.port=5432\n" {
+		t.Errorf("Unexpected properties %q, %v", content, err)
+	}
+
+	compressed := filepath.Join(dir, "app.env.json.gz")
+	if code, _, errOut := runCommand("", "convert", "--out", compressed, output); code != 0 {
+		t.Fatalf("Expected success, got %d %s", code, errOut)
+	}
+	file, err := os.Open(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := converters.ReadTo(reader, &decoded, "json"); err != nil || decoded["db"].(map[string]interface{})["port"] != 5432.0 {
+		t.Errorf("Unexpected compressed output %v, %v", decoded, err)
+	}
+
+	if code, out, _ := runCommand("", "convert", compressed, "--to", "env"); code != 0 || out != "db='{\"host\":\"localhost\",\"port\":5432}'\n" {
+		t.Errorf("Unexpected env output %d %q", code, out)
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	for _, tc := range []struct {
+		args    []string
+		code    int
+		message string
+	}{
+		{nil, 2, "usage: gofns"},
+		{[]string{"unknown"}, 2, `unknown command "unknown"`},
+		{[]string{"convert"}, 2, "--to is required"},
+		{[]string{"convert", "a", "b", "--to", "json"}, 2, "expected one input, got 2"},
+		{[]string{"convert", "--bogus"}, 2, "flag provided but not defined"},
+		{[]string{"convert", "--to", "ini"}, 1, "format: [ini], error: [no codec registered]"},
+		{[]string{"convert", "missing.yaml", "--to", "json"}, 1, "missing.yaml"},
+		{[]string{"convert", "--from", "json", "--to", "yaml"}, 1, "input: [-], error: [format: [json]"},
+	} {
+		code, _, errOut := runCommand("{", tc.args...)
+		if code != tc.code || !strings.Contains(errOut, tc.message) {
+			t.Errorf("Args %v: expected %d %q, got %d %q", tc.args, tc.code, tc.message, code, errOut)
+		}
+	}
+
+	if code, out, _ := runCommand("", "help"); code != 0 || !strings.Contains(out, "convert") {
+		t.Errorf("Unexpected help %d %q", code, out)
+	}
+}
@@ -1,6 +1,8 @@
 // Package collections provides utility functions for working with Go collections like maps and sets.
 package collections
 
+import "fmt"
+
 // CopyAttribute copies a value from a source map to a target map using the specified key.
 // If the key exists in the source map, it will be copied to the target map with the same key.
 // Returns true if the copy was successful, false otherwise.
@@ -28,14 +30,15 @@ func FilteredByKey[K comparable, V any](m map[K]V, pred func(key K) bool) map[K]
 
 // MapByStringKey creates a new map by applying a transformation function to each value in the input map.
 // The transformation function receives the key and value of each entry and returns a new value.
-// The keys remain unchanged in the resulting map.
+// The keys remain unchanged in the resulting map, except that non-string keys, such as the
+// integer keys YAML decodes, are converted to strings with fmt.Sprint.
 func MapByStringKey(source interface{}) interface{} {
 	switch source.(type) {
 	case map[interface{}]interface{}:
 		m := source.(map[interface{}]interface{})
 		result := make(map[string]interface{}, len(m))
 		for k, v := range m {
-			result[k.(string)] = MapByStringKey(v)
+			result[fmt.Sprint(k)] = MapByStringKey(v)
 		}
 		return result
 
@@ -170,6 +170,19 @@ func TestMapByStringKey(t *testing.T) {
 				map[string]interface{}{"key": "value"},
 			},
 		},
+		{
+			name: "non-string keys",
+			input: map[interface{}]interface{}{
+				1:     "a",
+				true:  map[interface{}]interface{}{2.5: "b"},
+				"key": "c",
+			},
+			expected: map[string]interface{}{
+				"1":    "a",
+				"true": map[string]interface{}{"2.5": "b"},
+				"key":  "c",
+			},
+		},
 		{
 			name:     "non-map value",
 			input:    "string value",
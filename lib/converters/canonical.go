@@ -0,0 +1,181 @@
+package converters
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// MarshalCanonicalJson marshals item to canonical JSON as defined by RFC 8785 (JSON
+// Canonicalization Scheme), so equal data always produces identical bytes for hashing and
+// signing. The item is first marshaled with encoding/json, honouring json tags and
+// marshalers, then written without whitespace, with object keys sorted by UTF-16 code
+// units, strings escaped minimally and numbers formatted as ECMAScript doubles.
+// Returns an error if item cannot be marshaled or holds a number that is not a finite double.
+func MarshalCanonicalJson(item interface{}) ([]byte, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling %T, with error %w", item, err)
+	}
+	return CanonicalJson(data)
+}
+
+// CanonicalJson rewrites a JSON document in RFC 8785 canonical form.
+// Returns an error if content is not valid JSON or holds a number that is not a finite double.
+func CanonicalJson(content []byte) ([]byte, error) {
+	var tree interface{}
+	if err := decodeJsonNumbers(content, &tree); err != nil {
+		return nil, err
+	}
+	bff := bytes.Buffer{}
+	if err := writeCanonical(&bff, tree); err != nil {
+		return nil, err
+	}
+	return bff.Bytes(), nil
+}
+
+// CanonicalJsonSha256 returns the hex encoded SHA-256 digest of item's canonical JSON.
+// Returns an error if item cannot be canonicalized.
+func CanonicalJsonSha256(item interface{}) (string, error) {
+	data, err := MarshalCanonicalJson(item)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func writeCanonical(bff *bytes.Buffer, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		bff.WriteString("null")
+	case bool:
+		bff.WriteString(strconv.FormatBool(v))
+	case string:
+		writeCanonicalString(bff, v)
+	case json.Number:
+		number, err := strconv.ParseFloat(v.String(), 64)
+		if err != nil || math.IsInf(number, 0) {
+			return fmt.Errorf("number %s is not representable as a double", v)
+		}
+		bff.WriteString(canonicalNumber(number))
+	case []interface{}:
+		bff.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				bff.WriteByte(',')
+			}
+			if err := writeCanonical(bff, item); err != nil {
+				return err
+			}
+		}
+		bff.WriteByte(']')
+	case map[string]interface{}:
+		keys := sortedKeys(v)
+		sort.SliceStable(keys, func(i, j int) bool {
+			return lessUtf16(keys[i], keys[j])
+		})
+		bff.WriteByte('{')
+		for i, key := range keys {
+			if i > 0 {
+				bff.WriteByte(',')
+			}
+			writeCanonicalString(bff, key)
+			bff.WriteByte(':')
+			if err := writeCanonical(bff, v[key]); err != nil {
+				return err
+			}
+		}
+		bff.WriteByte('}')
+	default:
+		return fmt.Errorf("unsupported value %T", value)
+	}
+	return nil
+}
+
+// writeCanonicalString escapes only quotes, backslashes and control characters, using the
+// short forms where JSON has them and lowercase \u00xx otherwise.
+func writeCanonicalString(bff *bytes.Buffer, s string) {
+	bff.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			bff.WriteString(`\"`)
+		case '\\':
+			bff.WriteString(`\\`)
+		case '\b':
+			bff.WriteString(`\b`)
+		case '\f':
+			bff.WriteString(`\f`)
+		case '\n':
+			bff.WriteString(`\n`)
+		case '\r':
+			bff.WriteString(`\r`)
+		case '\t':
+			bff.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(bff, `\u%04x`, r)
+			} else {
+				bff.WriteRune(r)
+			}
+		}
+	}
+	bff.WriteByte('"')
+}
+
+// canonicalNumber formats a double like ECMAScript's Number.prototype.toString: the shortest
+// round-tripping digits, in plain notation for magnitudes from 1e-6 up to 1e21 and in
+// exponent notation such as 1e+21 or 1.5e-7 outside that range.
+func canonicalNumber(number float64) string {
+	if number == 0 {
+		return "0"
+	}
+	sign := ""
+	if number < 0 {
+		sign = "-"
+		number = -number
+	}
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(number, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	power, _ := strconv.Atoi(exponent)
+	k := len(digits)
+	n := power + 1
+
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits
+	}
+	exponentPart := "e+" + strconv.Itoa(n-1)
+	if n-1 < 0 {
+		exponentPart = "e-" + strconv.Itoa(1-n)
+	}
+	fraction := ""
+	if k > 1 {
+		fraction = "." + digits[1:]
+	}
+	return sign + digits[:1] + fraction + exponentPart
+}
+
+// lessUtf16 orders strings by their UTF-16 code units, as RFC 8785 requires for object keys.
+func lessUtf16(a string, b string) bool {
+	x := utf16.Encode([]rune(a))
+	y := utf16.Encode([]rune(b))
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if x[i] != y[i] {
+			return x[i] < y[i]
+		}
+	}
+	return len(x) < len(y)
+}
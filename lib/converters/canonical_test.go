@@ -0,0 +1,116 @@
+package converters
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalJson(t *testing.T) {
+	t.Run("rfc 8785 example", func(t *testing.T) {
+		input := `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`
+		expected := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+		out, err := CanonicalJson([]byte(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != expected {
+			t.Errorf("Expected %s, got %s", expected, out)
+		}
+	})
+
+	t.Run("keys sort by utf-16 code units", func(t *testing.T) {
+		input := `{"€":"Euro Sign","\r":"Carriage Return","דּ":"Hebrew Letter Dalet With Dagesh","1":"One","😀":"Emoji: Grinning Face","\u0080":"Control","ö":"Latin Small Letter O With Diaeresis"}`
+		out, err := CanonicalJson([]byte(input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		order := []string{"Carriage Return", "One", "Control", "Latin Small", "Euro Sign", "Emoji", "Hebrew"}
+		last := -1
+		for _, name := range order {
+			at := strings.Index(string(out), name)
+			if at < last {
+				t.Errorf("Expected %s after the previous key in %s", name, out)
+			}
+			last = at
+		}
+	})
+
+	t.Run("numbers", func(t *testing.T) {
+		for bits, expected := range map[uint64]string{
+			0x0000000000000000: "0",
+			0x8000000000000000: "0",
+			0x0000000000000001: "5e-324",
+			0x8000000000000001: "-5e-324",
+			0x7fefffffffffffff: "1.7976931348623157e+308",
+			0xffefffffffffffff: "-1.7976931348623157e+308",
+			0x4340000000000000: "9007199254740992",
+			0xc340000000000000: "-9007199254740992",
+			0x4430000000000000: "295147905179352830000",
+			0x44b52d02c7e14af5: "9.999999999999997e+22",
+			0x44b52d02c7e14af6: "1e+23",
+			0x44b52d02c7e14af7: "1.0000000000000001e+23",
+			0x444b1ae4d6e2ef4e: "999999999999999700000",
+			0x444b1ae4d6e2ef4f: "999999999999999900000",
+			0x444b1ae4d6e2ef50: "1e+21",
+			0x3eb0c6f7a0b5ed8c: "9.999999999999997e-7",
+			0x3eb0c6f7a0b5ed8d: "0.000001",
+			0x41b3de4355555553: "333333333.3333332",
+			0x41b3de4355555554: "333333333.33333325",
+			0x41b3de4355555555: "333333333.3333333",
+			0xc1b3de4355555555: "-333333333.3333333",
+		} {
+			if actual := canonicalNumber(math.Float64frombits(bits)); actual != expected {
+				t.Errorf("Bits %016x: expected %s, got %s", bits, expected, actual)
+			}
+		}
+	})
+
+	t.Run("structs and hashing", func(t *testing.T) {
+		type item struct {
+			Name  string            `json:"name"`
+			Count int               `json:"count"`
+			Tags  map[string]string `json:"tags"`
+		}
+		out, err := MarshalCanonicalJson(item{Name: "<a&b>", Count: 10, Tags: map[string]string{"z": "1", "a": "2"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != `{"count":10,"name":"<a&b>","tags":{"a":"2","z":"1"}}` {
+			t.Errorf("Unexpected output %s", out)
+		}
+
+		first, err := CanonicalJsonSha256(map[string]interface{}{"b": 1.0, "a": []int{1, 2}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded interface{}
+		_ = UnmarshalYaml([]byte("a: [1, 2]\nb: 1\n"), &decoded)
+		second, err := CanonicalJsonSha256(decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first != second || len(first) != 64 {
+			t.Errorf("Expected equal digests, got %s and %s", first, second)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := CanonicalJson([]byte(`{"a":`)); err == nil {
+			t.Error("Expected error for invalid JSON")
+		}
+		if _, err := CanonicalJson([]byte(`[1e400]`)); err == nil || !strings.Contains(err.Error(), "not representable") {
+			t.Errorf("Expected range error, got %v", err)
+		}
+		if _, err := MarshalCanonicalJson(errorMarshaler{}); err == nil {
+			t.Error("Expected marshal error")
+		}
+		if _, err := CanonicalJsonSha256(math.Inf(1)); err == nil {
+			t.Error("Expected error for infinity")
+		}
+	})
+}
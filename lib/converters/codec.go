@@ -0,0 +1,280 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format names a data format that a Codec reads and writes.
+type Format string
+
+const (
+	FormatJSON       Format = "json"
+	FormatYAML       Format = "yaml"
+	FormatTOML       Format = "toml"
+	FormatCSV        Format = "csv"
+	FormatEnv        Format = "env"
+	FormatProperties Format = "properties"
+	FormatXML        Format = "xml"
+	FormatJSON5      Format = "json5"
+)
+
+// ErrNoCodec is returned, wrapped with the format, when no codec is registered for a format.
+var ErrNoCodec = errors.New("no codec registered")
+
+// Codec reads and writes one format. Extensions, with their leading dot, and MIME types
+// let LookupCodec and CodecForFile find it.
+type Codec struct {
+	Format     Format
+	Extensions []string
+	MimeTypes  []string
+	Unmarshal  func(content []byte, t interface{}) error
+	Marshal    func(t interface{}) ([]byte, error)
+}
+
+var codecs = struct {
+	sync.RWMutex
+	byFormat map[Format]Codec
+}{byFormat: make(map[Format]Codec)}
+
+func init() {
+	RegisterCodec(Codec{
+		Format:     FormatJSON,
+		Extensions: []string{".json"},
+		MimeTypes:  []string{"application/json", "text/json"},
+		Unmarshal:  unmarshalJsonContent,
+		Marshal: func(t interface{}) ([]byte, error) {
+			return marshalJson(t, false)
+		},
+	})
+	RegisterCodec(Codec{
+		Format:     FormatYAML,
+		Extensions: []string{".yaml", ".yml"},
+		MimeTypes:  []string{"application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml"},
+		Unmarshal:  UnmarshalYaml,
+		Marshal: func(t interface{}) ([]byte, error) {
+			return yaml.Marshal(t)
+		},
+	})
+	RegisterCodec(Codec{
+		Format:     FormatCSV,
+		Extensions: []string{".csv"},
+		MimeTypes:  []string{"text/csv"},
+		Unmarshal:  UnmarshalCsv,
+		Marshal:    MarshalCsv,
+	})
+	RegisterCodec(Codec{
+		Format:     FormatTOML,
+		Extensions: []string{".toml"},
+		MimeTypes:  []string{"application/toml"},
+		Unmarshal:  UnmarshalToml,
+		Marshal:    MarshalToml,
+	})
+	RegisterCodec(Codec{
+		Format:     FormatEnv,
+		Extensions: []string{".env"},
+		Unmarshal:  UnmarshalEnv,
+		Marshal:    MarshalEnv,
+	})
+	RegisterCodec(Codec{
+		Format:     FormatProperties,
+		Extensions: []string{".properties"},
+		MimeTypes:  []string{"text/x-java-properties"},
+		Unmarshal:  UnmarshalProperties,
+		Marshal:    MarshalProperties,
+	})
+	RegisterCodec(Codec{
+		Format:     FormatXML,
+		Extensions: []string{".xml"},
+		MimeTypes:  []string{"application/xml", "text/xml"},
+		Unmarshal:  UnmarshalXml,
+		Marshal:    MarshalXml,
+	})
+	RegisterCodec(Codec{
+		Format:     FormatJSON5,
+		Extensions: []string{".json5"},
+		MimeTypes:  []string{"application/json5"},
+		Unmarshal:  UnmarshalJson5,
+		Marshal:    MarshalJson5,
+	})
+}
+
+// RegisterCodec adds a codec, replacing any codec registered for the same format, so further
+// formats can be supported and built-in ones replaced.
+func RegisterCodec(codec Codec) {
+	codecs.Lock()
+	defer codecs.Unlock()
+	codecs.byFormat[codec.Format] = codec
+}
+
+// RegisteredFormats returns the formats that have a codec, sorted by name.
+func RegisteredFormats() []Format {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	formats := make([]Format, 0, len(codecs.byFormat))
+	for format := range codecs.byFormat {
+		formats = append(formats, format)
+	}
+	sort.Slice(formats, func(i, j int) bool {
+		return formats[i] < formats[j]
+	})
+	return formats
+}
+
+// LookupCodec finds a codec by format name ("yaml"), file extension (".yml") or MIME type
+// ("application/json; charset=utf-8"). MIME types with a structured syntax suffix, such as
+// "application/merge-patch+json", resolve to the suffix's format.
+func LookupCodec(name string) (Codec, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if mediaType, _, err := mime.ParseMediaType(name); err == nil {
+		name = mediaType
+	}
+	codecs.RLock()
+	defer codecs.RUnlock()
+	if codec, ok := codecs.byFormat[Format(name)]; ok {
+		return codec, true
+	}
+	for _, codec := range codecs.byFormat {
+		for _, candidate := range append(append([]string{}, codec.Extensions...), codec.MimeTypes...) {
+			if strings.EqualFold(candidate, name) {
+				return codec, true
+			}
+		}
+	}
+	if plus := strings.LastIndexByte(name, '+'); plus >= 0 && strings.Contains(name, "/") {
+		if codec, ok := codecs.byFormat[Format(name[plus+1:])]; ok {
+			return codec, true
+		}
+	}
+	return Codec{}, false
+}
+
+// CodecForFile finds the codec registered for a file's extension.
+func CodecForFile(file string) (Codec, bool) {
+	ext := filepath.Ext(file)
+	if ext == "" {
+		return Codec{}, false
+	}
+	return LookupCodec(ext)
+}
+
+var (
+	tomlTablePattern    = regexp.MustCompile(`^\[\[?\s*[A-Za-z0-9_"'.\- ]+\s*\]\]?\s*(#.*)?$`)
+	tomlKeyValuePattern = regexp.MustCompile(`^[A-Za-z0-9_"'.\-]+\s*=`)
+)
+
+// DetectFormat guesses the format of content: JSON for valid JSON or content starting with
+// "{" or "[", YAML for a "---" or "%YAML" marker, XML for content starting with "<", TOML
+// for a leading [table] header or key = value line, CSV for a header line followed by rows
+// of the same width, and YAML otherwise.
+func DetectFormat(content []byte) Format {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if len(trimmed) == 0 || json.Valid(trimmed) {
+		return FormatJSON
+	}
+	if bytes.HasPrefix(trimmed, []byte("---")) || bytes.HasPrefix(trimmed, []byte("%YAML")) {
+		return FormatYAML
+	}
+	if trimmed[0] == '<' {
+		return FormatXML
+	}
+
+	firstLine := ""
+	for _, line := range strings.Split(string(trimmed), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			firstLine = line
+			break
+		}
+	}
+	switch {
+	case tomlTablePattern.MatchString(firstLine) && !strings.Contains(firstLine, ","):
+		return FormatTOML
+	case trimmed[0] == '{' || trimmed[0] == '[':
+		return FormatJSON
+	case tomlKeyValuePattern.MatchString(firstLine):
+		return FormatTOML
+	case looksLikeCsv(trimmed):
+		return FormatCSV
+	}
+	return FormatYAML
+}
+
+// looksLikeCsv reports whether the first lines of content parse as at least two CSV records
+// of the same width, with a header that is not a YAML mapping key.
+func looksLikeCsv(content []byte) bool {
+	header, _, _ := strings.Cut(string(content), "\n")
+	if !strings.Contains(header, ",") || strings.HasPrefix(header, "- ") || strings.Contains(header, ": ") || strings.HasSuffix(strings.TrimSpace(header), ":") {
+		return false
+	}
+	reader := csv.NewReader(bytes.NewReader(content))
+	width := 0
+	for i := 0; i < 5; i++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return i >= 2
+		}
+		if err != nil {
+			return false
+		}
+		if i == 0 {
+			width = len(record)
+		} else if len(record) != width {
+			return false
+		}
+	}
+	return width >= 2
+}
+
+// ReadTo reads data from a reader and unmarshals it into the provided data structure.
+// The format is determined by the provided format string ("json" or "yaml", or any
+// registered format, extension or MIME type), or detected with DetectFormat when omitted.
+// Compressed content is decompressed first, see DecompressReader.
+// Returns an error if the data cannot be read or unmarshaled.
+func ReadTo(src io.Reader, t interface{}, format ...string) error {
+	name := ""
+	if len(format) > 0 {
+		name = format[0]
+	}
+	_, err := ReadToFormat(src, t, name)
+	return err
+}
+
+// ReadToFormat reads data from a reader and unmarshals it with the codec for format, a
+// format name, extension or MIME type, or with the codec for the detected format when
+// format is empty. Content compressed with a registered compression, such as gzip, is
+// decompressed first. It returns the format that was used.
+// Returns an error if the data cannot be read, no codec handles the format, or the data
+// cannot be unmarshaled.
+func ReadToFormat(src io.Reader, t interface{}, format string) (Format, error) {
+	reader, err := DecompressReader(src)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	bb := bytes.Buffer{}
+	if _, err := bb.ReadFrom(reader); err != nil {
+		return "", err
+	}
+	detected := Format(format)
+	if format == "" {
+		detected = DetectFormat(bb.Bytes())
+	}
+	codec, ok := LookupCodec(string(detected))
+	if !ok {
+		return detected, fmt.Errorf("format: [%s], error: [%w]", detected, ErrNoCodec)
+	}
+	return codec.Format, codec.Unmarshal(bb.Bytes(), t)
+}
@@ -0,0 +1,218 @@
+package converters
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	for content, expected := range map[string]Format{
+		`{"name": "test"}`:                  FormatJSON,
+		"\xef\xbb\xbf  [1, 2, 3]":           FormatJSON,
+		`{"broken": `:                       FormatJSON,
+		"":                                  FormatJSON,
+		"42":                                FormatJSON,
+		"[not toml, json]":                  FormatJSON,
+		"---\nname: test\n":                 FormatYAML,
+		"%YAML 1.2\n---\na: 1\n":            FormatYAML,
+		"name: test\nvalue: 42\n":           FormatYAML,
+		"- a\n- b\n":                        FormatYAML,
+		"- name,value\n- other,1\n":         FormatYAML,
+		"name: a, b\nvalue: c, d\n":         FormatYAML,
+		"servers:\n  - a,b\n  - c,d\n":      FormatYAML,
+		"just a plain scalar":               FormatYAML,
+		"# settings\n[server]\nport = 80\n": FormatTOML,
+		"[[servers]]\nname = \"a\"\n":       FormatTOML,
+		"[table.sub-table] # comment\n":     FormatTOML,
+		"title = \"TOML\"\n[owner]\n":       FormatTOML,
+		"name,value\ntest,42\nother,7\n":    FormatCSV,
+		"name,value\na,\"quoted, comma\"\n": FormatCSV,
+		"time,value\n12:30,1\n":             FormatCSV,
+		"name,value\n":                      FormatYAML,
+		"name,value\na,1\nb,2,3\n":          FormatYAML,
+		"name,value\n\"unterminated,1\n":    FormatYAML,
+		"<?xml version=\"1.0\"?>\n<a/>":     FormatXML,
+		"\n  <config><a>1</a></config>":     FormatXML,
+	} {
+		if actual := DetectFormat([]byte(content)); actual != expected {
+			t.Errorf("Content %q: expected %s, got %s", content, expected, actual)
+		}
+	}
+}
+
+func TestLookupCodec(t *testing.T) {
+	for name, expected := range map[string]Format{
+		"json":                            FormatJSON,
+		"JSON":                            FormatJSON,
+		".json":                           FormatJSON,
+		"application/json":                FormatJSON,
+		"application/json; charset=utf-8": FormatJSON,
+		"application/merge-patch+json":    FormatJSON,
+		"yaml":                            FormatYAML,
+		".yml":                            FormatYAML,
+		"application/x-yaml":              FormatYAML,
+		"application/vnd.api+yaml":        FormatYAML,
+		"text/csv":                        FormatCSV,
+		".csv":                            FormatCSV,
+		"toml":                            FormatTOML,
+		"application/toml":                FormatTOML,
+		".env":                            FormatEnv,
+		"text/x-java-properties":          FormatProperties,
+		"application/xml":                 FormatXML,
+		"application/atom+xml":            FormatXML,
+		".xml":                            FormatXML,
+		".json5":                          FormatJSON5,
+		"application/json5":               FormatJSON5,
+	} {
+		codec, ok := LookupCodec(name)
+		if !ok || codec.Format != expected {
+			t.Errorf("Name %s: expected %s, got %s, %v", name, expected, codec.Format, ok)
+		}
+	}
+	for _, name := range []string{"ini", "application/x-hcl", "", "text/plain+unknown"} {
+		if _, ok := LookupCodec(name); ok {
+			t.Errorf("Name %s: expected no codec", name)
+		}
+	}
+	if codec, ok := CodecForFile("/etc/app/config.YAML"); !ok || codec.Format != FormatYAML {
+		t.Errorf("Expected yaml codec, got %v, %v", codec.Format, ok)
+	}
+	if _, ok := CodecForFile("Makefile"); ok {
+		t.Error("Expected no codec without an extension")
+	}
+	formats := RegisteredFormats()
+	builtIn := map[Format]bool{FormatCSV: true, FormatEnv: true, FormatJSON: true, FormatProperties: true, FormatJSON5: true, FormatTOML: true, FormatXML: true, FormatYAML: true}
+	for i, format := range formats {
+		delete(builtIn, format)
+		if i > 0 && formats[i-1] >= format {
+			t.Errorf("Expected sorted formats, got %v", formats)
+		}
+	}
+	if len(builtIn) > 0 {
+		t.Errorf("Missing formats %v in %v", builtIn, formats)
+	}
+}
+
+func TestRegisterCodec(t *testing.T) {
+	const format Format = "upper-test"
+	RegisterCodec(Codec{
+		Format:     format,
+		Extensions: []string{".upper"},
+		MimeTypes:  []string{"text/x-upper"},
+		Unmarshal: func(content []byte, t interface{}) error {
+			*(t.(*string)) = strings.ToUpper(string(content))
+			return nil
+		},
+		Marshal: func(t interface{}) ([]byte, error) {
+			return []byte(strings.ToLower(t.(string))), nil
+		},
+	})
+
+	var out string
+	detected, err := ReadToFormat(strings.NewReader("shout"), &out, "text/x-upper")
+	if err != nil || detected != format || out != "SHOUT" {
+		t.Errorf("Unexpected result %s, %q, %v", detected, out, err)
+	}
+	codec, _ := CodecForFile("a.upper")
+	if data, _ := codec.Marshal("QUIET"); string(data) != "quiet" {
+		t.Errorf("Unexpected marshal output %s", data)
+	}
+}
+
+func TestReadToFormat(t *testing.T) {
+	t.Run("detected formats", func(t *testing.T) {
+		for content, expected := range map[string]Format{
+			`{"name": "test", "value": 42}`: FormatJSON,
+			"name: test\nvalue: 42\n":       FormatYAML,
+			"---\nname: test\nvalue: 42\n":  FormatYAML,
+		} {
+			var result TestStruct
+			detected, err := ReadToFormat(strings.NewReader(content), &result, "")
+			if err != nil || detected != expected {
+				t.Errorf("Content %q: expected %s, got %s, %v", content, expected, detected, err)
+			}
+			if result.Name != "test" || result.Value != 42 {
+				t.Errorf("Content %q: unexpected result %+v", content, result)
+			}
+		}
+
+		var rows []TestStruct
+		detected, err := ReadToFormat(strings.NewReader("name,value\na,1\nb,2\n"), &rows, "")
+		if err != nil || detected != FormatCSV || len(rows) != 2 || rows[1].Value != 2 {
+			t.Errorf("Unexpected csv result %s, %+v, %v", detected, rows, err)
+		}
+	})
+
+	t.Run("explicit format and mime type", func(t *testing.T) {
+		var result TestStruct
+		detected, err := ReadToFormat(strings.NewReader("name: test"), &result, "application/yaml")
+		if err != nil || detected != FormatYAML || result.Name != "test" {
+			t.Errorf("Unexpected result %s, %+v, %v", detected, result, err)
+		}
+		if err := ReadTo(strings.NewReader("name: x"), &result, "json"); err == nil {
+			t.Error("Expected JSON decoding of YAML content to fail")
+		}
+		if err := ReadTo(strings.NewReader("name: yaml"), &result); err != nil || result.Name != "yaml" {
+			t.Errorf("Expected YAML to be detected, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("no codec", func(t *testing.T) {
+		var result map[string]interface{}
+		detected, err := ReadToFormat(strings.NewReader("[server]\nport = 80\n"), &result, "")
+		if detected != FormatTOML || err != nil || result["server"].(map[string]interface{})["port"] != 80 {
+			t.Errorf("Expected toml to be detected, got %s, %v, %v", detected, result, err)
+		}
+		_, err = ReadToFormat(strings.NewReader("[server]"), &result, "ini")
+		if !errors.Is(err, ErrNoCodec) || !strings.Contains(err.Error(), "format: [ini]") {
+			t.Errorf("Expected missing ini codec, got %v", err)
+		}
+		if _, err := ReadToFormat(strings.NewReader("x"), &result, "application/x-hcl"); !errors.Is(err, ErrNoCodec) {
+			t.Errorf("Expected missing codec, got %v", err)
+		}
+		if _, err := ReadToFormat(&errorReader{}, &result, ""); err == nil {
+			t.Error("Expected read error")
+		}
+	})
+}
+
+type roundTripConfig struct {
+	Name   string
+	Port   int
+	DbHost string  `json:"dbHost" yaml:"db_host"`
+	Ratio  float64 `json:"ratio" yaml:"ratio"`
+}
+
+func TestMarshalFileRoundTrip(t *testing.T) {
+	original := roundTripConfig{Name: "api", Port: 8080, DbHost: "db.internal", Ratio: 0.5}
+	for _, extension := range []string{".json5", ".yaml", ".toml", ".env", ".properties"} {
+		file := filepath.Join(t.TempDir(), "config"+extension)
+		if err := MarshalFile(original, file); err != nil {
+			t.Fatalf("%s: %v", extension, err)
+		}
+		var decoded roundTripConfig
+		if err := UnmarshalFile(file, &decoded); err != nil || decoded != original {
+			t.Errorf("%s: expected %+v, got %+v, %v", extension, original, decoded, err)
+		}
+	}
+}
+
+func TestUnmarshalFileJsonYamlTags(t *testing.T) {
+	type yamlOnly struct {
+		DbHost string `yaml:"db_host"`
+		Port   int    `yaml:"port"`
+	}
+	file := writeTestFile(t, filepath.Join(t.TempDir(), "app.json"), `{"db_host": "h", "port": 5}`)
+	var decoded yamlOnly
+	if err := UnmarshalFile(file, &decoded); err != nil || decoded != (yamlOnly{DbHost: "h", Port: 5}) {
+		t.Errorf("Expected yaml tags to match in .json files, got %+v, %v", decoded, err)
+	}
+	var relaxed yamlOnly
+	EnableJson5ForJson(true)
+	defer EnableJson5ForJson(false)
+	if err := UnmarshalFile(file, &relaxed); err != nil || relaxed != (yamlOnly{Port: 5}) {
+		t.Errorf("Expected json matching once JSON5 is enabled for .json, got %+v, %v", relaxed, err)
+	}
+}
@@ -0,0 +1,163 @@
+package converters
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrNoCompressor is returned, wrapped with the extension, when a compression format is
+// recognised but has no reader or writer registered, such as .zst until one is plugged in
+// with RegisterCompression, or writing .bz2.
+var ErrNoCompressor = errors.New("no compressor registered")
+
+// Compression describes a compression format by its file extension, with its leading dot,
+// and the magic bytes that start compressed content. Match, when set, confirms that content
+// starting with Magic is in the format, for magic bytes short enough to start plain text; it
+// is given up to the first 16 bytes. NewReader decompresses and NewWriter compresses; either
+// may be nil when the format is only read or only recognised.
+type Compression struct {
+	Extension string
+	Magic     []byte
+	Match     func(head []byte) bool
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+}
+
+// compressionHeaderSize is the number of leading bytes given to Compression.Match.
+const compressionHeaderSize = 16
+
+var (
+	bzip2BlockMagic = []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}
+	bzip2EndMagic   = []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90}
+)
+
+var compressions = struct {
+	sync.RWMutex
+	byExtension map[string]Compression
+}{byExtension: make(map[string]Compression)}
+
+func init() {
+	RegisterCompression(Compression{
+		Extension: ".gz",
+		Magic:     []byte{0x1f, 0x8b},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+	})
+	RegisterCompression(Compression{
+		Extension: ".bz2",
+		Magic:     []byte("BZh"),
+		Match:     isBzip2Header,
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(bzip2.NewReader(r)), nil
+		},
+	})
+	RegisterCompression(Compression{
+		Extension: ".zst",
+		Magic:     []byte{0x28, 0xb5, 0x2f, 0xfd},
+	})
+}
+
+// RegisterCompression adds a compression format, replacing any registered for the same
+// extension. Zstandard is recognised by its .zst extension and magic bytes but needs a
+// decompressor, for example one wrapping github.com/klauspost/compress/zstd:
+//
+//	converters.RegisterCompression(converters.Compression{
+//	    Extension: ".zst",
+//	    Magic:     []byte{0x28, 0xb5, 0x2f, 0xfd},
+//	    NewReader: func(r io.Reader) (io.ReadCloser, error) {
+//	        d, err := zstd.NewReader(r)
+//	        if err != nil {
+//	            return nil, err
+//	        }
+//	        return d.IOReadCloser(), nil
+//	    },
+//	})
+func RegisterCompression(compression Compression) {
+	compressions.Lock()
+	defer compressions.Unlock()
+	compressions.byExtension[strings.ToLower(compression.Extension)] = compression
+}
+
+// CompressionForFile finds the compression registered for a file's extension and returns it
+// with the file name stripped of that extension, so "dump.yaml.zst" gives ".zst" and
+// "dump.yaml". The name is returned unchanged when it has no compression extension.
+func CompressionForFile(file string) (Compression, string, bool) {
+	ext := filepath.Ext(file)
+	compressions.RLock()
+	defer compressions.RUnlock()
+	compression, ok := compressions.byExtension[strings.ToLower(ext)]
+	if !ok || ext == "" {
+		return Compression{}, file, false
+	}
+	return compression, strings.TrimSuffix(file, ext), true
+}
+
+// DecompressReader returns a reader of the decompressed content of src when src starts with
+// the magic bytes of a registered compression, and a reader of src as is otherwise.
+// Returns an error, wrapping ErrNoCompressor, when the compression has no reader.
+func DecompressReader(src io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(src)
+	compressions.RLock()
+	defer compressions.RUnlock()
+	for _, compression := range compressions.byExtension {
+		if len(compression.Magic) == 0 {
+			continue
+		}
+		head, _ := buffered.Peek(len(compression.Magic))
+		if !bytes.Equal(head, compression.Magic) {
+			continue
+		}
+		if compression.Match != nil {
+			if head, _ = buffered.Peek(compressionHeaderSize); !compression.Match(head) {
+				continue
+			}
+		}
+		if compression.NewReader == nil {
+			return nil, fmt.Errorf("compression: [%s], error: [%w]", compression.Extension, ErrNoCompressor)
+		}
+		return compression.NewReader(buffered)
+	}
+	return io.NopCloser(buffered), nil
+}
+
+// isBzip2Header reports whether head starts a bzip2 stream: "BZh", a block size digit from
+// 1 to 9, then the magic of the first block, or of the stream end when it is empty.
+func isBzip2Header(head []byte) bool {
+	if len(head) < 10 || head[3] < '1' || head[3] > '9' {
+		return false
+	}
+	return bytes.Equal(head[4:10], bzip2BlockMagic) || bytes.Equal(head[4:10], bzip2EndMagic)
+}
+
+// Compress compresses data with the compression's writer.
+// Returns an error, wrapping ErrNoCompressor, when the compression has no writer.
+func Compress(compression Compression, data []byte) ([]byte, error) {
+	if compression.NewWriter == nil {
+		return nil, fmt.Errorf("compression: [%s], error: [%w]", compression.Extension, ErrNoCompressor)
+	}
+	bff := bytes.Buffer{}
+	writer, err := compression.NewWriter(&bff)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return bff.Bytes(), nil
+}
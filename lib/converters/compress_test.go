@@ -0,0 +1,209 @@
+package converters
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/skhatri/go-fns/lib/fs"
+)
+
+// bzip2 of "name: test\nvalue: 42\n"; the standard library only decompresses bzip2.
+var bzip2Fixture = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x6c, 0x42, 0xbf, 0x3c, 0x00, 0x00,
+	0x07, 0xd9, 0x80, 0x00, 0x10, 0x40, 0x00, 0x14, 0x10, 0x22, 0x07, 0x0f, 0x00, 0x20, 0x00, 0x21,
+	0xa9, 0xa1, 0xea, 0x69, 0xa7, 0xa6, 0xa1, 0x00, 0x00, 0x32, 0x88, 0x26, 0x43, 0x4e, 0xfa, 0x82,
+	0x97, 0x4f, 0x7f, 0x17, 0x72, 0x45, 0x38, 0x50, 0x90, 0x6c, 0x42, 0xbf, 0x3c,
+}
+
+func gzipBytes(t *testing.T, content string) []byte {
+	t.Helper()
+	bff := bytes.Buffer{}
+	writer := gzip.NewWriter(&bff)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bff.Bytes()
+}
+
+func TestUnmarshalFileCompressed(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string][]byte{
+		"config.json.gz": gzipBytes(t, `{"name": "test", "value": 42}`),
+		"config.YAML.GZ": gzipBytes(t, "name: test\nvalue: 42\n"),
+		"dump.yaml.bz2":  bzip2Fixture,
+		"rows.csv.gz":    gzipBytes(t, "name,value\ntest,42\n"),
+	} {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		var result TestStruct
+		var err error
+		if strings.HasPrefix(name, "rows") {
+			var rows []TestStruct
+			err = UnmarshalFile(file, &rows)
+			if len(rows) == 1 {
+				result = rows[0]
+			}
+		} else {
+			err = UnmarshalFile(file, &result)
+		}
+		if err != nil || result.Name != "test" || result.Value != 42 {
+			t.Errorf("File %s: unexpected result %+v, %v", name, result, err)
+		}
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		var result TestStruct
+		corrupt := filepath.Join(dir, "corrupt.json.gz")
+		if err := os.WriteFile(corrupt, []byte(`{"name": "plain"}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := UnmarshalFile(corrupt, &result); err == nil {
+			t.Error("Expected gzip header error")
+		}
+
+		zst := filepath.Join(dir, "dump.yaml.zst")
+		if err := os.WriteFile(zst, []byte{0x28, 0xb5, 0x2f, 0xfd}, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := UnmarshalFile(zst, &result); !errors.Is(err, ErrNoCompressor) || !strings.Contains(err.Error(), ".zst") {
+			t.Errorf("Expected missing zstd decompressor, got %v", err)
+		}
+		if err := UnmarshalJsonFile(filepath.Join(dir, "missing.json.gz"), &result); err == nil {
+			t.Error("Expected error for missing file")
+		}
+	})
+}
+
+func TestMarshalFile(t *testing.T) {
+	dir := t.TempDir()
+	data := TestStruct{Name: "test", Value: 42}
+
+	t.Run("round trip", func(t *testing.T) {
+		for _, name := range []string{"out.json", "out.yaml", "out.json.gz", "out.yml.gz"} {
+			file := filepath.Join(dir, name)
+			if err := MarshalFile(data, file); err != nil {
+				t.Fatalf("File %s: %v", name, err)
+			}
+			var result TestStruct
+			if err := UnmarshalFile(file, &result); err != nil || result != data {
+				t.Errorf("File %s: unexpected result %+v, %v", name, result, err)
+			}
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, "out.json.gz"))
+		if err != nil || !bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+			t.Errorf("Expected gzip content, got %q, %v", content, err)
+		}
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		file := filepath.Join(dir, "nested", "out.yml.gz")
+		if err := MarshalFileWithOptions(data, file, fs.WriteOptions{Mode: 0600, CreateDirs: true}); err != nil {
+			t.Fatal(err)
+		}
+		var result TestStruct
+		if err := UnmarshalFile(file, &result); err != nil || result != data {
+			t.Errorf("Unexpected result %+v, %v", result, err)
+		}
+		if info, err := os.Stat(file); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("Expected mode 0600, got %v, %v", info, err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if err := MarshalFile(data, filepath.Join(dir, "out.bz2")); !errors.Is(err, ErrNoCodec) {
+			t.Errorf("Expected missing codec, got %v", err)
+		}
+		if err := MarshalFile(data, filepath.Join(dir, "out.yaml.bz2")); !errors.Is(err, ErrNoCompressor) {
+			t.Errorf("Expected missing bzip2 compressor, got %v", err)
+		}
+		if err := MarshalFile(data, filepath.Join(dir, "out.txt")); !errors.Is(err, ErrNoCodec) {
+			t.Errorf("Expected missing codec, got %v", err)
+		}
+		if err := MarshalFile(errorMarshaler{}, filepath.Join(dir, "out.json.gz")); err == nil {
+			t.Error("Expected marshal error")
+		}
+	})
+}
+
+func TestRegisterCompression(t *testing.T) {
+	RegisterCompression(Compression{
+		Extension: ".b64test",
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(base64.NewDecoder(base64.StdEncoding, r)), nil
+		},
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return base64.NewEncoder(base64.StdEncoding, w), nil
+		},
+	})
+
+	file := filepath.Join(t.TempDir(), "config.json.b64test")
+	if err := MarshalFile(TestStruct{Name: "encoded"}, file); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(file)
+	if bytes.Contains(content, []byte("encoded")) {
+		t.Errorf("Expected encoded content, got %s", content)
+	}
+	var result TestStruct
+	if err := UnmarshalFile(file, &result); err != nil || result.Name != "encoded" {
+		t.Errorf("Unexpected result %+v, %v", result, err)
+	}
+
+	compression, name, ok := CompressionForFile("/tmp/dump.YAML.GZ")
+	if !ok || compression.Extension != ".gz" || name != "/tmp/dump.YAML" {
+		t.Errorf("Unexpected compression %s, %s, %v", compression.Extension, name, ok)
+	}
+	if _, name, ok := CompressionForFile("config.yaml"); ok || name != "config.yaml" {
+		t.Errorf("Expected no compression, got %s, %v", name, ok)
+	}
+}
+
+func TestReadToCompressed(t *testing.T) {
+	var result TestStruct
+	if err := ReadTo(bytes.NewReader(gzipBytes(t, `{"name": "test", "value": 42}`)), &result); err != nil || result.Value != 42 {
+		t.Errorf("Unexpected gzip result %+v, %v", result, err)
+	}
+	result = TestStruct{}
+	detected, err := ReadToFormat(bytes.NewReader(bzip2Fixture), &result, "")
+	if err != nil || detected != FormatYAML || result.Name != "test" {
+		t.Errorf("Unexpected bzip2 result %s, %+v, %v", detected, result, err)
+	}
+	if err := ReadTo(bytes.NewReader([]byte{0x28, 0xb5, 0x2f, 0xfd, 0x00}), &result); !errors.Is(err, ErrNoCompressor) {
+		t.Errorf("Expected missing zstd decompressor, got %v", err)
+	}
+
+	for _, plain := range []string{"BZh", "BZh9 is not compressed", "BZhx1AY&SY", "BZh91AY&SX trailing"} {
+		reader, err := DecompressReader(strings.NewReader(plain))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if content, _ := io.ReadAll(reader); string(content) != plain {
+			t.Errorf("Expected text starting with BZh to pass through, got %q", content)
+		}
+	}
+	var text map[string]interface{}
+	if err := ReadTo(strings.NewReader("BZh: 1\n"), &text); err != nil || text["BZh"] != 1 {
+		t.Errorf("Expected plain YAML starting with BZh to decode, got %v, %v", text, err)
+	}
+
+	reader, err := DecompressReader(strings.NewReader("B"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := io.ReadAll(reader); string(content) != "B" {
+		t.Errorf("Expected short plain content to pass through, got %q", content)
+	}
+}
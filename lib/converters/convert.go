@@ -0,0 +1,285 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConvertOptions controls Convert.
+type ConvertOptions struct {
+	// Pretty indents JSON output. Other formats are always written one entry per line.
+	Pretty bool
+	// SortKeys orders mapping keys alphabetically instead of keeping the input order.
+	SortKeys bool
+	// Document selects a single document, 1-based, from a multi-document input. Zero keeps all.
+	Document int
+	// Array wraps all documents in a single list, so they can be written to one-document formats.
+	Array bool
+}
+
+// Convert reads content in one registered format and writes it in another, keeping key order
+// where both formats have one. An empty from detects the input format with DetectFormat.
+// Multi-document YAML and streams of JSON values are converted document by document: YAML
+// output separates them with "---" and JSON output writes one value per line.
+// Returns an error if either format has no codec, the content cannot be read, or the output
+// format cannot hold several documents.
+func Convert(content []byte, from string, to string, opts ConvertOptions) ([]byte, error) {
+	if from == "" {
+		from = string(DetectFormat(content))
+	}
+	source, ok := LookupCodec(from)
+	if !ok {
+		return nil, fmt.Errorf("format: [%s], error: [%w]", from, ErrNoCodec)
+	}
+	target, ok := LookupCodec(to)
+	if !ok {
+		return nil, fmt.Errorf("format: [%s], error: [%w]", to, ErrNoCodec)
+	}
+
+	documents, err := convertDocuments(content, source)
+	if err != nil {
+		return nil, fmt.Errorf("format: [%s], error: [%v]", source.Format, err)
+	}
+	if opts.Document > 0 {
+		if opts.Document > len(documents) {
+			return nil, fmt.Errorf("format: [%s], error: [document %d requested, content has %d]", source.Format, opts.Document, len(documents))
+		}
+		documents = documents[opts.Document-1 : opts.Document]
+	}
+	if opts.Array {
+		documents = []*yaml.Node{{Kind: yaml.SequenceNode, Tag: "!!seq", Content: documents}}
+	}
+	if opts.SortKeys {
+		for _, document := range documents {
+			sortNodeKeys(document, make(map[*yaml.Node]bool))
+		}
+	}
+
+	out, err := writeDocuments(documents, target, opts)
+	if err != nil {
+		return nil, fmt.Errorf("format: [%s], error: [%v]", target.Format, err)
+	}
+	return out, nil
+}
+
+// convertDocuments parses content into one node per document. YAML, JSON and JSON5 keep their
+// key order; other formats are decoded by their codec into a generic tree.
+func convertDocuments(content []byte, codec Codec) ([]*yaml.Node, error) {
+	documents := make([]*yaml.Node, 0)
+	switch codec.Format {
+	case FormatYAML:
+		nodes, err := UnmarshalYamlNodes(content)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			documents = append(documents, node.Content[0])
+		}
+	case FormatJSON5:
+		return parseJson5Documents(content)
+	case FormatJSON:
+		if json5ForJson.Load() {
+			return parseJson5Documents(content)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		for index := 1; ; index++ {
+			var raw json.RawMessage
+			err := decoder.Decode(&raw)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("document: [%d], error: [%v]", index, err)
+			}
+			node, err := jsonNode(raw)
+			if err != nil {
+				return nil, fmt.Errorf("document: [%d], error: [%v]", index, err)
+			}
+			documents = append(documents, node.Content[0])
+		}
+	default:
+		var tree interface{}
+		if err := codec.Unmarshal(content, &tree); err != nil {
+			return nil, err
+		}
+		node, err := treeNode(tree)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, node)
+	}
+	return documents, nil
+}
+
+func writeDocuments(documents []*yaml.Node, codec Codec, opts ConvertOptions) ([]byte, error) {
+	bff := bytes.Buffer{}
+	switch codec.Format {
+	case FormatYAML:
+		encoder := yaml.NewEncoder(&bff)
+		encoder.SetIndent(DefaultYamlIndent)
+		for _, document := range documents {
+			if err := encoder.Encode(document); err != nil {
+				return nil, err
+			}
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	case FormatJSON, FormatJSON5:
+		for _, document := range documents {
+			compact := bytes.Buffer{}
+			if err := writeJsonNode(&compact, document); err != nil {
+				return nil, err
+			}
+			if opts.Pretty {
+				if err := json.Indent(&bff, compact.Bytes(), "", "  "); err != nil {
+					return nil, err
+				}
+			} else {
+				bff.Write(compact.Bytes())
+			}
+			bff.WriteByte('\n')
+		}
+	default:
+		if len(documents) > 1 {
+			return nil, fmt.Errorf("cannot hold %d documents, select one or wrap them in an array", len(documents))
+		}
+		var tree interface{}
+		if len(documents) == 1 {
+			if err := documents[0].Decode(&tree); err != nil {
+				return nil, err
+			}
+		}
+		out, err := codec.Marshal(tree)
+		if err != nil {
+			return nil, err
+		}
+		bff.Write(out)
+	}
+	return bff.Bytes(), nil
+}
+
+// writeJsonNode writes a node as compact JSON in its own key order. Numbers keep their
+// source text when it is valid JSON, so 1.50 stays 1.50.
+func writeJsonNode(b *bytes.Buffer, node *yaml.Node) error {
+	node = resolveAlias(node)
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(node.Content); i += 2 {
+			if node.Content[i].Tag == "!!merge" {
+				// Merged keys are resolved by the decoder, at the cost of this mapping's key order.
+				return writeJsonValue(b, node)
+			}
+		}
+		b.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			key, err := json.Marshal(resolveAlias(node.Content[i]).Value)
+			if err != nil {
+				return err
+			}
+			b.Write(key)
+			b.WriteByte(':')
+			if err := writeJsonNode(b, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case yaml.SequenceNode:
+		b.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeJsonNode(b, item); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case yaml.ScalarNode:
+		if tag := node.ShortTag(); (tag == "!!int" || tag == "!!float") && json.Valid([]byte(node.Value)) {
+			b.WriteString(node.Value)
+			return nil
+		}
+		if node.ShortTag() == "!!timestamp" {
+			// Keep timestamps as written rather than reformatting them through time.Time.
+			text, err := json.Marshal(node.Value)
+			if err != nil {
+				return err
+			}
+			b.Write(text)
+			return nil
+		}
+		return writeJsonValue(b, node)
+	default:
+		return writeJsonValue(b, node)
+	}
+	return nil
+}
+
+func writeJsonValue(b *bytes.Buffer, node *yaml.Node) error {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return err
+	}
+	text, err := json.Marshal(jsonCompatible(value))
+	if err != nil {
+		return err
+	}
+	b.Write(text)
+	return nil
+}
+
+// jsonCompatible converts the map[interface{}]interface{} values yaml produces for
+// non-string keys into maps json can encode.
+func jsonCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = jsonCompatible(item)
+		}
+		return m
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = jsonCompatible(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonCompatible(item)
+		}
+	}
+	return value
+}
+
+// sortNodeKeys orders the keys of every mapping under node, following aliases once.
+func sortNodeKeys(node *yaml.Node, seen map[*yaml.Node]bool) {
+	node = resolveAlias(node)
+	if seen[node] {
+		return
+	}
+	seen[node] = true
+	if node.Kind == yaml.MappingNode {
+		pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+		}
+		sort.SliceStable(pairs, func(i, j int) bool {
+			return pairs[i][0].Value < pairs[j][0].Value
+		})
+		for i, pair := range pairs {
+			node.Content[2*i], node.Content[2*i+1] = pair[0], pair[1]
+		}
+	}
+	for _, child := range node.Content {
+		sortNodeKeys(child, seen)
+	}
+}
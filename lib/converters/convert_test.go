@@ -0,0 +1,120 @@
+package converters
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	yamlContent := `# service
+name: api
+port: 8080
+ratio: 1.50
+started: 2024-01-02
+defaults: &defaults
+  retries: 3
+db:
+  <<: *defaults
+  host: localhost
+tags: [b, a]
+`
+	out, err := Convert([]byte(yamlContent), "yaml", "json", ConvertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"name":"api","port":8080,"ratio":1.50,"started":"2024-01-02","defaults":{"retries":3},"db":{"host":"localhost","retries":3},"tags":["b","a"]}` + "\n"
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+
+	out, err = Convert([]byte(`{"b": {"d": 1, "c": [true, null]}, "a": "x"}`), "", "json", ConvertOptions{Pretty: true, SortKeys: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = `{
+  "a": "x",
+  "b": {
+    "c": [
+      true,
+      null
+    ],
+    "d": 1
+  }
+}
+`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+
+	out, err = Convert([]byte(`{"z": "true", "a": {"n": 1e3}}`), "application/json", ".yml", ConvertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "z: \"true\"\na:\n  n: 1e3\n" {
+		t.Errorf("Unexpected yaml %q", out)
+	}
+
+	out, err = Convert([]byte("title = \"x\"\n[db]\nport = 5432\nratio = 2.0\n"), "toml", "properties", ConvertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "db.port=5432\ndb.ratio=2\ntitle=x\n" {
+		t.Errorf("Unexpected properties %q", out)
+	}
+
+	out, err = Convert([]byte("db.port=5432\ndb.host=localhost\n"), "properties", "toml", ConvertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "[db]\nhost = \"localhost\"\nport = 5432\n" {
+		t.Errorf("Unexpected toml %q", out)
+	}
+
+	out, err = Convert([]byte("PORT=8080\nNAME='my app'\n"), "env", "yaml", ConvertOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "NAME: my app\nPORT: 8080\n" {
+		t.Errorf("Unexpected yaml %q", out)
+	}
+}
+
+func TestConvertDocuments(t *testing.T) {
+	stream := "a: 1\n---\nb: 2\n---\n"
+	out, err := Convert([]byte(stream), "yaml", "json", ConvertOptions{})
+	if err != nil || string(out) != "{\"a\":1}\n{\"b\":2}\n" {
+		t.Errorf("Unexpected json lines %q, %v", out, err)
+	}
+	out, err = Convert(out, "json", "yaml", ConvertOptions{})
+	if err != nil || string(out) != "a: 1\n---\nb: 2\n" {
+		t.Errorf("Unexpected yaml stream %q, %v", out, err)
+	}
+	out, err = Convert([]byte(stream), "yaml", "json", ConvertOptions{Array: true})
+	if err != nil || string(out) != "[{\"a\":1},{\"b\":2}]\n" {
+		t.Errorf("Unexpected array %q, %v", out, err)
+	}
+	out, err = Convert([]byte(stream), "yaml", "toml", ConvertOptions{Document: 2})
+	if err != nil || string(out) != "b = 2\n" {
+		t.Errorf("Unexpected document %q, %v", out, err)
+	}
+
+	if _, err := Convert([]byte(stream), "yaml", "toml", ConvertOptions{}); err == nil || !strings.Contains(err.Error(), "format: [toml], error: [cannot hold 2 documents") {
+		t.Errorf("Expected documents error, got %v", err)
+	}
+	if _, err := Convert([]byte(stream), "yaml", "json", ConvertOptions{Document: 3}); err == nil || !strings.Contains(err.Error(), "document 3 requested, content has 2") {
+		t.Errorf("Expected range error, got %v", err)
+	}
+	if _, err := Convert([]byte("{\"a\":1}\n{\"b\":"), "json", "yaml", ConvertOptions{}); err == nil || !strings.Contains(err.Error(), "document: [2]") {
+		t.Errorf("Expected parse error, got %v", err)
+	}
+}
+
+func TestConvertNoCodec(t *testing.T) {
+	if _, err := Convert([]byte("a: 1"), "ini", "json", ConvertOptions{}); !errors.Is(err, ErrNoCodec) || !strings.Contains(err.Error(), "format: [ini]") {
+		t.Errorf("Expected ErrNoCodec, got %v", err)
+	}
+	if _, err := Convert([]byte("a: 1"), "yaml", "hcl", ConvertOptions{}); !errors.Is(err, ErrNoCodec) {
+		t.Errorf("Expected ErrNoCodec, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnmarshalCsv decodes CSV with a header row into t, typically a slice of structs or of
+// map[string]interface{}, treating each row as a mapping from header names to cells. Cells
+// are typed like plain YAML scalars, so "42" decodes into an int field and an empty cell
+// leaves a field at its zero value. Struct fields are matched by their yaml names.
+// Returns an error if the CSV is malformed or a row cannot be decoded into t.
+func UnmarshalCsv(content []byte, t interface{}) error {
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+	}
+	rows := &yaml.Node{Kind: yaml.SequenceNode}
+	if len(records) > 0 {
+		header := records[0]
+		for _, record := range records[1:] {
+			row := &yaml.Node{Kind: yaml.MappingNode}
+			for i, name := range header {
+				cell := ""
+				if i < len(record) {
+					cell = record[i]
+				}
+				row.Content = append(row.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+					&yaml.Node{Kind: yaml.ScalarNode, Value: cell},
+				)
+			}
+			rows.Content = append(rows.Content, row)
+		}
+	}
+	if err := rows.Decode(t); err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+	}
+	return nil
+}
+
+// MarshalCsv encodes a list of records, structs or maps, as CSV with a header row holding
+// the sorted union of their field names. Fields are named by their yaml tags, or their
+// lowercased names, as UnmarshalCsv reads them. Nested values are written as compact JSON.
+// Returns an error if t is not a list of objects or cannot be marshaled.
+func MarshalCsv(t interface{}) ([]byte, error) {
+	list := reflect.ValueOf(t)
+	for list.Kind() == reflect.Pointer || list.Kind() == reflect.Interface {
+		list = list.Elem()
+	}
+	if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+		return nil, fmt.Errorf("error marshalling %T, with error csv needs a list of objects", t)
+	}
+	rows := make([]map[string]interface{}, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		item := list.Index(i)
+		if (item.Kind() == reflect.Pointer || item.Kind() == reflect.Interface) && item.IsNil() {
+			rows = append(rows, map[string]interface{}{})
+			continue
+		}
+		row, err := toYamlMap(item.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling %T, with error csv needs a list of objects: %v", t, err)
+		}
+		rows = append(rows, row)
+	}
+
+	columns := make(map[string]interface{})
+	for _, row := range rows {
+		for key := range row {
+			columns[key] = nil
+		}
+	}
+	header := sortedKeys(columns)
+
+	bff := bytes.Buffer{}
+	writer := csv.NewWriter(&bff)
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		record := make([]string, 0, len(header))
+		for _, column := range header {
+			record = append(record, csvCell(row[column]))
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return bff.Bytes(), writer.Error()
+}
+
+func csvCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case map[string]interface{}, []interface{}:
+		return diffValueText(v)
+	}
+	return fmt.Sprint(value)
+}
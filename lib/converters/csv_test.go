@@ -0,0 +1,101 @@
+package converters
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalCsv(t *testing.T) {
+	t.Run("rows into structs", func(t *testing.T) {
+		var rows []TestStruct
+		err := UnmarshalCsv([]byte("name,value,extra\na,1,x\n\"b, c\",,y\n"), &rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []TestStruct{{Name: "a", Value: 1}, {Name: "b, c"}}
+		if !reflect.DeepEqual(rows, expected) {
+			t.Errorf("Expected %v, got %v", expected, rows)
+		}
+	})
+
+	t.Run("rows into maps with typed cells", func(t *testing.T) {
+		var rows []map[string]interface{}
+		if err := UnmarshalCsv([]byte("name,port,enabled\napi,8080,true\n"), &rows); err != nil {
+			t.Fatal(err)
+		}
+		expected := []map[string]interface{}{{"name": "api", "port": 8080, "enabled": true}}
+		if !reflect.DeepEqual(rows, expected) {
+			t.Errorf("Expected %v, got %v", expected, rows)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var rows []TestStruct
+		if err := UnmarshalCsv([]byte("name,value\n\"open,1\n"), &rows); err == nil {
+			t.Error("Expected malformed csv error")
+		}
+		if err := UnmarshalCsv([]byte("name,value\na,notanumber\n"), &rows); err == nil {
+			t.Error("Expected decode error")
+		}
+		if err := UnmarshalCsv(nil, &rows); err != nil || len(rows) != 0 {
+			t.Errorf("Expected no rows, got %v, %v", rows, err)
+		}
+	})
+}
+
+func TestMarshalCsv(t *testing.T) {
+	data, err := MarshalCsv([]interface{}{
+		TestStruct{Name: "a", Value: 1},
+		map[string]interface{}{"name": "b, c", "tags": []string{"x"}, "extra": nil},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "extra,name,tags,value\n,a,,1\n,\"b, c\",\"[\"\"x\"\"]\",\n"
+	if string(data) != expected {
+		t.Errorf("Expected %q, got %q", expected, data)
+	}
+
+	var rows []TestStruct
+	if err := UnmarshalCsv(data, &rows); err != nil || rows[0].Value != 1 || rows[1].Name != "b, c" {
+		t.Errorf("Expected a round trip, got %v, %v", rows, err)
+	}
+
+	large, err := MarshalCsv([]map[string]interface{}{{"id": 1234567, "big": int64(9007199254740993), "ratio": 2.5e-7}})
+	if err != nil || string(large) != "big,id,ratio\n9007199254740993,1234567,0.00000025\n" {
+		t.Errorf("Expected exact numbers, got %q, %v", large, err)
+	}
+
+	if _, err := MarshalCsv(TestStruct{}); err == nil || !strings.Contains(err.Error(), "list of objects") {
+		t.Errorf("Expected shape error, got %v", err)
+	}
+	if _, err := MarshalCsv(errorMarshaler{}); err == nil {
+		t.Error("Expected marshal error")
+	}
+}
+
+func TestMarshalCsvRoundTrip(t *testing.T) {
+	type server struct {
+		Name    string
+		Port    int
+		Started time.Time `yaml:"started"`
+	}
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	original := []server{{Name: "api", Port: 8080, Started: started}, {Name: "db, primary", Port: 5432}}
+	file := filepath.Join(t.TempDir(), "servers.csv")
+	if err := MarshalFile(original, file); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(file)
+	if !strings.HasPrefix(string(content), "name,port,started\n") {
+		t.Errorf("Expected yaml column names, got %q", content)
+	}
+	var decoded []server
+	if err := UnmarshalFile(file, &decoded); err != nil || !reflect.DeepEqual(decoded[0], original[0]) || decoded[1].Name != "db, primary" || decoded[1].Port != 5432 {
+		t.Errorf("Expected %+v, got %+v, %v", original, decoded, err)
+	}
+}
@@ -0,0 +1,421 @@
+package converters
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecodeHook converts data, about to be decoded into a value of type to, into something
+// Decode can assign. Hooks run in order before every value is decoded and receive the
+// previous hook's result; a hook that does not handle the types returns data unchanged.
+type DecodeHook func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error)
+
+// DecodeOptions controls Decode. The zero value decodes weakly typed input and ignores
+// keys that match no field.
+type DecodeOptions struct {
+	// StrictTypes turns off weak typing, so a string only decodes into a string field,
+	// apart from durations and types implementing encoding.TextUnmarshaler.
+	StrictTypes bool
+	// ErrorUnused reports input keys that match no struct field.
+	ErrorUnused bool
+	// Hooks convert values of custom types before they are decoded.
+	Hooks []DecodeHook
+}
+
+// Decode copies a generic value, typically a map[string]interface{} from MapByStringKey or
+// UnmarshalYaml, into out, a non-nil pointer, without going through an encoded form. Struct
+// fields are matched by their yaml tag, then json tag, then name, ignoring case when there is
+// no exact match, and embedded or inline structs take their fields from the same map.
+// Input is weakly typed unless opts.StrictTypes is set: "8080" decodes into an int, "true"
+// into a bool, numbers into strings, "a, b" into a slice and a single value into a one
+// item slice. Durations parse with time.ParseDuration and types implementing
+// encoding.TextUnmarshaler, such as types.Regex, parse themselves from strings.
+// Returns FieldErrors naming every field that could not be decoded.
+func Decode(input interface{}, out interface{}, opts DecodeOptions) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("error decoding to %T, expected a non-nil pointer", out)
+	}
+	d := &decoder{opts: opts, errs: make(FieldErrors, 0)}
+	d.decode("", input, v.Elem())
+	if len(d.errs) > 0 {
+		return d.errs
+	}
+	return nil
+}
+
+type decoder struct {
+	opts DecodeOptions
+	errs FieldErrors
+}
+
+func (d *decoder) fail(path string, format string, args ...interface{}) {
+	d.errs = append(d.errs, &FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (d *decoder) decode(path string, input interface{}, out reflect.Value) {
+	for _, hook := range d.opts.Hooks {
+		converted, err := hook(reflect.TypeOf(input), out.Type(), input)
+		if err != nil {
+			d.fail(path, "%v", err)
+			return
+		}
+		input = converted
+	}
+	if input == nil {
+		out.Set(reflect.Zero(out.Type()))
+		return
+	}
+	in := reflect.ValueOf(input)
+	if out.Kind() != reflect.Interface && in.Type().AssignableTo(out.Type()) && !isContainer(out.Kind()) {
+		out.Set(in)
+		return
+	}
+	if s, ok := input.(string); ok && out.CanAddr() {
+		if unmarshaler, ok := out.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if err := unmarshaler.UnmarshalText([]byte(s)); err != nil {
+				d.fail(path, "cannot decode %q into %s: %v", s, out.Type(), err)
+			}
+			return
+		}
+	}
+	if out.Type() == durationType {
+		d.decodeDuration(path, input, out)
+		return
+	}
+
+	switch out.Kind() {
+	case reflect.Interface:
+		if !in.Type().AssignableTo(out.Type()) {
+			d.mismatch(path, in, out)
+			return
+		}
+		out.Set(in)
+	case reflect.Pointer:
+		elem := reflect.New(out.Type().Elem())
+		if !out.IsNil() {
+			elem = out
+		}
+		d.decode(path, input, elem.Elem())
+		out.Set(elem)
+	case reflect.String:
+		d.decodeString(path, in, out)
+	case reflect.Bool:
+		d.decodeBool(path, in, out)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		d.decodeNumber(path, in, out)
+	case reflect.Slice, reflect.Array:
+		d.decodeList(path, in, out)
+	case reflect.Map:
+		d.decodeMap(path, in, out)
+	case reflect.Struct:
+		if in.Kind() != reflect.Map {
+			d.mismatch(path, in, out)
+			return
+		}
+		used := make(map[string]bool)
+		entries := stringKeyedEntries(in)
+		d.decodeStruct(path, entries, out, used)
+		if d.opts.ErrorUnused {
+			for _, key := range sortedKeys(entries) {
+				if !used[key] {
+					d.fail(joinFieldPath(path, key), "unknown field")
+				}
+			}
+		}
+	default:
+		d.mismatch(path, in, out)
+	}
+}
+
+// decodeStruct decodes the entries whose keys name fields of out, marking them used.
+// Embedded and inline structs share the entries, an inline map receives the unused ones.
+func (d *decoder) decodeStruct(path string, entries map[string]interface{}, out reflect.Value, used map[string]bool) {
+	var inlineMap reflect.Value
+	t := out.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if (!field.IsExported() && !field.Anonymous) || isSkippedField(field) {
+			continue
+		}
+		fieldValue := out.Field(i)
+		name := documentFieldName(field)
+		if name == "" {
+			target := fieldValue
+			if target.Kind() == reflect.Pointer && target.Type().Elem().Kind() == reflect.Struct {
+				if target.IsNil() && target.CanSet() {
+					target.Set(reflect.New(target.Type().Elem()))
+				}
+				target = target.Elem()
+			}
+			switch {
+			case target.Kind() == reflect.Struct && !isTextValue(target.Type()):
+				d.decodeStruct(path, entries, target, used)
+				continue
+			case target.Kind() == reflect.Map && target.CanSet():
+				inlineMap = target
+				continue
+			}
+		}
+		if !fieldValue.CanSet() {
+			continue
+		}
+		key := matchKey(entries, name)
+		if _, ok := entries[key]; !ok {
+			continue
+		}
+		used[key] = true
+		d.decode(joinFieldPath(path, name), entries[key], fieldValue)
+	}
+
+	if inlineMap.IsValid() {
+		rest := make(map[string]interface{})
+		for key, value := range entries {
+			if !used[key] {
+				rest[key] = value
+				used[key] = true
+			}
+		}
+		d.decodeMap(path, reflect.ValueOf(rest), inlineMap)
+	}
+}
+
+func (d *decoder) decodeMap(path string, in reflect.Value, out reflect.Value) {
+	if in.Kind() != reflect.Map {
+		d.mismatch(path, in, out)
+		return
+	}
+	result := reflect.MakeMapWithSize(out.Type(), in.Len())
+	if !out.IsNil() {
+		result = out
+	}
+	entries := stringKeyedEntries(in)
+	for _, key := range sortedKeys(entries) {
+		keyValue := reflect.New(out.Type().Key()).Elem()
+		d.decode(joinFieldPath(path, key), key, keyValue)
+		value := reflect.New(out.Type().Elem()).Elem()
+		d.decode(joinFieldPath(path, key), entries[key], value)
+		result.SetMapIndex(keyValue, value)
+	}
+	out.Set(result)
+}
+
+func (d *decoder) decodeList(path string, in reflect.Value, out reflect.Value) {
+	if s, ok := in.Interface().(string); ok && out.Type().Elem().Kind() == reflect.Uint8 && out.Kind() == reflect.Slice {
+		out.SetBytes([]byte(s))
+		return
+	}
+	if in.Kind() != reflect.Slice && in.Kind() != reflect.Array {
+		if d.opts.StrictTypes {
+			d.mismatch(path, in, out)
+			return
+		}
+		if s, ok := in.Interface().(string); ok {
+			parts := make([]interface{}, 0)
+			if strings.TrimSpace(s) != "" {
+				for _, part := range strings.Split(s, ",") {
+					parts = append(parts, strings.TrimSpace(part))
+				}
+			}
+			in = reflect.ValueOf(parts)
+		} else {
+			in = reflect.ValueOf([]interface{}{in.Interface()})
+		}
+	}
+
+	result := out
+	if out.Kind() == reflect.Slice {
+		result = reflect.MakeSlice(out.Type(), in.Len(), in.Len())
+	} else if in.Len() > out.Len() {
+		d.fail(path, "cannot decode %d items into %s", in.Len(), out.Type())
+		return
+	}
+	for i := 0; i < in.Len(); i++ {
+		d.decode(fmt.Sprintf("%s[%d]", path, i), in.Index(i).Interface(), result.Index(i))
+	}
+	out.Set(result)
+}
+
+func (d *decoder) decodeString(path string, in reflect.Value, out reflect.Value) {
+	switch {
+	case in.Kind() == reflect.String:
+		out.SetString(in.String())
+	case d.opts.StrictTypes:
+		d.mismatch(path, in, out)
+	case in.Kind() == reflect.Bool:
+		out.SetString(strconv.FormatBool(in.Bool()))
+	case isNumberKind(in.Kind()):
+		out.SetString(fmt.Sprint(in.Interface()))
+	case in.Kind() == reflect.Slice && in.Type().Elem().Kind() == reflect.Uint8:
+		out.SetString(string(in.Bytes()))
+	default:
+		d.mismatch(path, in, out)
+	}
+}
+
+func (d *decoder) decodeBool(path string, in reflect.Value, out reflect.Value) {
+	switch {
+	case in.Kind() == reflect.Bool:
+		out.SetBool(in.Bool())
+	case d.opts.StrictTypes:
+		d.mismatch(path, in, out)
+	case in.Kind() == reflect.String:
+		s := strings.TrimSpace(in.String())
+		if s == "" {
+			out.SetBool(false)
+			return
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			d.fail(path, "cannot decode %q into %s", in.String(), out.Type())
+			return
+		}
+		out.SetBool(b)
+	case isNumberKind(in.Kind()):
+		f, _ := numberValue(in)
+		out.SetBool(f != 0)
+	default:
+		d.mismatch(path, in, out)
+	}
+}
+
+func (d *decoder) decodeNumber(path string, in reflect.Value, out reflect.Value) {
+	var text string
+	switch {
+	case in.Type() == reflect.TypeOf(json.Number("")):
+		text = in.String()
+	case isNumberKind(in.Kind()):
+		_, text = numberValue(in)
+	case d.opts.StrictTypes:
+		d.mismatch(path, in, out)
+		return
+	case in.Kind() == reflect.String:
+		text = strings.TrimSpace(in.String())
+	case in.Kind() == reflect.Bool:
+		text = "0"
+		if in.Bool() {
+			text = "1"
+		}
+	default:
+		d.mismatch(path, in, out)
+		return
+	}
+
+	var err error
+	switch out.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(text, 10, out.Type().Bits()); err != nil && text != "" {
+			if f, ferr := strconv.ParseFloat(text, 64); ferr == nil && f == math.Trunc(f) && !out.OverflowInt(int64(f)) && math.Abs(f) < 1<<63 {
+				n, err = int64(f), nil
+			}
+		}
+		if err == nil {
+			out.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		if n, err = strconv.ParseUint(text, 10, out.Type().Bits()); err != nil && text != "" {
+			if f, ferr := strconv.ParseFloat(text, 64); ferr == nil && f >= 0 && f == math.Trunc(f) && f < 1<<64 && !out.OverflowUint(uint64(f)) {
+				n, err = uint64(f), nil
+			}
+		}
+		if err == nil {
+			out.SetUint(n)
+		}
+	default:
+		var f float64
+		if f, err = strconv.ParseFloat(text, out.Type().Bits()); err == nil {
+			out.SetFloat(f)
+		}
+	}
+	if err != nil {
+		d.fail(path, "cannot decode %s into %s", describeInput(in), out.Type())
+	}
+}
+
+func (d *decoder) decodeDuration(path string, input interface{}, out reflect.Value) {
+	in := reflect.ValueOf(input)
+	if s, ok := input.(string); ok {
+		duration, err := time.ParseDuration(strings.TrimSpace(s))
+		if err != nil {
+			d.fail(path, "cannot decode %q into %s", s, out.Type())
+			return
+		}
+		out.SetInt(int64(duration))
+		return
+	}
+	if isNumberKind(in.Kind()) {
+		d.decodeNumber(path, in, out)
+		return
+	}
+	d.mismatch(path, in, out)
+}
+
+func (d *decoder) mismatch(path string, in reflect.Value, out reflect.Value) {
+	d.fail(path, "cannot decode %s into %s", describeInput(in), out.Type())
+}
+
+// describeInput names an input value for error messages, quoting strings and scalars.
+func describeInput(in reflect.Value) string {
+	switch {
+	case in.Kind() == reflect.String:
+		return fmt.Sprintf("%q", in.String())
+	case in.Kind() == reflect.Bool || isNumberKind(in.Kind()):
+		return fmt.Sprintf("%s %v", in.Type(), in.Interface())
+	}
+	return in.Type().String()
+}
+
+// numberValue returns a numeric value as a float64 and as exact decimal text.
+func numberValue(in reflect.Value) (float64, string) {
+	switch in.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(in.Int()), strconv.FormatInt(in.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(in.Uint()), strconv.FormatUint(in.Uint(), 10)
+	}
+	return in.Float(), strconv.FormatFloat(in.Float(), 'f', -1, 64)
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isContainer reports whether values of kind are copied element by element rather than
+// assigned, so that nested values are converted too.
+func isContainer(kind reflect.Kind) bool {
+	return kind == reflect.Map || kind == reflect.Slice || kind == reflect.Array
+}
+
+// isSkippedField reports whether a field is tagged "-" for yaml, or for json without a yaml tag.
+func isSkippedField(field reflect.StructField) bool {
+	if tag, ok := field.Tag.Lookup("yaml"); ok {
+		return tag == "-"
+	}
+	return field.Tag.Get("json") == "-"
+}
+
+// stringKeyedEntries returns the entries of a map value keyed by their text.
+func stringKeyedEntries(in reflect.Value) map[string]interface{} {
+	entries := make(map[string]interface{}, in.Len())
+	iter := in.MapRange()
+	for iter.Next() {
+		entries[fmt.Sprint(iter.Key().Interface())] = iter.Value().Interface()
+	}
+	return entries
+}
@@ -0,0 +1,214 @@
+package converters
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skhatri/go-fns/lib/collections"
+	"github.com/skhatri/go-fns/lib/types"
+)
+
+type decodeBase struct {
+	ID      string `json:"id"`
+	Enabled bool
+}
+
+type decodeLevel int
+
+type decodeTarget struct {
+	decodeBase
+	*DecodeMeta
+	Port     int               `yaml:"port"`
+	Ratio    float32           `json:"ratio"`
+	Timeout  time.Duration     `yaml:"timeout"`
+	Hosts    []string          `yaml:"hosts"`
+	Pattern  *types.Regex      `yaml:"pattern"`
+	Labels   map[string]int    `yaml:"labels"`
+	Nested   *TestStruct       `yaml:"nested"`
+	Level    decodeLevel       `yaml:"level"`
+	Pair     [2]uint8          `yaml:"pair"`
+	Any      interface{}       `yaml:"any"`
+	Ignored  string            `yaml:"-"`
+	Extra    map[string]string `yaml:",inline"`
+	internal string
+}
+
+// DecodeMeta is exported because reflection cannot allocate an unexported embedded pointer.
+type DecodeMeta struct {
+	Owner string `yaml:"owner"`
+}
+
+func TestDecode(t *testing.T) {
+	input := collections.MapByStringKey(map[interface{}]interface{}{
+		"id":      42,
+		"ENABLED": "true",
+		"owner":   "ops",
+		"port":    "8080",
+		"ratio":   "0.5",
+		"timeout": "5s",
+		"hosts":   "a, b,c",
+		"pattern": "^api-",
+		"labels":  map[interface{}]interface{}{"tier": "1", "zone": 2.0},
+		"nested":  map[interface{}]interface{}{"name": 7, "value": "9"},
+		"level":   "high",
+		"pair":    []interface{}{"1", 2},
+		"any":     []interface{}{"kept"},
+		"Ignored": "no",
+		"region":  "eu",
+	})
+
+	levels := func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		if to != reflect.TypeOf(decodeLevel(0)) || from == nil || from.Kind() != reflect.String {
+			return data, nil
+		}
+		switch data {
+		case "low":
+			return 1, nil
+		case "high":
+			return 3, nil
+		}
+		return nil, fmt.Errorf("unknown level %v", data)
+	}
+
+	var out decodeTarget
+	if err := Decode(input, &out, DecodeOptions{Hooks: []DecodeHook{levels}}); err != nil {
+		t.Fatal(err)
+	}
+	if out.ID != "42" || !out.Enabled || out.DecodeMeta == nil || out.Owner != "ops" {
+		t.Errorf("Unexpected embedded fields %+v %+v", out.decodeBase, out.DecodeMeta)
+	}
+	if out.Port != 8080 || out.Ratio != 0.5 || out.Timeout != 5*time.Second || out.Level != 3 || out.Pair != [2]uint8{1, 2} {
+		t.Errorf("Unexpected scalars %+v", out)
+	}
+	if !reflect.DeepEqual(out.Hosts, []string{"a", "b", "c"}) || !out.Pattern.MatchString("api-1") {
+		t.Errorf("Unexpected hosts %v or pattern %v", out.Hosts, out.Pattern)
+	}
+	if !reflect.DeepEqual(out.Labels, map[string]int{"tier": 1, "zone": 2}) || *out.Nested != (TestStruct{Name: "7", Value: 9}) {
+		t.Errorf("Unexpected labels %v or nested %v", out.Labels, out.Nested)
+	}
+	if !reflect.DeepEqual(out.Any, []interface{}{"kept"}) || out.Ignored != "" {
+		t.Errorf("Unexpected any %v or ignored %q", out.Any, out.Ignored)
+	}
+	if !reflect.DeepEqual(out.Extra, map[string]string{"Ignored": "no", "region": "eu"}) {
+		t.Errorf("Unexpected inline map %v", out.Extra)
+	}
+}
+
+func TestDecodeValues(t *testing.T) {
+	var hosts []string
+	if err := Decode("single", &hosts, DecodeOptions{}); err != nil || !reflect.DeepEqual(hosts, []string{"single"}) {
+		t.Errorf("Unexpected hosts %v, %v", hosts, err)
+	}
+	var ports []int
+	if err := Decode(8080, &ports, DecodeOptions{}); err != nil || !reflect.DeepEqual(ports, []int{8080}) {
+		t.Errorf("Unexpected ports %v, %v", ports, err)
+	}
+	var text string
+	if err := Decode(true, &text, DecodeOptions{}); err != nil || text != "true" {
+		t.Errorf("Unexpected text %q, %v", text, err)
+	}
+	var flag bool
+	if err := Decode(1, &flag, DecodeOptions{}); err != nil || !flag {
+		t.Errorf("Unexpected flag %v, %v", flag, err)
+	}
+	var big int64
+	if err := Decode(1e15, &big, DecodeOptions{}); err != nil || big != 1e15 {
+		t.Errorf("Unexpected number %v, %v", big, err)
+	}
+	var data []byte
+	if err := Decode("raw", &data, DecodeOptions{}); err != nil || string(data) != "raw" {
+		t.Errorf("Unexpected bytes %q, %v", data, err)
+	}
+	var timeout time.Duration
+	if err := Decode(int64(time.Second), &timeout, DecodeOptions{}); err != nil || timeout != time.Second {
+		t.Errorf("Unexpected duration %v, %v", timeout, err)
+	}
+	var nothing *TestStruct
+	if err := Decode(nil, &nothing, DecodeOptions{}); err != nil || nothing != nil {
+		t.Errorf("Unexpected pointer %v, %v", nothing, err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	input := map[string]interface{}{
+		"port":    "http",
+		"timeout": "soon",
+		"hosts":   []interface{}{"a", map[string]interface{}{}},
+		"pattern": "(",
+		"labels":  map[string]interface{}{"tier": 1.5},
+		"nested":  "flat",
+		"pair":    []interface{}{1, 2, 3},
+		"enabled": "maybe",
+		"unknown": true,
+	}
+	var out decodeTarget
+	err := Decode(input, &out, DecodeOptions{})
+	var problems FieldErrors
+	if !errors.As(err, &problems) {
+		t.Fatalf("Expected FieldErrors, got %v", err)
+	}
+	paths := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		paths = append(paths, problem.Path)
+	}
+	expected := []string{"Enabled", "port", "timeout", "hosts[1]", "pattern", "labels.tier", "nested", "pair"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected paths %v, got %v: %v", expected, paths, err)
+	}
+	if !strings.Contains(err.Error(), `field: [port], error: [cannot decode "http" into int]`) {
+		t.Errorf("Unexpected message %v", err)
+	}
+
+	t.Run("strict types and unused keys", func(t *testing.T) {
+		var result TestStruct
+		err := Decode(map[string]interface{}{"name": 1, "value": "2", "other": 3}, &result, DecodeOptions{StrictTypes: true, ErrorUnused: true})
+		if err == nil || !strings.Contains(err.Error(), "field: [name]") || !strings.Contains(err.Error(), "field: [value]") || !strings.Contains(err.Error(), "field: [other], error: [unknown field]") {
+			t.Errorf("Unexpected error %v", err)
+		}
+		if err := Decode(map[string]interface{}{"value": 2.0}, &result, DecodeOptions{StrictTypes: true}); err != nil || result.Value != 2 {
+			t.Errorf("Expected numbers to convert in strict mode, got %v, %v", result, err)
+		}
+		var hosts []string
+		if err := Decode("a,b", &hosts, DecodeOptions{StrictTypes: true}); err == nil {
+			t.Error("Expected strict slice error")
+		}
+	})
+
+	t.Run("non-empty interfaces", func(t *testing.T) {
+		var result struct {
+			S fmt.Stringer `yaml:"s"`
+		}
+		err := Decode(map[string]interface{}{"s": "x"}, &result, DecodeOptions{})
+		if err == nil || !strings.Contains(err.Error(), `field: [s], error: [cannot decode "x" into fmt.Stringer]`) {
+			t.Errorf("Expected mismatch error, got %v", err)
+		}
+		if err := Decode(map[string]interface{}{"s": types.Secret("x")}, &result, DecodeOptions{}); err != nil || result.S.String() != "***" {
+			t.Errorf("Expected an implementing value to be assigned, got %v, %v", result.S, err)
+		}
+	})
+
+	t.Run("hook and target errors", func(t *testing.T) {
+		failing := func(reflect.Type, reflect.Type, interface{}) (interface{}, error) {
+			return nil, errors.New("hook failed")
+		}
+		var result TestStruct
+		if err := Decode(map[string]interface{}{}, &result, DecodeOptions{Hooks: []DecodeHook{failing}}); err == nil || !strings.Contains(err.Error(), "hook failed") {
+			t.Errorf("Expected hook error, got %v", err)
+		}
+		if err := Decode(map[string]interface{}{}, result, DecodeOptions{}); err == nil {
+			t.Error("Expected error for non-pointer target")
+		}
+		var small int8
+		if err := Decode(300, &small, DecodeOptions{}); err == nil {
+			t.Error("Expected overflow error")
+		}
+		var unsigned uint
+		if err := Decode(-1, &unsigned, DecodeOptions{}); err == nil {
+			t.Error("Expected sign error")
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package converters
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ApplyDefaults sets every zero-valued field of the struct pointed to by t from its
+// `default:"..."` tag, recursing into nested structs, non-nil struct pointers and slices
+// of structs. Slice defaults are comma separated, durations use time.ParseDuration and
+// types implementing encoding.TextUnmarshaler, such as types.Regex, parse themselves.
+// Note that a false bool or a 0 number is indistinguishable from an unset field.
+// Returns FieldErrors naming every default that could not be applied.
+func ApplyDefaults(t interface{}) error {
+	v := reflect.ValueOf(t)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("error applying defaults to %T, expected a non-nil pointer", t)
+	}
+	errs := make(FieldErrors, 0)
+	applyDefaults(v.Elem(), "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func applyDefaults(v reflect.Value, path string, errs *FieldErrors) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			applyDefaults(v.Elem(), path, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			applyDefaults(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Struct:
+		if isTextValue(v.Type()) {
+			return
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			fieldValue := v.Field(i)
+			fieldPath := structFieldPath(path, field)
+			if value, ok := field.Tag.Lookup("default"); ok && fieldValue.IsZero() && fieldValue.CanSet() {
+				if err := setFromString(fieldValue, value); err != nil {
+					*errs = append(*errs, &FieldError{Path: fieldPath, Message: fmt.Sprintf("invalid default %q: %v", value, err)})
+					continue
+				}
+			}
+			applyDefaults(fieldValue, fieldPath, errs)
+		}
+	}
+}
+
+// setFromString parses s into v according to v's type.
+func setFromString(v reflect.Value, s string) error {
+	if v.CanAddr() {
+		if unmarshaler, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return unmarshaler.UnmarshalText([]byte(s))
+		}
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(v.Type().Elem())
+		if err := setFromString(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		parts := strings.Split(s, ",")
+		if strings.TrimSpace(s) == "" {
+			parts = nil
+		}
+		items := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setFromString(items.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		v.Set(items)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// isTextValue reports whether values of t are parsed from text by themselves, so their
+// fields should not be inspected.
+func isTextValue(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType) || t == reflect.TypeOf(time.Time{})
+}
+
+// structFieldPath joins the document name of field onto path. Embedded structs without a
+// name of their own contribute no path segment, as their fields are decoded inline.
+func structFieldPath(path string, field reflect.StructField) string {
+	name := documentFieldName(field)
+	if name == "" {
+		return path
+	}
+	return joinFieldPath(path, name)
+}
+
+// documentFieldName returns the name a field has in a decoded document, preferring the
+// yaml tag, then the json tag, then the Go field name. It returns "" for inline fields.
+func documentFieldName(field reflect.StructField) string {
+	for _, key := range []string{"yaml", "json"} {
+		name, opts, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name != "" && name != "-" {
+			return name
+		}
+		if strings.Contains(","+opts+",", ",inline,") {
+			return ""
+		}
+	}
+	if field.Anonymous {
+		return ""
+	}
+	return field.Name
+}
@@ -0,0 +1,90 @@
+package converters
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/skhatri/go-fns/lib/types"
+)
+
+type defaultsServer struct {
+	Host string `yaml:"host" default:"localhost"`
+	Port int    `yaml:"port" default:"8080"`
+}
+
+type defaultsConfig struct {
+	Name     string           `yaml:"name" default:"app"`
+	Debug    bool             `yaml:"debug" default:"true"`
+	Ratio    float64          `yaml:"ratio" default:"0.5"`
+	Retries  uint8            `yaml:"retries" default:"3"`
+	Timeout  time.Duration    `yaml:"timeout" default:"5s"`
+	Tags     []string         `yaml:"tags" default:"a, b"`
+	Limit    *int             `yaml:"limit" default:"10"`
+	Match    *types.Regex     `yaml:"match" default:"^v[0-9]+$"`
+	Primary  defaultsServer   `yaml:"primary"`
+	Replicas []defaultsServer `yaml:"replicas"`
+	Fallback *defaultsServer  `yaml:"fallback"`
+}
+
+func TestApplyDefaults(t *testing.T) {
+	t.Run("zero values are defaulted", func(t *testing.T) {
+		cfg := defaultsConfig{Replicas: []defaultsServer{{Host: "replica"}}}
+		if err := ApplyDefaults(&cfg); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.Name != "app" || !cfg.Debug || cfg.Ratio != 0.5 || cfg.Retries != 3 || cfg.Timeout != 5*time.Second {
+			t.Errorf("Unexpected scalars %+v", cfg)
+		}
+		if len(cfg.Tags) != 2 || cfg.Tags[1] != "b" {
+			t.Errorf("Expected [a b], got %v", cfg.Tags)
+		}
+		if cfg.Limit == nil || *cfg.Limit != 10 {
+			t.Errorf("Expected limit 10, got %v", cfg.Limit)
+		}
+		if cfg.Match == nil || !cfg.Match.MatchString("v2") {
+			t.Errorf("Expected compiled regex, got %v", cfg.Match)
+		}
+		if cfg.Primary.Host != "localhost" || cfg.Primary.Port != 8080 {
+			t.Errorf("Expected nested defaults, got %+v", cfg.Primary)
+		}
+		if cfg.Replicas[0].Host != "replica" || cfg.Replicas[0].Port != 8080 {
+			t.Errorf("Expected slice element defaults, got %+v", cfg.Replicas[0])
+		}
+		if cfg.Fallback != nil {
+			t.Errorf("Expected nil pointer to stay nil, got %+v", cfg.Fallback)
+		}
+	})
+
+	t.Run("set values are kept", func(t *testing.T) {
+		cfg := defaultsConfig{Name: "custom", Primary: defaultsServer{Port: 9090}}
+		if err := ApplyDefaults(&cfg); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.Name != "custom" || cfg.Primary.Port != 9090 || cfg.Primary.Host != "localhost" {
+			t.Errorf("Unexpected config %+v", cfg)
+		}
+	})
+
+	t.Run("invalid defaults are reported", func(t *testing.T) {
+		var cfg struct {
+			Port  int            `yaml:"port" default:"http"`
+			Match types.Regex    `yaml:"match" default:"["`
+			Meta  map[string]int `default:"a=1"`
+		}
+		err := ApplyDefaults(&cfg)
+		var errs FieldErrors
+		if !errors.As(err, &errs) || len(errs) != 3 {
+			t.Fatalf("Expected 3 field errors, got %v", err)
+		}
+		if errs[0].Path != "port" || errs[1].Path != "match" || errs[2].Path != "Meta" {
+			t.Errorf("Unexpected paths %v", errs)
+		}
+	})
+
+	t.Run("error on non-pointer", func(t *testing.T) {
+		if err := ApplyDefaults(defaultsConfig{}); err == nil {
+			t.Error("Expected error for non-pointer")
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/skhatri/go-fns/lib/collections"
+)
+
+// ChangeType describes how a path differs between two documents.
+type ChangeType string
+
+const (
+	ChangeAdded   ChangeType = "added"
+	ChangeRemoved ChangeType = "removed"
+	ChangeChanged ChangeType = "changed"
+)
+
+// Change is one difference between two documents. Path is dotted, as accepted by Get, and
+// empty for the document root. Old is unset for additions and New for removals.
+type Change struct {
+	Type ChangeType  `json:"type"`
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// Changes is the ordered list of differences returned by Diff.
+type Changes []Change
+
+// Diff compares two decoded documents, such as those read by UnmarshalFile into an
+// interface{}, and returns the paths that were added, removed or changed going from a to b.
+// Objects are compared key by key in sorted order and arrays index by index, so an item
+// inserted into an array shows as changes to the items after it. Numbers are compared by
+// value, so a YAML int equals the same JSON float64.
+func Diff(a interface{}, b interface{}) Changes {
+	changes := make(Changes, 0)
+	diffValues(collections.MapByStringKey(a), collections.MapByStringKey(b), nil, &changes)
+	return changes
+}
+
+// DiffFiles reads two files with UnmarshalFile and compares them with Diff.
+// Returns an error if either file cannot be read.
+func DiffFiles(a string, b string) (Changes, error) {
+	var x, y interface{}
+	if err := UnmarshalFile(a, &x); err != nil {
+		return nil, err
+	}
+	if err := UnmarshalFile(b, &y); err != nil {
+		return nil, err
+	}
+	return Diff(x, y), nil
+}
+
+// Unified renders the changes like a unified diff, with a "---"/"+++" header naming the two
+// sides, one "@@ path @@" hunk per change and removed and added values, as compact JSON,
+// on "-" and "+" lines. It returns an empty string when there are no changes.
+func (c Changes) Unified(from string, to string) string {
+	if len(c) == 0 {
+		return ""
+	}
+	out := strings.Builder{}
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", from, to)
+	for _, change := range c {
+		path := change.Path
+		if path == "" {
+			path = "$"
+		}
+		fmt.Fprintf(&out, "@@ %s @@\n", path)
+		if change.Type != ChangeAdded {
+			fmt.Fprintf(&out, "-%s\n", diffValueText(change.Old))
+		}
+		if change.Type != ChangeRemoved {
+			fmt.Fprintf(&out, "+%s\n", diffValueText(change.New))
+		}
+	}
+	return out.String()
+}
+
+// String renders the changes with Unified, labelling the sides a and b.
+func (c Changes) String() string {
+	return c.Unified("a", "b")
+}
+
+func diffValues(a interface{}, b interface{}, path []string, changes *Changes) {
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		union := make(map[string]interface{}, len(x)+len(y))
+		for key := range x {
+			union[key] = nil
+		}
+		for key := range y {
+			union[key] = nil
+		}
+		for _, key := range sortedKeys(union) {
+			child := append(path[:len(path):len(path)], key)
+			old, inA := x[key]
+			value, inB := y[key]
+			switch {
+			case !inA:
+				*changes = append(*changes, Change{Type: ChangeAdded, Path: diffPath(child), New: value})
+			case !inB:
+				*changes = append(*changes, Change{Type: ChangeRemoved, Path: diffPath(child), Old: old})
+			default:
+				diffValues(old, value, child, changes)
+			}
+		}
+		return
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < max(len(x), len(y)); i++ {
+			child := append(path[:len(path):len(path)], strconv.Itoa(i))
+			switch {
+			case i >= len(x):
+				*changes = append(*changes, Change{Type: ChangeAdded, Path: diffPath(child), New: y[i]})
+			case i >= len(y):
+				*changes = append(*changes, Change{Type: ChangeRemoved, Path: diffPath(child), Old: x[i]})
+			default:
+				diffValues(x[i], y[i], child, changes)
+			}
+		}
+		return
+	}
+	if !schemaEqual(a, b) {
+		*changes = append(*changes, Change{Type: ChangeChanged, Path: diffPath(path), Old: a, New: b})
+	}
+}
+
+func diffPath(segments []string) string {
+	return strings.Join(escapeDottedSegments(segments), ".")
+}
+
+// diffValueText renders a value as compact JSON without HTML escaping, falling back to fmt.
+func diffValueText(value interface{}) string {
+	bff := bytes.Buffer{}
+	encoder := json.NewEncoder(&bff)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSuffix(bff.String(), "\n")
+}
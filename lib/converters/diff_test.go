@@ -0,0 +1,105 @@
+package converters
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	var staging, production interface{}
+	_ = UnmarshalYaml([]byte(`
+db:
+  host: staging-db
+  port: 5432
+debug: true
+hosts: [a, b, c]
+limits: {cpu: 1}
+owners:
+  team.lead: alice
+`), &staging)
+	_ = UnmarshalJson([]byte(`{
+  "db": {"host": "prod-db", "port": 5432.0},
+  "hosts": ["a", "x"],
+  "limits": 2,
+  "owners": {"team.lead": "bob"},
+  "replicas": 3
+}`), &production)
+
+	changes := Diff(staging, production)
+	expected := Changes{
+		{Type: ChangeChanged, Path: "db.host", Old: "staging-db", New: "prod-db"},
+		{Type: ChangeRemoved, Path: "debug", Old: true},
+		{Type: ChangeChanged, Path: "hosts.1", Old: "b", New: "x"},
+		{Type: ChangeRemoved, Path: "hosts.2", Old: "c"},
+		{Type: ChangeChanged, Path: "limits", Old: map[string]interface{}{"cpu": 1}, New: 2.0},
+		{Type: ChangeChanged, Path: `owners.team\.lead`, Old: "alice", New: "bob"},
+		{Type: ChangeAdded, Path: "replicas", New: 3.0},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v, got %v", expected, changes)
+	}
+
+	t.Run("unified", func(t *testing.T) {
+		expected := `--- staging
++++ production
+@@ db.host @@
+-"staging-db"
++"prod-db"
+@@ debug @@
+-true
+@@ hosts.1 @@
+-"b"
++"x"
+@@ hosts.2 @@
+-"c"
+@@ limits @@
+-{"cpu":1}
++2
+@@ owners.team\.lead @@
+-"alice"
++"bob"
+@@ replicas @@
++3
+`
+		if text := changes.Unified("staging", "production"); text != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, text)
+		}
+	})
+
+	t.Run("identical and root changes", func(t *testing.T) {
+		if changes := Diff(staging, staging); len(changes) != 0 || changes.String() != "" {
+			t.Errorf("Expected no changes, got %v", changes)
+		}
+		changes := Diff("a", []interface{}{"a"})
+		if len(changes) != 1 || changes[0].Path != "" {
+			t.Fatalf("Unexpected changes %v", changes)
+		}
+		if text := changes.String(); text != "--- a\n+++ b\n@@ $ @@\n-\"a\"\n+[\"a\"]\n" {
+			t.Errorf("Unexpected rendering %q", text)
+		}
+		added := Diff([]interface{}{}, []interface{}{"<b>"}).String()
+		if added != "--- a\n+++ b\n@@ 0 @@\n+\"<b>\"\n" {
+			t.Errorf("Unexpected rendering %q", added)
+		}
+	})
+
+	t.Run("files", func(t *testing.T) {
+		dir := t.TempDir()
+		a := filepath.Join(dir, "a.yaml")
+		b := filepath.Join(dir, "b.json")
+		_ = os.WriteFile(a, []byte("name: x\n"), 0o644)
+		_ = os.WriteFile(b, []byte(`{"name": "y"}`), 0o644)
+		changes, err := DiffFiles(a, b)
+		if err != nil || len(changes) != 1 || changes[0].Path != "name" {
+			t.Errorf("Unexpected result %v, %v", changes, err)
+		}
+		if _, err := DiffFiles(a, filepath.Join(dir, "missing.json")); err == nil {
+			t.Error("Expected error for missing file")
+		}
+		if _, err := DiffFiles(filepath.Join(dir, "missing.json"), b); err == nil {
+			t.Error("Expected error for missing file")
+		}
+	})
+}
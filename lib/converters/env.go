@@ -0,0 +1,159 @@
+package converters
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+// UnmarshalEnv decodes dotenv content, KEY=value lines with optional "export " prefixes and
+// # comments, into t, typically a struct or map[string]interface{}. Double quoted values may
+// span lines and use \n, \t, \" and \\ escapes, single quoted values are taken literally, and
+// unquoted values end at a " #" comment. Unquoted values are typed like plain YAML scalars, so
+// PORT=8080 decodes into an int field; quoted values stay strings. Variables are not expanded.
+// Returns an error, naming the line, if a line is malformed or the values cannot be decoded into t.
+func UnmarshalEnv(content []byte, t interface{}) error {
+	node, err := parseEnv(content)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+	}
+	if err := node.Decode(t); err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+	}
+	return nil
+}
+
+// MarshalEnv encodes the top-level fields of a struct or map as sorted KEY=value lines, naming
+// fields by their yaml tags, or their lowercased names, as UnmarshalEnv reads them. Values
+// with spaces or other special characters are quoted, and nested values are written as
+// compact JSON.
+// Returns an error if t is not a struct or map, or a key is not a valid variable name.
+func MarshalEnv(t interface{}) ([]byte, error) {
+	tree, err := toYamlMap(t)
+	if err != nil {
+		return nil, err
+	}
+	bff := bytes.Buffer{}
+	for _, key := range sortedKeys(tree) {
+		if !envKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("error marshalling %T, with error invalid variable name %q", t, key)
+		}
+		fmt.Fprintf(&bff, "%s=%s\n", key, envValue(csvCell(tree[key])))
+	}
+	return bff.Bytes(), nil
+}
+
+// parseEnv parses dotenv content into a mapping node. A repeated key replaces the earlier value.
+func parseEnv(content []byte) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	positions := make(map[string]int)
+	lines := strings.Split(strings.ReplaceAll(string(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		key, rest, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || !envKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNumber)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+
+		value := &yaml.Node{Kind: yaml.ScalarNode, Line: lineNumber}
+		switch {
+		case strings.HasPrefix(rest, `"`) || strings.HasPrefix(rest, "'"):
+			quote := rest[:1]
+			text := rest[1:]
+			// Quoted values continue over following lines until the closing quote.
+			for envClosingQuote(text, quote) < 0 && i+1 < len(lines) {
+				i++
+				text += "\n" + lines[i]
+			}
+			end := envClosingQuote(text, quote)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated %s quote", lineNumber, quote)
+			}
+			if trailing := strings.TrimSpace(text[end+1:]); trailing != "" && !strings.HasPrefix(trailing, "#") {
+				return nil, fmt.Errorf("line %d: unexpected %q after quoted value", lineNumber, trailing)
+			}
+			value.Tag, value.Value = "!!str", text[:end]
+			if quote == `"` {
+				value.Value = unescapeEnv(text[:end])
+			}
+		default:
+			if comment := strings.Index(rest, " #"); comment >= 0 {
+				rest = rest[:comment]
+			}
+			value.Value = strings.TrimSpace(rest)
+			if value.Value == "" {
+				value.Tag = "!!str"
+			}
+		}
+
+		if position, exists := positions[key]; exists {
+			node.Content[position] = value
+			continue
+		}
+		positions[key] = len(node.Content) + 1
+		node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key, Line: lineNumber}, value)
+	}
+	return node, nil
+}
+
+// envClosingQuote returns the index of the first unescaped quote in text, or -1.
+func envClosingQuote(text string, quote string) int {
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '\\' && quote == `"`:
+			i++
+		case text[i:i+1] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeEnv(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 == len(text) {
+			b.WriteByte(text[i])
+			continue
+		}
+		i++
+		switch text[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(text[i])
+		}
+	}
+	return b.String()
+}
+
+// envValue leaves letters, digits and simple punctuation bare, single quotes other values so
+// that no loader expands them, and double quotes values holding single quotes or line breaks.
+func envValue(value string) string {
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-.,:/@+", r)) {
+			if !strings.ContainsAny(value, "'\n\r") {
+				return "'" + value + "'"
+			}
+			return strconv.Quote(value)
+		}
+	}
+	return value
+}
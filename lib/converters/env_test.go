@@ -0,0 +1,99 @@
+package converters
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalEnv(t *testing.T) {
+	content := `# service settings
+export PORT=8080
+DEBUG = true # inline comment
+NAME="my \"app\"\nv2"
+LITERAL='$HOME\n'
+MULTI="first
+second"
+EMPTY=
+HASH=a#b
+PORT=9090
+`
+	var env map[string]interface{}
+	if err := UnmarshalEnv([]byte(content), &env); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"PORT":    9090,
+		"DEBUG":   true,
+		"NAME":    "my \"app\"\nv2",
+		"LITERAL": `$HOME\n`,
+		"MULTI":   "first\nsecond",
+		"EMPTY":   "",
+		"HASH":    "a#b",
+	}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %v, got %v", expected, env)
+	}
+
+	var typed struct {
+		Port  int    `yaml:"PORT"`
+		Debug bool   `yaml:"DEBUG"`
+		Name  string `yaml:"NAME"`
+	}
+	if err := UnmarshalEnv([]byte(content), &typed); err != nil || typed.Port != 9090 || !typed.Debug {
+		t.Errorf("Unexpected struct %+v, %v", typed, err)
+	}
+}
+
+func TestUnmarshalEnvErrors(t *testing.T) {
+	for content, message := range map[string]string{
+		"A=1\nnot a pair": "line 2: expected KEY=value",
+		"1A=x":            "line 1: expected KEY=value",
+		"A=\"open\nstill": "line 1: unterminated \" quote",
+		"A='x' y":         "line 1: unexpected \"y\" after quoted value",
+	} {
+		var env map[string]interface{}
+		if err := UnmarshalEnv([]byte(content), &env); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Content %q: expected %q, got %v", content, message, err)
+		}
+	}
+}
+
+func TestMarshalEnv(t *testing.T) {
+	out, err := MarshalEnv(map[string]interface{}{
+		"PORT":   8080,
+		"URL":    "http://host:80/path",
+		"GREET":  "hello world",
+		"QUOTE":  "it's",
+		"LINES":  "a\nb",
+		"LIST":   []string{"a", "b"},
+		"NESTED": map[string]interface{}{"k": 1},
+		"NONE":   nil,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `GREET='hello world'
+LINES="a\nb"
+LIST='["a","b"]'
+NESTED='{"k":1}'
+NONE=
+PORT=8080
+QUOTE="it's"
+URL=http://host:80/path
+`
+	if string(out) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	var back map[string]interface{}
+	if err := UnmarshalEnv(out, &back); err != nil || back["LINES"] != "a\nb" || back["QUOTE"] != "it's" || back["PORT"] != 8080 {
+		t.Errorf("Expected a round trip, got %v, %v", back, err)
+	}
+	if out, err := MarshalEnv(map[string]interface{}{"MAX_BYTES": 1.5e9}); err != nil || string(out) != "MAX_BYTES=1500000000\n" {
+		t.Errorf("Expected a plain number, got %q, %v", out, err)
+	}
+	if _, err := MarshalEnv(map[string]interface{}{"bad key": 1}); err == nil || !strings.Contains(err.Error(), `invalid variable name "bad key"`) {
+		t.Errorf("Expected key error, got %v", err)
+	}
+}
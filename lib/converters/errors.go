@@ -0,0 +1,193 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// errorLinePattern finds the line number yaml syntax errors and the TOML, dotenv and
+	// properties parsers put in their messages, and the column the JSON5 parser adds.
+	errorLinePattern = regexp.MustCompile(`\bline (\d+)(?:, column (\d+))?: (.*)$`)
+	// yamlValuePattern finds the tag and the value, possibly shortened with "...", that an
+	// entry of yaml.TypeError failed on, as in "cannot unmarshal !!str `eighty` into int".
+	yamlValuePattern = regexp.MustCompile("cannot unmarshal (!!\\w+)(?: `([^`]*)`)?")
+)
+
+// locateError turns a decode error for content read from file into FieldErrors with the line,
+// column, field path and a snippet of the source, keeping the original error reachable
+// through errors.As. Errors with no position are wrapped with the file name.
+func locateError(file string, content []byte, err error) error {
+	var located FieldErrors
+	if errors.As(err, &located) {
+		for _, fieldErr := range located {
+			fieldErr.File = file
+			if fieldErr.Snippet == "" {
+				fieldErr.Snippet = sourceSnippet(content, fieldErr.Line, fieldErr.Column)
+			}
+		}
+		return located
+	}
+
+	var syntaxErr *json.SyntaxError
+	var jsonTypeErr *json.UnmarshalTypeError
+	var yamlTypeErr *yaml.TypeError
+	var xmlSyntaxErr *xml.SyntaxError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, column := offsetPosition(content, max(int(syntaxErr.Offset)-1, 0))
+		located = FieldErrors{{Line: line, Column: column, Message: syntaxErr.Error()}}
+	case errors.As(err, &jsonTypeErr):
+		line, column := offsetPosition(content, max(int(jsonTypeErr.Offset)-1, 0))
+		fieldErr := &FieldError{Line: line, Column: column, Message: strings.TrimPrefix(jsonTypeErr.Error(), "json: ")}
+		if root, parseErr := jsonNode(content); parseErr == nil {
+			if node, path := jsonNodeBefore(root, line, column); node != nil {
+				fieldErr.Line, fieldErr.Column, fieldErr.Path = node.Line, node.Column, path
+			}
+		}
+		located = FieldErrors{fieldErr}
+	case errors.As(err, &yamlTypeErr):
+		var root yaml.Node
+		if yaml.Unmarshal(content, &root) == nil {
+			located = locateYamlTypeError(&root, yamlTypeErr)
+		}
+	case errors.As(err, &xmlSyntaxErr):
+		located = FieldErrors{{Line: xmlSyntaxErr.Line, Message: xmlSyntaxErr.Msg}}
+	default:
+		if match := errorLinePattern.FindStringSubmatch(err.Error()); match != nil {
+			line, _ := strconv.Atoi(match[1])
+			column, _ := strconv.Atoi(match[2])
+			located = FieldErrors{{Line: line, Column: column, Message: match[3]}}
+		}
+	}
+	if len(located) == 0 {
+		return fmt.Errorf("file: [%s], error: [%w]", file, err)
+	}
+	for _, fieldErr := range located {
+		fieldErr.File, fieldErr.Err = file, err
+		fieldErr.Snippet = sourceSnippet(content, fieldErr.Line, fieldErr.Column)
+	}
+	return located
+}
+
+// locateYamlTypeError returns a FieldError for each entry of a yaml.TypeError raised decoding
+// root, with the column and field path of the value on the entry's line.
+func locateYamlTypeError(root *yaml.Node, typeErr *yaml.TypeError) FieldErrors {
+	located := make(FieldErrors, 0, len(typeErr.Errors))
+	for _, entry := range typeErr.Errors {
+		match := errorLinePattern.FindStringSubmatch(entry)
+		if match == nil {
+			located = append(located, &FieldError{Message: entry})
+			continue
+		}
+		line, _ := strconv.Atoi(match[1])
+		fieldErr := &FieldError{Line: line, Message: match[3]}
+		tag, value := "", ""
+		if found := yamlValuePattern.FindStringSubmatch(match[3]); found != nil {
+			tag, value = found[1], strings.TrimSuffix(found[2], "...")
+		}
+		if node, path := yamlNodeOnLine(root, line, tag, value); node != nil {
+			fieldErr.Column, fieldErr.Path = node.Column, path
+		}
+		located = append(located, fieldErr)
+	}
+	return located
+}
+
+// sourceSnippet returns the given line of content prefixed with its number, and when the
+// column is known a second line with a caret under it:
+//
+//	3 | port: eighty
+//	  |       ^
+func sourceSnippet(content []byte, line int, column int) string {
+	lines := bytes.Split(content, []byte("\n"))
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	text := strings.TrimRight(string(lines[line-1]), "\r")
+	gutter := strconv.Itoa(line)
+	snippet := fmt.Sprintf("%s | %s", gutter, text)
+	if column < 1 {
+		return snippet
+	}
+	// Tabs are kept so the caret lines up however the terminal expands them.
+	pad := strings.Builder{}
+	for i, r := range []rune(text) {
+		if i >= column-1 {
+			break
+		}
+		if r == '\t' {
+			pad.WriteRune('\t')
+		} else {
+			pad.WriteByte(' ')
+		}
+	}
+	return fmt.Sprintf("%s\n%s | %s^", snippet, strings.Repeat(" ", len(gutter)), pad.String())
+}
+
+// walkValueNodes calls visit with every value node under node and its field path, in
+// document order. Mapping keys and the targets of aliases are not visited.
+func walkValueNodes(node *yaml.Node, path string, visit func(node *yaml.Node, path string)) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			walkValueNodes(child, path, visit)
+		}
+		return
+	case yaml.MappingNode:
+		visit(node, path)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			walkValueNodes(node.Content[i+1], joinFieldPath(path, node.Content[i].Value), visit)
+		}
+	case yaml.SequenceNode:
+		visit(node, path)
+		for i, item := range node.Content {
+			walkValueNodes(item, fmt.Sprintf("%s[%d]", path, i), visit)
+		}
+	default:
+		visit(node, path)
+	}
+}
+
+// yamlNodeOnLine finds the value node on a line, preferring the first with the given tag
+// whose value starts with text.
+func yamlNodeOnLine(root *yaml.Node, line int, tag string, text string) (*yaml.Node, string) {
+	var first, found *yaml.Node
+	var firstPath, foundPath string
+	walkValueNodes(root, "", func(node *yaml.Node, path string) {
+		if node.Line != line {
+			return
+		}
+		if first == nil {
+			first, firstPath = node, path
+		}
+		if found == nil && (tag == "" || node.ShortTag() == tag) && strings.HasPrefix(node.Value, text) {
+			found, foundPath = node, path
+		}
+	})
+	if found == nil {
+		return first, firstPath
+	}
+	return found, foundPath
+}
+
+// jsonNodeBefore finds the last value node starting at or before a position, which for the
+// offset of a JSON decode error is the value that failed.
+func jsonNodeBefore(root *yaml.Node, line int, column int) (*yaml.Node, string) {
+	var found *yaml.Node
+	var foundPath string
+	walkValueNodes(root, "", func(node *yaml.Node, path string) {
+		if node.Line < line || node.Line == line && node.Column <= column {
+			found, foundPath = node, path
+		}
+	})
+	return found, foundPath
+}
@@ -0,0 +1,165 @@
+package converters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+type positionConfig struct {
+	Name    string `yaml:"name" json:"name"`
+	Port    int    `yaml:"port" json:"port"`
+	Servers []struct {
+		Port int `yaml:"port" json:"port"`
+	} `yaml:"servers" json:"servers"`
+}
+
+func TestUnmarshalFileYamlPositions(t *testing.T) {
+	file := writeTestFile(t, filepath.Join(t.TempDir(), "app.yaml"), "name: api\nport: eighty\nservers:\n  - port: 1\n  - port: [2]\n")
+	var config positionConfig
+	err := UnmarshalFile(file, &config)
+
+	var errs FieldErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Expected two field errors, got %v", err)
+	}
+	first := errs[0]
+	if first.File != file || first.Line != 2 || first.Column != 7 || first.Path != "port" || first.Message != "cannot unmarshal !!str `eighty` into int" {
+		t.Errorf("Unexpected error %+v", first)
+	}
+	if first.Snippet != "2 | port: eighty\n  |       ^" {
+		t.Errorf("Unexpected snippet\n%s", first.Snippet)
+	}
+	if errs[1].Line != 5 || errs[1].Column != 11 || errs[1].Path != "servers[1].port" {
+		t.Errorf("Unexpected error %+v", errs[1])
+	}
+	if !strings.Contains(err.Error(), "file: ["+file+"], line: [2], column: [7], field: [port], error: [cannot unmarshal") {
+		t.Errorf("Unexpected message %s", err)
+	}
+
+	var typeErr *yaml.TypeError
+	var fieldErr *FieldError
+	if !errors.As(err, &typeErr) || !errors.As(err, &fieldErr) || fieldErr != first {
+		t.Errorf("Expected the yaml.TypeError and FieldError through errors.As, got %v", err)
+	}
+
+	syntax := writeTestFile(t, filepath.Join(t.TempDir(), "broken.yml"), "name: api\nport: a: b\n")
+	err = UnmarshalFile(syntax, &config)
+	if !errors.As(err, &fieldErr) || fieldErr.Line != 2 || fieldErr.Column != 0 || fieldErr.Snippet != "2 | port: a: b" || fieldErr.Message != "mapping values are not allowed in this context" {
+		t.Errorf("Expected the line of the syntax error, got %+v", fieldErr)
+	}
+}
+
+func TestUnmarshalFileJsonPositions(t *testing.T) {
+	file := writeTestFile(t, filepath.Join(t.TempDir(), "app.json"), "{\n  \"name\": \"api\",\n  \"port\": x\n}\n")
+	var config positionConfig
+	err := UnmarshalFile(file, &config)
+	var syntaxErr *json.SyntaxError
+	var fieldErr *FieldError
+	if !errors.As(err, &syntaxErr) || !errors.As(err, &fieldErr) {
+		t.Fatalf("Expected a located json.SyntaxError, got %v", err)
+	}
+	if fieldErr.Line != 3 || fieldErr.Column != 11 || fieldErr.Snippet != "3 |   \"port\": x\n  |           ^" {
+		t.Errorf("Unexpected error %+v\n%s", fieldErr, fieldErr.Snippet)
+	}
+
+	typed := writeTestFile(t, filepath.Join(t.TempDir(), "typed.json"), `{"name": "api", "servers": [{"port": 1}, {"port": "80"}]}`)
+	err = UnmarshalJsonFile(typed, &config)
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || !errors.As(err, &fieldErr) {
+		t.Fatalf("Expected a located json.UnmarshalTypeError, got %v", err)
+	}
+	if fieldErr.Line != 1 || fieldErr.Column != 51 || fieldErr.Path != "servers[1].port" {
+		t.Errorf("Unexpected error %+v", fieldErr)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	for name, unmarshal := range map[string]func(string, interface{}) error{
+		"UnmarshalFile":           UnmarshalFile,
+		"UnmarshalJsonFile":       UnmarshalJsonFile,
+		"UnmarshalFileStrict":     UnmarshalFileStrict,
+		"UnmarshalJsonFileStrict": UnmarshalJsonFileStrict,
+	} {
+		err := unmarshal(missing, &config)
+		if errors.As(err, &fieldErr) || !errors.Is(err, os.ErrNotExist) || !strings.Contains(err.Error(), "missing.json") {
+			t.Errorf("%s: expected an unlocated read error wrapping os.ErrNotExist, got %v", name, err)
+		}
+	}
+}
+
+func TestUnmarshalFileCodecPositions(t *testing.T) {
+	file := writeTestFile(t, filepath.Join(t.TempDir(), "app.toml"), "name = \"api\"\nname = \"again\"\n")
+	var config map[string]interface{}
+	err := UnmarshalFile(file, &config)
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Line != 2 || fieldErr.Message != `key "name" is already defined` || fieldErr.Snippet != `2 | name = "again"` {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestUnmarshalFileXmlPositions(t *testing.T) {
+	file := writeTestFile(t, filepath.Join(t.TempDir(), "feed.xml"), "<feed>\n  <item></entry>\n</feed>\n")
+	var feed map[string]interface{}
+	err := UnmarshalFile(file, &feed)
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Line != 2 || fieldErr.Snippet != "2 |   <item></entry>" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestStrictSnippets(t *testing.T) {
+	file := writeTestFile(t, filepath.Join(t.TempDir(), "app.yaml"), "name: api\n\tnmae: x\n")
+	err := UnmarshalFileStrict(file, &positionConfig{})
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Line != 2 || fieldErr.Snippet != "2 | \tnmae: x" {
+		t.Errorf("Expected the line of the syntax error, got %v", err)
+	}
+
+	file = writeTestFile(t, filepath.Join(t.TempDir(), "app.yaml"), "name: api\nnmae: x\n")
+	err = UnmarshalFileStrict(file, &positionConfig{})
+	if !errors.As(err, &fieldErr) || fieldErr.Snippet != "2 | nmae: x\n  | ^" {
+		t.Errorf("Expected a snippet, got %v", err)
+	}
+}
+
+func TestFieldErrorFormat(t *testing.T) {
+	file := writeTestFile(t, filepath.Join(t.TempDir(), "app.yaml"), "name: api\nport: eighty\nservers: [{port: x}]\n")
+	err := UnmarshalFile(file, &positionConfig{})
+	var errs FieldErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("Expected two field errors, got %v", err)
+	}
+	if fmt.Sprint(err) != err.Error() || fmt.Sprintf("%s", errs[0]) != errs[0].Error() || fmt.Sprintf("%q", errs[0]) != strconv.Quote(errs[0].Error()) {
+		t.Errorf("Expected %%v, %%s and %%q to print the error alone, got %v", err)
+	}
+	expected := errs[0].Error() + "\n2 | port: eighty\n  |       ^\n" + errs[1].Error() + "\n3 | servers: [{port: x}]\n  |                  ^"
+	if detailed := fmt.Sprintf("%+v", err); detailed != expected {
+		t.Errorf("Expected the snippets with %%+v, got\n%s", detailed)
+	}
+	if detailed := fmt.Sprintf("%+v", &FieldError{Path: "port", Message: "is required"}); detailed != "field: [port], error: [is required]" {
+		t.Errorf("Expected no snippet when the source is unknown, got %s", detailed)
+	}
+}
+
+func TestSourceSnippet(t *testing.T) {
+	content := []byte("a: 1\n\tcafé: [x, y]\r\n")
+	if snippet := sourceSnippet(content, 2, 10); snippet != "2 | \tcafé: [x, y]\n  | \t        ^" {
+		t.Errorf("Unexpected snippet %q", snippet)
+	}
+	if snippet := sourceSnippet(content, 1, 0); snippet != "1 | a: 1" {
+		t.Errorf("Unexpected snippet %q", snippet)
+	}
+	if snippet := sourceSnippet(content, 9, 1); snippet != "" {
+		t.Errorf("Expected no snippet, got %q", snippet)
+	}
+	if line, column := offsetPosition(content, 12); line != 2 || column != 7 {
+		t.Errorf("Expected columns in characters, got %d:%d", line, column)
+	}
+}
@@ -0,0 +1,293 @@
+package converters
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/skhatri/go-fns/lib/collections"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// ToMap converts a struct, or a map, into a map[string]interface{} following the rules of
+// encoding/json: fields are named by their json tag, "-" and unexported fields are left out,
+// omitempty drops empty values, and embedded structs or fields tagged `json:",inline"` add
+// their fields to the enclosing map. Unlike a JSON round trip, numbers keep their Go types;
+// values implementing json.Marshaler or encoding.TextMarshaler, such as time.Time, become
+// their JSON form. Secrets are kept; call Redact first to mask fields tagged `secret:"true"`.
+// Returns an error if v is not a struct or map, or a value cannot be marshaled.
+func ToMap(v interface{}) (map[string]interface{}, error) {
+	return toMap(v, "json")
+}
+
+// toYamlMap converts v like ToMap but names fields as yaml does, by their yaml tag or their
+// lowercased name, and adds the fields of embedded structs only when tagged `yaml:",inline"`.
+// yaml.Marshaler and encoding.TextMarshaler values become their marshaled form, while
+// time.Time values are kept. Codecs whose content decodes like YAML encode with it, so what
+// they write reads back into the same fields.
+func toYamlMap(v interface{}) (map[string]interface{}, error) {
+	return toMap(v, "yaml")
+}
+
+// toMap converts v into a map, naming struct fields by the rules of tagKey, "json" or "yaml".
+func toMap(v interface{}, tagKey string) (map[string]interface{}, error) {
+	value, err := toMapValue(reflect.ValueOf(v), tagKey)
+	if err != nil {
+		return nil, fmt.Errorf("error converting %T to a map, with error %v", v, err)
+	}
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("error converting %T to a map, expected a struct or map", v)
+	}
+	return m, nil
+}
+
+func toMapValue(v reflect.Value, tagKey string) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, nil
+	}
+	if tagKey == "yaml" {
+		if converted, ok, err := yamlMarshaled(v); ok {
+			return converted, err
+		}
+	} else if v.Type().Implements(jsonMarshalerType) || v.Type().Implements(textMarshalerType) {
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		var out interface{}
+		return out, json.Unmarshal(data, &out)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return toMapValue(v.Elem(), tagKey)
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		return m, toMapFields(v, m, tagKey)
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			item, err := toMapValue(iter.Value(), tagKey)
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(iter.Key().Interface())] = item
+		}
+		return m, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface(), nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, err := toMapValue(v.Index(i), tagKey)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case reflect.String:
+		// Named string types, such as types.Secret, marshal their value but may print
+		// something else, so they are kept as plain strings.
+		return v.String(), nil
+	}
+	return v.Interface(), nil
+}
+
+// yamlMarshaled returns the form yaml gives to time.Time, yaml.Marshaler and
+// encoding.TextMarshaler values, and false for other values.
+func yamlMarshaled(v reflect.Value) (interface{}, bool, error) {
+	if !v.CanInterface() {
+		// An embedded unexported struct, whose exported fields are still converted.
+		return nil, false, nil
+	}
+	if v.Type() == timeType {
+		return v.Interface(), true, nil
+	}
+	switch marshaler := v.Interface().(type) {
+	case yaml.Marshaler:
+		out, err := marshaler.MarshalYAML()
+		if err != nil {
+			return nil, true, err
+		}
+		converted, err := toMapValue(reflect.ValueOf(out), "yaml")
+		return converted, true, err
+	case encoding.TextMarshaler:
+		text, err := marshaler.MarshalText()
+		return string(text), true, err
+	}
+	return nil, false, nil
+}
+
+// toMapFields adds the fields of struct v to m, named by the rules of tagKey. Fields of
+// inline structs are added after the struct's own fields and never replace them, as in
+// encoding/json.
+func toMapFields(v reflect.Value, m map[string]interface{}, tagKey string) error {
+	t := v.Type()
+	inline := make([]reflect.Value, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get(tagKey)
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fieldValue := v.Field(i)
+		embedded := field.Anonymous && tagKey == "json"
+		if name == "" && (embedded || strings.Contains(","+opts+",", ",inline,")) {
+			target := fieldValue
+			for target.Kind() == reflect.Pointer && !target.IsNil() {
+				target = target.Elem()
+			}
+			if target.Kind() == reflect.Struct || target.Kind() == reflect.Map {
+				inline = append(inline, target)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if strings.Contains(","+opts+",", ",omitempty,") && fieldValue.Kind() != reflect.Struct && isEmptyValue(fieldValue) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+			if tagKey == "yaml" {
+				name = strings.ToLower(field.Name)
+			}
+		}
+		value, err := toMapValue(fieldValue, tagKey)
+		if err != nil {
+			return err
+		}
+		m[name] = value
+	}
+
+	for _, target := range inline {
+		nested, err := toMapValue(target, tagKey)
+		if err != nil {
+			return err
+		}
+		for key, value := range nested.(map[string]interface{}) {
+			if _, exists := m[key]; !exists {
+				m[key] = value
+			}
+		}
+	}
+	return nil
+}
+
+// Flatten turns a nested tree, such as one returned by ToMap or UnmarshalYaml, into a single
+// level map keyed by dotted paths, so {"db": {"pool": {"size": 5}}} becomes {"db.pool.size": 5}.
+// List items are keyed by their index ("hosts.0") and dots within keys are escaped as "\.",
+// the path syntax of Get. Empty maps and lists are kept as values so Unflatten can restore them.
+// Non-string keys, such as the integer keys YAML decodes, are converted to strings.
+func Flatten(tree map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+	for key, item := range tree {
+		flatten(collections.MapByStringKey(item), escapeDottedSegments([]string{key})[0], flat)
+	}
+	return flat
+}
+
+func flatten(value interface{}, prefix string, flat map[string]interface{}) {
+	switch node := value.(type) {
+	case map[string]interface{}:
+		if len(node) > 0 {
+			for key, item := range node {
+				flatten(item, flatKey(prefix, escapeDottedSegments([]string{key})[0]), flat)
+			}
+			return
+		}
+	case []interface{}:
+		if len(node) > 0 {
+			for i, item := range node {
+				flatten(item, flatKey(prefix, strconv.Itoa(i)), flat)
+			}
+			return
+		}
+	}
+	flat[prefix] = value
+}
+
+func flatKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// Unflatten reverses Flatten, nesting dotted keys into maps. A nested map whose keys are
+// exactly 0 to n-1 becomes a list; the top level stays a map, so "0" and "1" remain keys.
+// Returns an error if one key is a prefix of another, such as "db" and "db.host".
+func Unflatten(flat map[string]interface{}) (map[string]interface{}, error) {
+	tree := make(map[string]interface{})
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		segments := splitDottedPath(key)
+		node := tree
+		for i, segment := range segments[:len(segments)-1] {
+			next, exists := node[segment]
+			if !exists {
+				next = make(map[string]interface{})
+				node[segment] = next
+			}
+			child, ok := next.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("key: [%s], error: [conflicts with the value at %s]", key, strings.Join(escapeDottedSegments(segments[:i+1]), "."))
+			}
+			node = child
+		}
+		last := segments[len(segments)-1]
+		if _, exists := node[last]; exists {
+			return nil, fmt.Errorf("key: [%s], error: [conflicts with the keys below it]", key)
+		}
+		node[last] = flat[key]
+	}
+	for key, item := range tree {
+		tree[key] = listsFromIndexes(item)
+	}
+	return tree, nil
+}
+
+// listsFromIndexes turns maps keyed by "0" to "n-1" back into lists.
+func listsFromIndexes(value interface{}) interface{} {
+	node, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	for key, item := range node {
+		node[key] = listsFromIndexes(item)
+	}
+	items := make([]interface{}, len(node))
+	for i := range items {
+		item, ok := node[strconv.Itoa(i)]
+		if !ok {
+			return node
+		}
+		items[i] = item
+	}
+	if len(items) == 0 {
+		return node
+	}
+	return items
+}
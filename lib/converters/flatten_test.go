@@ -0,0 +1,160 @@
+package converters
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skhatri/go-fns/lib/types"
+)
+
+type toMapPool struct {
+	Size int `json:"size"`
+}
+
+type toMapMeta struct {
+	Service string `json:"service"`
+	Name    string `json:"name"`
+}
+
+type toMapConfig struct {
+	toMapMeta
+	Name     string            `json:"name"`
+	Pool     *toMapPool        `json:"pool,omitempty"`
+	Empty    *toMapPool        `json:"empty,omitempty"`
+	Hosts    []string          `json:"hosts,omitempty"`
+	Labels   map[string]string `json:"labels"`
+	Started  time.Time         `json:"started"`
+	Password string            `json:"password" secret:"true"`
+	Token    types.Secret      `json:"token"`
+	Extra    map[string]int    `json:",inline"`
+	Skipped  string            `json:"-"`
+	Plain    uint8
+	hidden   string
+}
+
+func TestToMap(t *testing.T) {
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	m, err := ToMap(&toMapConfig{
+		toMapMeta: toMapMeta{Service: "api", Name: "shadowed"},
+		Name:      "primary",
+		Pool:      &toMapPool{Size: 5},
+		Labels:    map[string]string{"tier": "web"},
+		Started:   started,
+		Password:  "hunter2",
+		Token:     "abc",
+		Extra:     map[string]int{"retries": 3, "name": 0},
+		Skipped:   "no",
+		Plain:     7,
+		hidden:    "no",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"service":  "api",
+		"name":     "primary",
+		"pool":     map[string]interface{}{"size": 5},
+		"labels":   map[string]interface{}{"tier": "web"},
+		"started":  "2024-01-02T03:04:05Z",
+		"password": "hunter2",
+		"token":    "abc",
+		"retries":  3,
+		"Plain":    uint8(7),
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Expected %v, got %v", expected, m)
+	}
+
+	if m, err := ToMap(map[int]interface{}{1: []int{2}}); err != nil || !reflect.DeepEqual(m, map[string]interface{}{"1": []interface{}{2}}) {
+		t.Errorf("Unexpected map result %v, %v", m, err)
+	}
+	if _, err := ToMap([]string{"a"}); err == nil || !strings.Contains(err.Error(), "expected a struct or map") {
+		t.Errorf("Expected shape error, got %v", err)
+	}
+	if _, err := ToMap(map[string]interface{}{"bad": errorMarshaler{}}); err == nil {
+		t.Error("Expected marshal error")
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	tree := map[string]interface{}{
+		"db": map[string]interface{}{
+			"pool":  map[string]interface{}{"size": 5},
+			"hosts": []interface{}{"a", map[interface{}]interface{}{"port": 1}},
+		},
+		"dotted.key": true,
+		"empty":      map[string]interface{}{},
+		"none":       []interface{}{},
+		"nil":        nil,
+	}
+	flat := Flatten(tree)
+	expected := map[string]interface{}{
+		"db.pool.size":    5,
+		"db.hosts.0":      "a",
+		"db.hosts.1.port": 1,
+		`dotted\.key`:     true,
+		"empty":           map[string]interface{}{},
+		"none":            []interface{}{},
+		"nil":             nil,
+	}
+	if !reflect.DeepEqual(flat, expected) {
+		t.Errorf("Expected %v, got %v", expected, flat)
+	}
+
+	restored, err := Unflatten(flat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree["db"].(map[string]interface{})["hosts"] = []interface{}{"a", map[string]interface{}{"port": 1}}
+	if !reflect.DeepEqual(restored, tree) {
+		t.Errorf("Expected %v, got %v", tree, restored)
+	}
+	if value, err := Get(restored, `dotted\.key`); err != nil || value != true {
+		t.Errorf("Expected the escaped key to match Get, got %v, %v", value, err)
+	}
+
+	if flat := Flatten(map[string]interface{}{}); len(flat) != 0 {
+		t.Errorf("Expected an empty map, got %v", flat)
+	}
+
+	var decoded map[string]interface{}
+	if err := UnmarshalYaml([]byte("codes:\n  404: missing\n  true: yes\nports: {80: http}\n"), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	expected = map[string]interface{}{"codes.404": "missing", "codes.true": "yes", "ports.80": "http"}
+	if flat := Flatten(decoded); !reflect.DeepEqual(flat, expected) {
+		t.Errorf("Expected %v, got %v", expected, flat)
+	}
+}
+
+func TestUnflatten(t *testing.T) {
+	tree, err := Unflatten(map[string]interface{}{"a.0": 1, "a.2": 3, "b.1": "x", "b.0": "y"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"a": map[string]interface{}{"0": 1, "2": 3},
+		"b": []interface{}{"y", "x"},
+	}
+	if !reflect.DeepEqual(tree, expected) {
+		t.Errorf("Expected %v, got %v", expected, tree)
+	}
+
+	fields, err := ToMap(map[int]string{0: "a", 1: "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree, err := Unflatten(Flatten(fields)); err != nil || !reflect.DeepEqual(tree, map[string]interface{}{"0": "a", "1": "b"}) {
+		t.Errorf("Expected index keys to stay a map at the top level, got %v, %v", tree, err)
+	}
+	var indexed map[string]interface{}
+	if err := UnmarshalProperties([]byte("0=a\n1.0=b\n"), &indexed); err != nil || !reflect.DeepEqual(indexed, map[string]interface{}{"0": "a", "1": []interface{}{"b"}}) {
+		t.Errorf("Unexpected properties %v, %v", indexed, err)
+	}
+
+	if _, err := Unflatten(map[string]interface{}{"db": 1, "db.host": "x"}); err == nil || !strings.Contains(err.Error(), "key: [db.host], error: [conflicts with the value at db]") {
+		t.Errorf("Expected conflict, got %v", err)
+	}
+}
@@ -9,34 +9,114 @@ import (
 	"io"
 	"os"
 
+	"github.com/skhatri/go-fns/lib/fs"
 	"gopkg.in/yaml.v3"
 )
 
 // UnmarshalFile reads a file and unmarshals its contents into the provided data structure.
-// The file format is determined by its extension (.json or .yaml).
-// Returns an error if the file cannot be read or unmarshaled.
+// The file format is determined by its extension (.json or .yaml, or any other registered
+// codec such as .csv). JSON and YAML files decode like YAML, by yaml tags, unless
+// EnableJson5ForJson is on, when .json files decode like .json5 files, by json tags as
+// encoding/json does. Files compressed with a registered compression, such as
+// config.json.gz or dump.yaml.bz2, are decompressed as they are read.
+// Returns an error if the file cannot be read or unmarshaled. Decode failures are reported as
+// FieldErrors with the line, column, field path and a snippet of the offending source.
 func UnmarshalFile(file string, t interface{}) error {
-	content, err := os.ReadFile(file)
+	content, err := readFile(file)
 	if err != nil {
-		return fmt.Errorf("file: [%s], error: [%v]", file, err)
+		return fmt.Errorf("file: [%s], error: [%w]", file, err)
 	}
-	err = UnmarshalYaml(content, t)
-	if err != nil {
-		return fmt.Errorf("file: [%s], error: [%v]", file, err)
+	return unmarshalFileContent(file, content, t)
+}
+
+// unmarshalFileContent decodes content read from file with the codec UnmarshalFile picks
+// for the file's extension.
+func unmarshalFileContent(file string, content []byte, t interface{}) error {
+	unmarshal := UnmarshalYaml
+	_, name, _ := CompressionForFile(file)
+	codec, ok := CodecForFile(name)
+	relaxed := ok && codec.Format == FormatJSON && json5ForJson.Load()
+	switch {
+	case relaxed:
+		unmarshal = UnmarshalJson5
+	case ok && codec.Format != FormatJSON && codec.Format != FormatYAML:
+		unmarshal = codec.Unmarshal
+	}
+	if err := unmarshal(content, t); err != nil {
+		// yaml reports only the line of a JSON syntax error, so JSON files are checked again
+		// for the exact position.
+		if ok && codec.Format == FormatJSON && !relaxed && !json.Valid(content) {
+			var tree interface{}
+			err = UnmarshalJson(content, &tree)
+		}
+		return locateError(file, content, err)
 	}
 	return nil
 }
 
+// readFile reads a file, decompressing it when its extension names a registered compression.
+func readFile(file string) ([]byte, error) {
+	compression, _, compressed := CompressionForFile(file)
+	if !compressed {
+		return os.ReadFile(file)
+	}
+	if compression.NewReader == nil {
+		return nil, fmt.Errorf("compression: [%s], error: [%w]", compression.Extension, ErrNoCompressor)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	reader, err := compression.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// MarshalFile marshals the provided data structure with the codec for the file's extension
+// and writes it to the file, compressing it when the name ends with a registered compression
+// extension such as .gz. The file is replaced atomically with permission 0644.
+// Returns an error if no codec handles the extension, the compression cannot be written, the
+// data cannot be marshaled or the file cannot be written.
+func MarshalFile(t interface{}, path string) error {
+	return MarshalFileWithOptions(t, path, fs.WriteOptions{})
+}
+
+// MarshalFileWithOptions marshals the provided data structure like MarshalFile and writes it
+// to a file atomically, with the mode, backup and directory creation given in opts.
+// Returns an error if the data cannot be marshaled or if the file cannot be written.
+func MarshalFileWithOptions(t interface{}, path string, opts fs.WriteOptions) error {
+	compression, name, compressed := CompressionForFile(path)
+	codec, ok := CodecForFile(name)
+	if !ok {
+		return fmt.Errorf("file: [%s], error: [%w]", path, ErrNoCodec)
+	}
+	data, err := codec.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if compressed {
+		if data, err = Compress(compression, data); err != nil {
+			return fmt.Errorf("file: [%s], error: [%w]", path, err)
+		}
+	}
+	return fs.WriteFileAtomicWithOptions(path, data, opts)
+}
+
 // UnmarshalJsonFile reads a JSON file and unmarshals its contents into the provided data structure.
-// Returns an error if the file cannot be read or unmarshaled as JSON.
+// Returns an error if the file cannot be read, or FieldErrors locating why it cannot be
+// unmarshaled as JSON, or as JSON5 once EnableJson5ForJson is on.
 func UnmarshalJsonFile(file string, t interface{}) error {
-	content, err := os.ReadFile(file)
+	content, err := readFile(file)
 	if err != nil {
-		return fmt.Errorf("file: [%s], error: [%v]", file, err)
+		return fmt.Errorf("file: [%s], error: [%w]", file, err)
 	}
-	err = UnmarshalJson(content, t)
+	err = unmarshalJsonContent(content, t)
 	if err != nil {
-		return fmt.Errorf("file: [%s], error: [%v]", file, err)
+		return locateError(file, content, err)
 	}
 	return nil
 }
@@ -46,7 +126,7 @@ func UnmarshalJsonFile(file string, t interface{}) error {
 func UnmarshalJson(content []byte, t interface{}) error {
 	err := json.Unmarshal(content, t)
 	if err != nil {
-		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+		return fmt.Errorf("error unmarshalling to %T, with error %w", t, err)
 	}
 	return nil
 }
@@ -56,85 +136,179 @@ func UnmarshalJson(content []byte, t interface{}) error {
 func UnmarshalYaml(content []byte, t interface{}) error {
 	err := yaml.Unmarshal(content, t)
 	if err != nil {
-		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+		return fmt.Errorf("error unmarshalling to %T, with error %w", t, err)
 	}
 	return nil
 }
 
 // MarshalToYamlFile marshals the provided data structure to YAML and writes it to a file.
+// The file is replaced atomically, keeping its permission or using 0644 for a new file, see
+// fs.WriteFileAtomic.
 // Returns an error if the data cannot be marshaled or if the file cannot be written.
 func MarshalToYamlFile(t interface{}, path string) error {
+	return MarshalToYamlFileWithOptions(t, path, fs.WriteOptions{})
+}
+
+// MarshalToYamlFileWithOptions marshals the provided data structure to YAML and writes it
+// to a file atomically, with the mode, backup and directory creation given in opts.
+// Returns an error if the data cannot be marshaled or if the file cannot be written.
+func MarshalToYamlFileWithOptions(t interface{}, path string, opts fs.WriteOptions) error {
 	data, marshalErr := yaml.Marshal(t)
 	if marshalErr != nil {
 		return marshalErr
 	}
-	return os.WriteFile(path, data, os.ModePerm)
+	return fs.WriteFileAtomicWithOptions(path, data, opts)
+}
+
+// YamlOptions controls MarshalToYamlWithOptions. The zero value matches the YAML file writers.
+type YamlOptions struct {
+	// Redact masks fields tagged `secret:"true"` and types.Secret values, see Redact. Use it
+	// for output meant for logs, never for files or data that is read back.
+	Redact bool
+}
+
+// MarshalToYamlWithOptions marshals the provided data structure to YAML, with or without
+// secrets as set in opts.
+// Returns an error if the data cannot be marshaled.
+func MarshalToYamlWithOptions(item interface{}, opts YamlOptions) ([]byte, error) {
+	if opts.Redact {
+		item = Redact(item)
+	}
+	data, err := yaml.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling %T, with error %w", item, err)
+	}
+	return data, nil
 }
 
 // MarshalToJsonFile marshals the provided data structure to JSON and writes it to a file.
+// The file is replaced atomically, keeping its permission or using 0644 for a new file, see
+// fs.WriteFileAtomic.
 // Returns an error if the data cannot be marshaled or if the file cannot be written.
 func MarshalToJsonFile(t interface{}, path string) error {
+	return MarshalToJsonFileWithOptions(t, path, fs.WriteOptions{})
+}
+
+// MarshalToJsonFileWithOptions marshals the provided data structure to JSON and writes it
+// to a file atomically, with the mode, backup and directory creation given in opts.
+// Returns an error if the data cannot be marshaled or if the file cannot be written.
+func MarshalToJsonFileWithOptions(t interface{}, path string, opts fs.WriteOptions) error {
 	data, err := marshalJson(t, false)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, os.ModePerm)
+	return fs.WriteFileAtomicWithOptions(path, data, opts)
 }
 
 // MarshalToJsonPrettyFile marshals the provided data structure to pretty-printed JSON
-// and writes it to a file. Returns an error if the data cannot be marshaled or if the file
-// cannot be written.
+// and writes it to a file. The file is replaced atomically with permission 0644.
+// Returns an error if the data cannot be marshaled or if the file cannot be written.
 func MarshalToJsonPrettyFile(t interface{}, path string) error {
+	return MarshalToJsonPrettyFileWithOptions(t, path, fs.WriteOptions{})
+}
+
+// MarshalToJsonPrettyFileWithOptions marshals the provided data structure to pretty-printed
+// JSON and writes it to a file atomically, with the mode, backup and directory creation
+// given in opts.
+// Returns an error if the data cannot be marshaled or if the file cannot be written.
+func MarshalToJsonPrettyFileWithOptions(t interface{}, path string, opts fs.WriteOptions) error {
 	data, e := marshalJson(t, true)
 	if e != nil {
 		return e
 	}
-	return os.WriteFile(path, data, os.ModePerm)
+	return fs.WriteFileAtomicWithOptions(path, data, opts)
+}
+
+// JsonOptions controls MarshalToJsonWithOptions. The zero value matches MarshalToJson:
+// indented by two spaces and ending with a newline.
+type JsonOptions struct {
+	// Compact writes the JSON without indentation or line breaks.
+	Compact bool
+	// Indent is the indentation of pretty output, two spaces when empty.
+	Indent string
+	// OmitTrailingNewline drops the newline that ends the output.
+	OmitTrailingNewline bool
+	// Redact masks fields tagged `secret:"true"` and types.Secret values, see Redact. Use it
+	// for output meant for logs, never for files or data that is read back.
+	Redact bool
 }
 
 // MarshalToJson marshals the provided data structure to JSON.
 // Returns the JSON byte slice and any error that occurred during marshaling.
 func MarshalToJson(item interface{}) string {
-	b, err := marshalJson(item, true)
+	b, err := MarshalToJsonWithOptions(item, JsonOptions{})
 	if err == nil {
 		return string(b)
 	}
 	return ""
 }
 
-// marshalJson is an internal function that marshals data to JSON with optional pretty printing.
-// The pretty parameter determines whether the output should be formatted with indentation.
-func marshalJson(item interface{}, pretty bool) ([]byte, error) {
+// MarshalToJsonBytes marshals the provided data structure to compact JSON on one line,
+// ending with a newline. Use MarshalToJsonPrettyBytes for indented output.
+// Returns an error if the data cannot be marshaled.
+func MarshalToJsonBytes(item interface{}) ([]byte, error) {
+	return marshalJson(item, false)
+}
+
+// MarshalToJsonString marshals the provided data structure to compact JSON like
+// MarshalToJsonBytes. Use MarshalToJsonPrettyString for indented output.
+// Returns an error if the data cannot be marshaled.
+func MarshalToJsonString(item interface{}) (string, error) {
+	data, err := MarshalToJsonBytes(item)
+	return string(data), err
+}
+
+// MarshalToJsonWithOptions marshals the provided data structure to JSON, compact or
+// indented, with or without a trailing newline and with or without secrets as set in opts.
+// Returns an error if the data cannot be marshaled.
+func MarshalToJsonWithOptions(item interface{}, opts JsonOptions) ([]byte, error) {
 	bff := bytes.Buffer{}
 	encoder := json.NewEncoder(&bff)
-	if pretty {
-		encoder.SetIndent("", "  ")
+	if !opts.Compact {
+		indent := opts.Indent
+		if indent == "" {
+			indent = "  "
+		}
+		encoder.SetIndent("", indent)
 	}
-	err := encoder.Encode(item)
-	if err == nil {
-		return bff.Bytes(), nil
+	if opts.Redact {
+		item = Redact(item)
+	}
+	if err := encoder.Encode(item); err != nil {
+		return nil, fmt.Errorf("error marshalling %T, with error %w", item, err)
 	}
-	return nil, err
+	data := bff.Bytes()
+	if opts.OmitTrailingNewline {
+		data = bytes.TrimSuffix(data, []byte("\n"))
+	}
+	return data, nil
+}
+
+// marshalJson is an internal function that marshals data to JSON with optional pretty printing.
+// The pretty parameter determines whether the output should be formatted with indentation.
+func marshalJson(item interface{}, pretty bool) ([]byte, error) {
+	return MarshalToJsonWithOptions(item, JsonOptions{Compact: !pretty})
 }
 
 // MarshalToJsonPretty marshals the provided data structure to pretty-printed JSON.
 // Returns the formatted JSON byte slice and any error that occurred during marshaling.
 func MarshalToJsonPretty(item interface{}) string {
-	data, err := marshalJson(item, true)
+	data, err := MarshalToJsonWithOptions(item, JsonOptions{})
 	if err == nil {
 		return string(data)
 	}
 	return ""
 }
 
-// ReadTo reads data from a reader and unmarshals it into the provided data structure.
-// The format is determined by the provided format string ("json" or "yaml").
-// Returns an error if the data cannot be read or unmarshaled.
-func ReadTo(src io.Reader, t interface{}) error {
-	bb := bytes.Buffer{}
-	_, err := bb.ReadFrom(src)
-	if err != nil {
-		return err
-	}
-	return json.Unmarshal(bb.Bytes(), t)
+// MarshalToJsonPrettyBytes marshals the provided data structure to pretty-printed JSON.
+// Returns an error if the data cannot be marshaled.
+func MarshalToJsonPrettyBytes(item interface{}) ([]byte, error) {
+	return marshalJson(item, true)
+}
+
+// MarshalToJsonPrettyString marshals the provided data structure to pretty-printed JSON.
+// Returns an error if the data cannot be marshaled.
+func MarshalToJsonPrettyString(item interface{}) (string, error) {
+	data, err := marshalJson(item, true)
+	return string(data), err
 }
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/skhatri/go-fns/lib/fs"
 )
 
 type TestStruct struct {
@@ -24,6 +27,18 @@ func (e errorMarshaler) MarshalJSON() ([]byte, error) {
 	return nil, fmt.Errorf("marshal error")
 }
 
+// writeTestFile writes content to file, creating its directory, and returns the file name.
+func writeTestFile(t *testing.T, file string, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
 func TestUnmarshalFile(t *testing.T) {
 	t.Run("unmarshal yaml file", func(t *testing.T) {
 		tempDir := t.TempDir()
@@ -342,6 +357,57 @@ func TestMarshalToJsonPrettyFile(t *testing.T) {
 	})
 }
 
+func TestMarshalToFileWithOptions(t *testing.T) {
+	writers := map[string]func(item interface{}, path string, opts fs.WriteOptions) error{
+		"yaml":        MarshalToYamlFileWithOptions,
+		"json":        MarshalToJsonFileWithOptions,
+		"json pretty": MarshalToJsonPrettyFileWithOptions,
+		"yaml documents": func(item interface{}, path string, opts fs.WriteOptions) error {
+			return MarshalToYamlDocumentsFileWithOptions([]interface{}{item}, path, opts)
+		},
+	}
+	for name, write := range writers {
+		t.Run(name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			testFile := filepath.Join(tempDir, "nested", "out")
+			opts := fs.WriteOptions{Mode: 0o600, Backup: true, CreateDirs: true}
+
+			if err := write(TestStruct{Name: "first", Value: 1}, testFile, opts); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if err := write(TestStruct{Name: "second", Value: 2}, testFile, opts); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var result, previous TestStruct
+			if err := UnmarshalFile(testFile, &result); err != nil || result.Name != "second" {
+				t.Errorf("Expected second write, got %v, %v", result, err)
+			}
+			if err := UnmarshalFile(testFile+fs.DefaultBackupSuffix, &previous); err != nil || previous.Name != "first" {
+				t.Errorf("Expected backup of first write, got %v, %v", previous, err)
+			}
+			info, _ := os.Stat(testFile)
+			if info.Mode().Perm() != 0o600 {
+				t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+			}
+			if err := write(errorMarshaler{}, testFile, opts); err == nil {
+				t.Error("Expected error for marshal failure")
+			}
+		})
+	}
+
+	t.Run("default mode", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "test.json")
+		if err := MarshalToJsonFile(TestStruct{}, testFile); err != nil {
+			t.Fatal(err)
+		}
+		info, _ := os.Stat(testFile)
+		if info.Mode().Perm() != fs.DefaultFileMode {
+			t.Errorf("Expected mode %v, got %v", fs.DefaultFileMode, info.Mode().Perm())
+		}
+	})
+}
+
 func TestMarshalToJson(t *testing.T) {
 	t.Run("marshal to json string", func(t *testing.T) {
 		data := TestStruct{Name: "test", Value: 42}
@@ -403,6 +469,63 @@ func TestMarshalToJsonPretty(t *testing.T) {
 	})
 }
 
+func TestMarshalToJsonWithErrors(t *testing.T) {
+	data := TestStruct{Name: "test", Value: 42}
+	pretty := "{\n  \"name\": \"test\",\n  \"value\": 42\n}\n"
+
+	t.Run("error-returning variants", func(t *testing.T) {
+		compact := "{\"name\":\"test\",\"value\":42}\n"
+		b, err := MarshalToJsonBytes(data)
+		if err != nil || string(b) != compact {
+			t.Errorf("Unexpected result %q, %v", b, err)
+		}
+		s, err := MarshalToJsonString(data)
+		if err != nil || s != compact {
+			t.Errorf("Unexpected result %q, %v", s, err)
+		}
+		b, err = MarshalToJsonPrettyBytes(data)
+		if err != nil || string(b) != pretty {
+			t.Errorf("Unexpected result %q, %v", b, err)
+		}
+		s, err = MarshalToJsonPrettyString(data)
+		if err != nil || s != pretty || s != MarshalToJsonPretty(data) {
+			t.Errorf("Unexpected result %q, %v", s, err)
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		for expected, opts := range map[string]JsonOptions{
+			pretty:                                         {},
+			"{\"name\":\"test\",\"value\":42}\n":           {Compact: true},
+			"{\"name\":\"test\",\"value\":42}":             {Compact: true, OmitTrailingNewline: true},
+			"{\n\t\"name\": \"test\",\n\t\"value\": 42\n}": {Indent: "\t", OmitTrailingNewline: true},
+		} {
+			b, err := MarshalToJsonWithOptions(data, opts)
+			if err != nil || string(b) != expected {
+				t.Errorf("Options %+v: expected %q, got %q, %v", opts, expected, b, err)
+			}
+		}
+	})
+
+	t.Run("errors are reported", func(t *testing.T) {
+		if _, err := MarshalToJsonBytes(errorMarshaler{}); err == nil || !strings.Contains(err.Error(), "marshal error") {
+			t.Errorf("Expected marshal error, got %v", err)
+		}
+		if s, err := MarshalToJsonString(make(chan int)); err == nil || s != "" {
+			t.Errorf("Expected error, got %q, %v", s, err)
+		}
+		if _, err := MarshalToJsonPrettyBytes(errorMarshaler{}); err == nil {
+			t.Error("Expected marshal error")
+		}
+		if _, err := MarshalToJsonPrettyString(errorMarshaler{}); err == nil {
+			t.Error("Expected marshal error")
+		}
+		if _, err := MarshalToJsonWithOptions(errorMarshaler{}, JsonOptions{Compact: true}); err == nil {
+			t.Error("Expected marshal error")
+		}
+	})
+}
+
 func TestReadTo(t *testing.T) {
 	t.Run("read from reader to struct", func(t *testing.T) {
 		content := []byte(`{"name": "test", "value": 42}`)
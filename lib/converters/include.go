@@ -0,0 +1,193 @@
+package converters
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultIncludeDepth is how deeply includes and references may nest unless
+// IncludeOptions.MaxDepth says otherwise.
+const DefaultIncludeDepth = 32
+
+// ErrIncludeCycle is returned, wrapped with the chain of files, when an include or reference
+// leads back to a file or fragment that is still being resolved.
+var ErrIncludeCycle = errors.New("include cycle")
+
+// IncludeOptions controls ResolveIncludesWithOptions.
+type IncludeOptions struct {
+	// MaxDepth limits how deeply includes and references may nest, DefaultIncludeDepth when 0.
+	MaxDepth int
+}
+
+// ResolveIncludes reads a YAML or JSON file and replaces, recursively, every scalar tagged
+// `!include other.yaml` with the content of that file and every `{"$ref": "file.json#/path"}`
+// mapping with the value the reference addresses. Paths are relative to the including file,
+// the optional "#" fragment is a JSON Pointer into the target, and "#/path" alone refers to
+// the including file itself. Only the first document of a file is read.
+// Returns the resolved content node, or an error if a file cannot be read, a fragment does not
+// exist, includes form a cycle (ErrIncludeCycle) or nest deeper than DefaultIncludeDepth.
+func ResolveIncludes(file string) (*yaml.Node, error) {
+	return ResolveIncludesWithOptions(file, IncludeOptions{})
+}
+
+// ResolveIncludesWithOptions resolves includes and references like ResolveIncludes, with the
+// depth limit given in opts.
+func ResolveIncludesWithOptions(file string, opts IncludeOptions) (*yaml.Node, error) {
+	maxDepth := opts.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultIncludeDepth
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, fmt.Errorf("file: [%s], error: [%w]", file, err)
+	}
+	r := &includeResolver{maxDepth: maxDepth}
+	return r.resolve(abs, "")
+}
+
+// UnmarshalFileWithIncludes reads a file with ResolveIncludes and decodes the resolved
+// content into t like UnmarshalFile.
+// Returns an error if the includes cannot be resolved or the content cannot be decoded.
+func UnmarshalFileWithIncludes(file string, t interface{}) error {
+	node, err := ResolveIncludes(file)
+	if err != nil {
+		return err
+	}
+	if err := node.Decode(t); err != nil {
+		return fmt.Errorf("file: [%s], error: [error unmarshalling to %T, with error %v]", file, t, err)
+	}
+	return nil
+}
+
+type includeResolver struct {
+	maxDepth int
+	// stack holds the "file#pointer" targets being resolved, outermost first.
+	stack []string
+}
+
+// resolve loads file, finds the node at pointer and resolves the includes and references in it.
+// Each target is parsed afresh, so resolving it in place never leaks into another target.
+func (r *includeResolver) resolve(file string, pointer string) (*yaml.Node, error) {
+	key := file + "#" + pointer
+	for i, active := range r.stack {
+		if active == key {
+			chain := append(append([]string{}, r.stack[i:]...), key)
+			return nil, fmt.Errorf("file: [%s], error: [%w: %s]", file, ErrIncludeCycle, strings.Join(chain, " -> "))
+		}
+	}
+	if len(r.stack) >= r.maxDepth {
+		return nil, fmt.Errorf("file: [%s], error: [includes nest deeper than %d]", file, r.maxDepth)
+	}
+
+	root, err := loadIncludeFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("file: [%s], error: [%w]", file, err)
+	}
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, fmt.Errorf("file: [%s], error: [%w]", file, err)
+	}
+	target := root
+	for _, token := range tokens {
+		if target, _, err = yamlChild(resolveAlias(target), token); err != nil {
+			return nil, fmt.Errorf("file: [%s], error: [%w, %v at #%s]", file, ErrPathNotFound, err, pointer)
+		}
+	}
+
+	r.stack = append(r.stack, key)
+	defer func() {
+		r.stack = r.stack[:len(r.stack)-1]
+	}()
+	if err := r.walk(file, target, make(map[*yaml.Node]bool)); err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// walk replaces the includes and references in node and below it. Aliases are followed so that
+// anchors outside a referenced fragment are resolved too; seen stops shared anchors being
+// walked twice.
+func (r *includeResolver) walk(file string, node *yaml.Node, seen map[*yaml.Node]bool) error {
+	if seen[node] {
+		return nil
+	}
+	seen[node] = true
+
+	if ref, ok, err := includeReference(node); err != nil {
+		return fmt.Errorf("file: [%s], error: [line %d: %v]", file, node.Line, err)
+	} else if ok {
+		target, pointer, _ := strings.Cut(ref, "#")
+		targetFile := file
+		if target != "" {
+			targetFile = target
+			if !filepath.IsAbs(targetFile) {
+				targetFile = filepath.Join(filepath.Dir(file), targetFile)
+			}
+		}
+		resolved, err := r.resolve(filepath.Clean(targetFile), pointer)
+		if err != nil {
+			return err
+		}
+		replaceYamlNode(node, resolved)
+		return nil
+	}
+
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		return r.walk(file, node.Alias, seen)
+	}
+	for _, child := range node.Content {
+		if err := r.walk(file, child, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// includeReference returns the file and fragment that node refers to, when node is a scalar
+// tagged !include or a mapping holding only a "$ref" key.
+func includeReference(node *yaml.Node) (string, bool, error) {
+	switch {
+	case node.Tag == "!include":
+		if node.Kind != yaml.ScalarNode || node.Value == "" {
+			return "", false, errors.New("!include needs a file name")
+		}
+		return node.Value, true, nil
+	case node.Kind == yaml.MappingNode && len(node.Content) == 2 && node.Content[0].Value == "$ref":
+		ref := node.Content[1]
+		if ref.Kind != yaml.ScalarNode || ref.Value == "" {
+			return "", false, errors.New("$ref needs a file name or #/pointer")
+		}
+		return ref.Value, true, nil
+	}
+	return "", false, nil
+}
+
+// loadIncludeFile parses the first document of a file into a node. Formats with a registered
+// codec other than JSON and YAML, such as CSV, are decoded with the codec and re-encoded.
+func loadIncludeFile(file string) (*yaml.Node, error) {
+	content, err := readFile(file)
+	if err != nil {
+		return nil, err
+	}
+	_, name, _ := CompressionForFile(file)
+	if codec, ok := CodecForFile(name); ok && codec.Format != FormatJSON && codec.Format != FormatYAML {
+		var value interface{}
+		if err := codec.Unmarshal(content, &value); err != nil {
+			return nil, err
+		}
+		node := &yaml.Node{}
+		return node, node.Encode(value)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		return doc.Content[0], nil
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+}
@@ -0,0 +1,128 @@
+package converters
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveIncludes(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"app.yaml": `name: app
+db: !include conf/db.yaml
+defaults: &defaults
+  replicas: !include conf/numbers.json#/replicas
+web:
+  <<: *defaults
+  port: 80
+limits: {"$ref": "conf/numbers.json#/limits"}
+copy:
+  $ref: "#/name"
+users: !include users.csv
+`,
+		"conf/db.yaml": `host: db.local
+credentials: !include ../secrets/creds.yaml
+`,
+		"secrets/creds.yaml": "user: admin\n",
+		"conf/numbers.json":  `{"replicas": 3, "limits": {"cpu": "2", "mem": {"$ref": "#/replicas"}}}`,
+		"users.csv":          "name,admin\nada,true\n",
+	} {
+		writeTestFile(t, filepath.Join(dir, name), content)
+	}
+
+	var result map[string]interface{}
+	if err := UnmarshalFileWithIncludes(filepath.Join(dir, "app.yaml"), &result); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"name":     "app",
+		"db":       map[string]interface{}{"host": "db.local", "credentials": map[string]interface{}{"user": "admin"}},
+		"defaults": map[string]interface{}{"replicas": 3},
+		"web":      map[string]interface{}{"replicas": 3, "port": 80},
+		"limits":   map[string]interface{}{"cpu": "2", "mem": 3},
+		"copy":     "app",
+		"users":    []interface{}{map[string]interface{}{"name": "ada", "admin": true}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	node, err := ResolveIncludes(filepath.Join(dir, "conf", "db.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var db struct {
+		Credentials struct {
+			User string `yaml:"user"`
+		} `yaml:"credentials"`
+	}
+	if err := node.Decode(&db); err != nil || db.Credentials.User != "admin" {
+		t.Errorf("Unexpected result %+v, %v", db, err)
+	}
+}
+
+func TestResolveIncludesErrors(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.yaml":       "next: !include b.yaml\n",
+		"b.yaml":       "next: !include a.yaml\n",
+		"self.yaml":    "a: {$ref: '#/b'}\nb: {$ref: '#/a'}\n",
+		"root.yaml":    "all: {$ref: '#'}\n",
+		"chain1.yaml":  "next: !include chain2.yaml\n",
+		"chain2.yaml":  "next: !include chain3.yaml\n",
+		"chain3.yaml":  "end: true\n",
+		"missing.yaml": "x: !include nowhere.yaml\n",
+		"pointer.yaml": "x: {$ref: 'chain3.yaml#/nope'}\n",
+		"empty.yaml":   "x: !include\n",
+		"broken.yaml":  "x: !include bad.yaml\n",
+		"bad.yaml":     "a: [\n",
+	} {
+		writeTestFile(t, filepath.Join(dir, name), content)
+	}
+	file := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	for _, name := range []string{"a.yaml", "self.yaml", "root.yaml"} {
+		if _, err := ResolveIncludes(file(name)); !errors.Is(err, ErrIncludeCycle) {
+			t.Errorf("File %s: expected cycle, got %v", name, err)
+		}
+	}
+	_, err := ResolveIncludes(file("a.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "a.yaml# -> "+file("b.yaml")+"# -> "+file("a.yaml")+"#") {
+		t.Errorf("Expected the cycle to be named, got %v", err)
+	}
+
+	if _, err := ResolveIncludesWithOptions(file("chain1.yaml"), IncludeOptions{MaxDepth: 2}); err == nil || !strings.Contains(err.Error(), "deeper than 2") {
+		t.Errorf("Expected depth error, got %v", err)
+	}
+	if _, err := ResolveIncludesWithOptions(file("chain1.yaml"), IncludeOptions{MaxDepth: 3}); err != nil {
+		t.Errorf("Unexpected error %v", err)
+	}
+
+	if _, err := ResolveIncludes(file("missing.yaml")); err == nil || !strings.Contains(err.Error(), "nowhere.yaml") {
+		t.Errorf("Expected missing file error, got %v", err)
+	}
+	if _, err := ResolveIncludes(file("pointer.yaml")); !errors.Is(err, ErrPathNotFound) {
+		t.Errorf("Expected path not found, got %v", err)
+	}
+	if _, err := ResolveIncludes(file("empty.yaml")); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("Expected empty include error, got %v", err)
+	}
+	if _, err := ResolveIncludes(file("broken.yaml")); err == nil {
+		t.Error("Expected parse error")
+	}
+	var result struct {
+		End string `yaml:"end"`
+	}
+	if err := UnmarshalFileWithIncludes(file("chain3.yaml"), &result); err != nil || result.End != "true" {
+		t.Errorf("Unexpected result %+v, %v", result, err)
+	}
+	var wrong []string
+	if err := UnmarshalFileWithIncludes(file("chain3.yaml"), &wrong); err == nil {
+		t.Error("Expected decode error")
+	}
+}
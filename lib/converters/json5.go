@@ -0,0 +1,532 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// json5ForJson makes reads of .json files and JSON content accept JSON5.
+var json5ForJson atomic.Bool
+
+// EnableJson5ForJson makes reads of JSON accept JSON5 as well: UnmarshalFile and
+// UnmarshalJsonFile for .json files, and ReadTo and Convert for the JSON format. Strict JSON
+// is valid JSON5, so existing files keep decoding as before. Writing is unaffected.
+func EnableJson5ForJson(enabled bool) {
+	json5ForJson.Store(enabled)
+}
+
+// UnmarshalJson5 decodes JSON5 content into t like UnmarshalJson, following json tags. JSON5
+// extends JSON with // and /* */ comments, trailing commas, unquoted keys, single quoted
+// strings, strings continued over lines with a backslash, hexadecimal numbers, numbers with a
+// leading plus sign or a leading or trailing decimal point, and Infinity and NaN. Infinity
+// and NaN convert to YAML with Convert, but encoding/json cannot decode them into t.
+// Returns an error, naming the line and column, if the content is not valid JSON5, or
+// FieldErrors locating a value that does not fit t.
+func UnmarshalJson5(content []byte, t interface{}) error {
+	root, err := parseJson5(content)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+	}
+	compact := bytes.Buffer{}
+	if err := writeJsonNode(&compact, root); err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+	}
+	if err := json.Unmarshal(compact.Bytes(), t); err != nil {
+		err = fmt.Errorf("error unmarshalling to %T, with error %w", t, err)
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return FieldErrors{locateJson5TypeError(root, compact.Bytes(), typeErr, err)}
+		}
+		return err
+	}
+	return nil
+}
+
+// MarshalJson5 encodes t as indented JSON, which is valid JSON5.
+func MarshalJson5(t interface{}) ([]byte, error) {
+	return marshalJson(t, true)
+}
+
+// unmarshalJsonContent decodes JSON read from a file or stream, accepting JSON5 when
+// EnableJson5ForJson is on.
+func unmarshalJsonContent(content []byte, t interface{}) error {
+	if json5ForJson.Load() {
+		return UnmarshalJson5(content, t)
+	}
+	return UnmarshalJson(content, t)
+}
+
+// locateJson5TypeError finds the JSON5 value behind a type error in the compact JSON it was
+// written as. Both trees hold the same values in the same order, so the failing value's
+// index in one is its index in the other.
+func locateJson5TypeError(root *yaml.Node, compact []byte, typeErr *json.UnmarshalTypeError, err error) *FieldError {
+	fieldErr := &FieldError{Message: strings.TrimPrefix(typeErr.Error(), "json: "), Err: err}
+	compactRoot, parseErr := jsonNode(compact)
+	if parseErr != nil {
+		return fieldErr
+	}
+	line, column := offsetPosition(compact, max(int(typeErr.Offset)-1, 0))
+	index, failing := 0, -1
+	walkValueNodes(compactRoot, "", func(node *yaml.Node, path string) {
+		if node.Line < line || node.Line == line && node.Column <= column {
+			failing = index
+		}
+		index++
+	})
+	index = 0
+	walkValueNodes(root, "", func(node *yaml.Node, path string) {
+		if index == failing {
+			fieldErr.Line, fieldErr.Column, fieldErr.Path = node.Line, node.Column, path
+		}
+		index++
+	})
+	return fieldErr
+}
+
+type json5Parser struct {
+	content   []byte
+	pos       int
+	line      int
+	lineStart int
+}
+
+// parseJson5 parses content holding a single JSON5 value into a node tree carrying the line
+// and column of every value.
+func parseJson5(content []byte) (*yaml.Node, error) {
+	p := &json5Parser{content: content, line: 1}
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	node, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.skipSpace(); err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected %s after the value", p.describe())
+	}
+	return node, nil
+}
+
+// parseJson5Documents parses a stream of JSON5 values separated by whitespace or comments.
+func parseJson5Documents(content []byte) ([]*yaml.Node, error) {
+	p := &json5Parser{content: content, line: 1}
+	documents := make([]*yaml.Node, 0)
+	for {
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.eof() {
+			return documents, nil
+		}
+		node, err := p.value()
+		if err != nil {
+			return nil, fmt.Errorf("document: [%d], error: [%v]", len(documents)+1, err)
+		}
+		documents = append(documents, node)
+	}
+}
+
+func (p *json5Parser) eof() bool {
+	return p.pos >= len(p.content)
+}
+
+func (p *json5Parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.content[p.pos]
+}
+
+func (p *json5Parser) hasPrefix(prefix string) bool {
+	return bytes.HasPrefix(p.content[p.pos:], []byte(prefix))
+}
+
+func (p *json5Parser) column() int {
+	return utf8.RuneCount(p.content[p.lineStart:p.pos]) + 1
+}
+
+func (p *json5Parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d, column %d: %s", p.line, p.column(), fmt.Sprintf(format, args...))
+}
+
+// describe names the character at the current position for error messages.
+func (p *json5Parser) describe() string {
+	if p.eof() {
+		return "end of input"
+	}
+	r, _ := utf8.DecodeRune(p.content[p.pos:])
+	return strconv.QuoteRune(r)
+}
+
+func (p *json5Parser) newline() {
+	p.pos++
+	p.line++
+	p.lineStart = p.pos
+}
+
+// skipSpace skips whitespace, including the Unicode spaces JSON5 allows, and comments.
+func (p *json5Parser) skipSpace() error {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == '\n':
+			p.newline()
+		case c == ' ' || c == '\t' || c == '\r' || c == '\v' || c == '\f':
+			p.pos++
+		case p.hasPrefix("//"):
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		case p.hasPrefix("/*"):
+			line, column := p.line, p.column()
+			p.pos += 2
+			for !p.hasPrefix("*/") {
+				if p.eof() {
+					return fmt.Errorf("line %d, column %d: unterminated comment", line, column)
+				}
+				if p.peek() == '\n' {
+					p.newline()
+				} else {
+					p.pos++
+				}
+			}
+			p.pos += 2
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRune(p.content[p.pos:])
+			if r != '\uFEFF' && r != '\u2028' && r != '\u2029' && !unicode.Is(unicode.Zs, r) {
+				return nil
+			}
+			p.pos += size
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (p *json5Parser) value() (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.ScalarNode, Line: p.line, Column: p.column()}
+	switch c := p.peek(); {
+	case p.eof():
+		return nil, p.errorf("expected a value, found end of input")
+	case c == '{':
+		return p.object(node)
+	case c == '[':
+		return p.array(node)
+	case c == '"' || c == '\'':
+		text, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		node.Tag, node.Value = "!!str", text
+	case c == '-' || c == '+' || c == '.' || c >= '0' && c <= '9' || p.hasPrefix("Infinity") || p.hasPrefix("NaN"):
+		if err := p.number(node); err != nil {
+			return nil, err
+		}
+	default:
+		switch word := p.identifier(); word {
+		case "true", "false":
+			node.Tag, node.Value = "!!bool", word
+		case "null":
+			node.Tag, node.Value = "!!null", "null"
+		default:
+			p.pos -= len(word)
+			return nil, p.errorf("unexpected %s, expected a value", p.describe())
+		}
+	}
+	return node, nil
+}
+
+func (p *json5Parser) object(node *yaml.Node) (*yaml.Node, error) {
+	node.Kind, node.Tag = yaml.MappingNode, "!!map"
+	p.pos++
+	for {
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.peek() == '}' {
+			p.pos++
+			return node, nil
+		}
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Line: p.line, Column: p.column()}
+		if c := p.peek(); c == '"' || c == '\'' {
+			text, err := p.string()
+			if err != nil {
+				return nil, err
+			}
+			key.Value = text
+		} else if key.Value = p.identifier(); key.Value == "" {
+			return nil, p.errorf("unexpected %s, expected a key", p.describe())
+		}
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.peek() != ':' {
+			return nil, p.errorf("unexpected %s, expected : after key %q", p.describe(), key.Value)
+		}
+		p.pos++
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, key, value)
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+		default:
+			return nil, p.errorf("unexpected %s, expected , or } in object", p.describe())
+		}
+	}
+}
+
+func (p *json5Parser) array(node *yaml.Node) (*yaml.Node, error) {
+	node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+	p.pos++
+	for {
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return node, nil
+		}
+		item, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, item)
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, p.errorf("unexpected %s, expected , or ] in array", p.describe())
+		}
+	}
+}
+
+// identifier reads an unquoted key or literal made of letters, digits, _ and $.
+func (p *json5Parser) identifier() string {
+	start := p.pos
+	for !p.eof() {
+		r, size := utf8.DecodeRune(p.content[p.pos:])
+		if !(r == '_' || r == '$' || unicode.IsLetter(r) || p.pos > start && unicode.IsDigit(r)) {
+			break
+		}
+		p.pos += size
+	}
+	return string(p.content[start:p.pos])
+}
+
+// number reads a number into node, written in the decimal form JSON accepts.
+func (p *json5Parser) number(node *yaml.Node) error {
+	sign := ""
+	if c := p.peek(); c == '+' || c == '-' {
+		if c == '-' {
+			sign = "-"
+		}
+		p.pos++
+	}
+	switch {
+	case p.hasPrefix("Infinity"):
+		p.pos += len("Infinity")
+		node.Tag, node.Value = "!!float", sign+".inf"
+		return nil
+	case p.hasPrefix("NaN"):
+		p.pos += len("NaN")
+		node.Tag, node.Value = "!!float", ".nan"
+		return nil
+	case p.hasPrefix("0x") || p.hasPrefix("0X"):
+		p.pos += 2
+		digits := p.digits(func(c byte) bool {
+			return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+		})
+		value, err := strconv.ParseUint(digits, 16, 64)
+		if err != nil {
+			return p.errorf("invalid hexadecimal number 0x%s", digits)
+		}
+		node.Tag, node.Value = "!!int", sign+strconv.FormatUint(value, 10)
+		return nil
+	}
+
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	integer := p.digits(isDigit)
+	fraction, hasFraction := "", p.peek() == '.'
+	if hasFraction {
+		p.pos++
+		fraction = p.digits(isDigit)
+	}
+	if integer == "" && fraction == "" || len(integer) > 1 && integer[0] == '0' {
+		return p.errorf("invalid number")
+	}
+	text := sign + integer
+	if integer == "" {
+		text += "0"
+	}
+	node.Tag = "!!int"
+	if hasFraction {
+		if fraction == "" {
+			fraction = "0"
+		}
+		text += "." + fraction
+		node.Tag = "!!float"
+	}
+	if c := p.peek(); c == 'e' || c == 'E' {
+		p.pos++
+		exponent := ""
+		if c := p.peek(); c == '+' || c == '-' {
+			exponent = string(c)
+			p.pos++
+		}
+		digits := p.digits(isDigit)
+		if digits == "" {
+			return p.errorf("invalid number exponent")
+		}
+		text += "e" + exponent + digits
+		node.Tag = "!!float"
+	}
+	node.Value = text
+	return nil
+}
+
+func (p *json5Parser) digits(accept func(c byte) bool) string {
+	start := p.pos
+	for !p.eof() && accept(p.peek()) {
+		p.pos++
+	}
+	return string(p.content[start:p.pos])
+}
+
+// string reads a single or double quoted string, resolving its escapes.
+func (p *json5Parser) string() (string, error) {
+	quote := p.peek()
+	line, column := p.line, p.column()
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' || p.peek() == '\r' {
+			return "", fmt.Errorf("line %d, column %d: unterminated string", line, column)
+		}
+		c := p.peek()
+		switch c {
+		case quote:
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *json5Parser) escape(b *strings.Builder) error {
+	p.pos++
+	if p.eof() {
+		return p.errorf("unterminated escape")
+	}
+	c := p.peek()
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 'f':
+		b.WriteByte('\f')
+	case 'n':
+		b.WriteByte('\n')
+	case 'r':
+		b.WriteByte('\r')
+	case 't':
+		b.WriteByte('\t')
+	case 'v':
+		b.WriteByte('\v')
+	case '0':
+		if next := p.pos + 1; next < len(p.content) && p.content[next] >= '0' && p.content[next] <= '9' {
+			return p.errorf("invalid escape \\0 followed by a digit")
+		}
+		b.WriteByte(0)
+	case '\n':
+		// A backslash before a line break continues the string on the next line.
+		p.newline()
+		return nil
+	case '\r':
+		p.pos++
+		if p.peek() == '\n' {
+			p.newline()
+		}
+		return nil
+	case 'x', 'u':
+		size := 2
+		if c == 'u' {
+			size = 4
+		}
+		code, err := p.hexEscape(size)
+		if err != nil {
+			return err
+		}
+		// A high surrogate followed by an escaped low surrogate forms one character.
+		if utf16High(code) && p.hasPrefix(`\u`) {
+			p.pos++
+			low, err := p.hexEscape(4)
+			if err != nil {
+				return err
+			}
+			code = (code-0xD800)<<10 + (low - 0xDC00) + 0x10000
+		}
+		b.WriteRune(rune(code))
+		return nil
+	default:
+		if c >= '1' && c <= '9' {
+			return p.errorf("invalid escape \\%c", c)
+		}
+		r, size := utf8.DecodeRune(p.content[p.pos:])
+		if r != '\u2028' && r != '\u2029' {
+			b.WriteRune(r)
+		}
+		p.pos += size
+		return nil
+	}
+	p.pos++
+	return nil
+}
+
+// hexEscape reads the size hex digits following the escape letter at the current position.
+func (p *json5Parser) hexEscape(size int) (uint32, error) {
+	if p.pos+1+size > len(p.content) {
+		return 0, p.errorf("invalid escape")
+	}
+	digits := string(p.content[p.pos+1 : p.pos+1+size])
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return 0, p.errorf("invalid escape \\%c%s", p.peek(), digits)
+	}
+	p.pos += 1 + size
+	return uint32(code), nil
+}
+
+func utf16High(code uint32) bool {
+	return code >= 0xD800 && code < 0xDC00
+}
@@ -0,0 +1,180 @@
+package converters
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const json5Example = `// service settings
+{
+  name: 'api',
+  "port": 0x1F90, /* 8080 */
+  ratio: .5,
+  scale: +2.,
+  $env: "prod\
+uction",
+  escapes: '\x41é😀 \'q\' "d"',
+  tags: ['a', 'b',],
+  nested: {on: true, off: false, none: null,},
+}
+`
+
+func TestUnmarshalJson5(t *testing.T) {
+	var doc map[string]interface{}
+	if err := UnmarshalJson5([]byte(json5Example), &doc); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"name":    "api",
+		"port":    8080.0,
+		"ratio":   0.5,
+		"scale":   2.0,
+		"$env":    "production",
+		"escapes": "Aé😀 'q' \"d\"",
+		"tags":    []interface{}{"a", "b"},
+		"nested":  map[string]interface{}{"on": true, "off": false, "none": nil},
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Errorf("Expected %v, got %v", expected, doc)
+	}
+
+	var config struct {
+		Name string `json:"name"`
+		Port int    `json:"port"`
+	}
+	if err := UnmarshalJson5([]byte("{name: 'x', port: -0x10}"), &config); err != nil || config.Name != "x" || config.Port != -16 {
+		t.Errorf("Unexpected struct %+v, %v", config, err)
+	}
+}
+
+func TestUnmarshalJson5Errors(t *testing.T) {
+	var doc interface{}
+	for content, message := range map[string]string{
+		"{a: 1,, }":          "line 1, column 7: unexpected ',', expected a key",
+		"{a 1}":              "line 1, column 4: unexpected '1', expected : after key \"a\"",
+		"[1 2]":              "line 1, column 4: unexpected '2', expected , or ] in array",
+		"{\n  a: 'x\n'}":     "line 2, column 6: unterminated string",
+		"/* open":            "line 1, column 1: unterminated comment",
+		"{a: 01}":            "invalid number",
+		"{a: 0xZ}":           "invalid hexadecimal number",
+		"{a: '\\1'}":         "invalid escape \\1",
+		"{a: undefined}":     "unexpected 'u', expected a value",
+		"{a: 1} {b: 2}":      "line 1, column 8: unexpected '{' after the value",
+		"":                   "expected a value, found end of input",
+		"{a: Infinity}":      "unsupported value",
+		"{a: 1.5e}":          "invalid number exponent",
+		"{a: '\\u12'}":       "invalid escape",
+		"{a: 1} // trailing": "",
+	} {
+		err := UnmarshalJson5([]byte(content), &doc)
+		if message == "" && err != nil || message != "" && (err == nil || !strings.Contains(err.Error(), message)) {
+			t.Errorf("Content %q: expected %q, got %v", content, message, err)
+		}
+	}
+
+	var config positionConfig
+	err := UnmarshalJson5([]byte("{\n  // comment\n  servers: [{port: 1}, {port: 'x'},],\n}"), &config)
+	var fieldErr *FieldError
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &fieldErr) || !errors.As(err, &typeErr) {
+		t.Fatalf("Expected a located json.UnmarshalTypeError, got %v", err)
+	}
+	if fieldErr.Line != 3 || fieldErr.Column != 31 || fieldErr.Path != "servers[1].port" {
+		t.Errorf("Unexpected error %+v", fieldErr)
+	}
+}
+
+func TestMarshalJson5(t *testing.T) {
+	out, err := MarshalJson5(map[string]interface{}{"b": 1, "a": []string{"x"}})
+	if err != nil || string(out) != "{\n  \"a\": [\n    \"x\"\n  ],\n  \"b\": 1\n}\n" {
+		t.Errorf("Unexpected output %q, %v", out, err)
+	}
+}
+
+func TestUnmarshalFileJson5(t *testing.T) {
+	var config positionConfig
+	file := writeTestFile(t, filepath.Join(t.TempDir(), "app.json5"), "{name: 'api', port: 0x50,}")
+	if err := UnmarshalFile(file, &config); err != nil || config.Name != "api" || config.Port != 80 {
+		t.Errorf("Unexpected config %+v, %v", config, err)
+	}
+
+	var limits struct {
+		Max float64 `json:"max"`
+	}
+	if err := UnmarshalFile(writeTestFile(t, filepath.Join(t.TempDir(), "limits.json5"), "{max: -Infinity}"), &limits); err == nil || !strings.Contains(err.Error(), "unsupported value") {
+		t.Errorf("Expected -Infinity to be rejected, got %v", err)
+	}
+
+	typed := writeTestFile(t, filepath.Join(t.TempDir(), "typed.json5"), "{\n  name: 'api',\n  port: 'eighty',\n}")
+	err := UnmarshalFile(typed, &config)
+	var fieldErr *FieldError
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &fieldErr) || !errors.As(err, &typeErr) {
+		t.Fatalf("Expected a located json.UnmarshalTypeError, got %v", err)
+	}
+	if fieldErr.File != typed || fieldErr.Line != 3 || fieldErr.Column != 9 || fieldErr.Path != "port" || fieldErr.Snippet != "3 |   port: 'eighty',\n  |         ^" {
+		t.Errorf("Unexpected error %+v\n%s", fieldErr, fieldErr.Snippet)
+	}
+
+	broken := writeTestFile(t, filepath.Join(t.TempDir(), "broken.json5"), "{\n  name: 'api'\n  port: 80\n}")
+	if err := UnmarshalFile(broken, &config); !errors.As(err, &fieldErr) || fieldErr.Line != 3 || fieldErr.Column != 3 || fieldErr.Snippet != "3 |   port: 80\n  |   ^" {
+		t.Errorf("Expected the position of the syntax error, got %v", err)
+	}
+}
+
+func TestEnableJson5ForJson(t *testing.T) {
+	defer EnableJson5ForJson(false)
+	relaxed := "{\n  // comment\n  name: 'api',\n  port: 80,\n}"
+	file := writeTestFile(t, filepath.Join(t.TempDir(), "app.json"), relaxed)
+
+	var config positionConfig
+	if err := UnmarshalFile(file, &config); err == nil {
+		t.Error("Expected strict JSON by default")
+	}
+	if err := UnmarshalJsonFile(file, &config); err == nil {
+		t.Error("Expected strict JSON by default")
+	}
+
+	EnableJson5ForJson(true)
+	for name, unmarshal := range map[string]func(string, interface{}) error{"UnmarshalFile": UnmarshalFile, "UnmarshalJsonFile": UnmarshalJsonFile} {
+		config = positionConfig{}
+		if err := unmarshal(file, &config); err != nil || config.Name != "api" || config.Port != 80 {
+			t.Errorf("%s: unexpected config %+v, %v", name, config, err)
+		}
+	}
+	config = positionConfig{}
+	if err := ReadTo(strings.NewReader(relaxed), &config, "json"); err != nil || config.Port != 80 {
+		t.Errorf("Unexpected config %+v, %v", config, err)
+	}
+	original := roundTripConfig{Name: "api", Port: 8080, DbHost: "db.internal", Ratio: 0.5}
+	written := filepath.Join(t.TempDir(), "written.json")
+	if err := MarshalFile(original, written); err != nil {
+		t.Fatal(err)
+	}
+	var decoded roundTripConfig
+	if err := UnmarshalFile(written, &decoded); err != nil || decoded != original {
+		t.Errorf("Expected the same fields with JSON5 enabled, got %+v, %v", decoded, err)
+	}
+	out, err := Convert([]byte(relaxed), "json", "json", ConvertOptions{})
+	if err != nil || string(out) != "{\"name\":\"api\",\"port\":80}\n" {
+		t.Errorf("Unexpected json %q, %v", out, err)
+	}
+}
+
+func TestConvertJson5(t *testing.T) {
+	out, err := Convert([]byte("{z: 0x10, a: 'x'} // first\n[1, 2,]"), "json5", "yaml", ConvertOptions{})
+	if err != nil || string(out) != "z: 16\na: x\n---\n- 1\n- 2\n" {
+		t.Errorf("Unexpected yaml %q, %v", out, err)
+	}
+	out, err = Convert([]byte("b: 1\na: [x]\n"), "yaml", ".json5", ConvertOptions{Pretty: true})
+	if err != nil || string(out) != "{\n  \"b\": 1,\n  \"a\": [\n    \"x\"\n  ]\n}\n" {
+		t.Errorf("Unexpected json5 %q, %v", out, err)
+	}
+	if _, err := Convert([]byte("{a: 1}\n{b: }"), "json5", "json", ConvertOptions{}); err == nil || !strings.Contains(err.Error(), "document: [2], error: [line 2, column 5") {
+		t.Errorf("Expected parse error, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package converters
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxLineSize is the largest line, in bytes, a LineDecoder accepts unless
+// LineOptions.MaxLineSize says otherwise.
+const DefaultMaxLineSize = 4 * 1024 * 1024
+
+// LineOptions controls how DecodeLinesWithOptions reads a stream.
+type LineOptions struct {
+	// StopOnError ends the stream at the first line that fails to decode.
+	// By default bad lines are reported through LineErr and skipped over.
+	StopOnError bool
+	// MaxLineSize limits the length of a single line. Zero means DefaultMaxLineSize.
+	MaxLineSize int
+}
+
+// LineError describes a line of an NDJSON stream that could not be decoded.
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line: [%d], error: [%v]", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// LineDecoder reads newline-delimited JSON (NDJSON / JSON Lines) one value at a time,
+// so arbitrarily large streams can be processed without buffering them in memory.
+//
+//	dec := converters.DecodeLines[Event](r)
+//	for dec.Next() {
+//		if err := dec.LineErr(); err != nil {
+//			continue
+//		}
+//		handle(dec.Value())
+//	}
+//	if err := dec.Err(); err != nil {
+//		return err
+//	}
+type LineDecoder[T any] struct {
+	scanner *bufio.Scanner
+	opts    LineOptions
+	line    int
+	value   T
+	lineErr error
+	err     error
+}
+
+// DecodeLines returns a LineDecoder over r using the default LineOptions.
+func DecodeLines[T any](r io.Reader) *LineDecoder[T] {
+	return DecodeLinesWithOptions[T](r, LineOptions{})
+}
+
+// DecodeLinesWithOptions returns a LineDecoder over r using the provided options.
+func DecodeLinesWithOptions[T any](r io.Reader, opts LineOptions) *LineDecoder[T] {
+	maxSize := opts.MaxLineSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxLineSize
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, min(maxSize, bufio.MaxScanTokenSize)), maxSize)
+	return &LineDecoder[T]{
+		scanner: scanner,
+		opts:    opts,
+	}
+}
+
+// Next advances to the next non-blank line and decodes it. It returns false when
+// the stream is exhausted, cannot be read, or a line failed with StopOnError set.
+// A line that fails to decode still returns true; its error is available from LineErr.
+func (d *LineDecoder[T]) Next() bool {
+	if d.err != nil {
+		return false
+	}
+	for d.scanner.Scan() {
+		d.line++
+		content := bytes.TrimSpace(d.scanner.Bytes())
+		if len(content) == 0 {
+			continue
+		}
+		var value T
+		d.value = value
+		d.lineErr = nil
+		if err := json.Unmarshal(content, &d.value); err != nil {
+			d.lineErr = &LineError{Line: d.line, Err: err}
+			if d.opts.StopOnError {
+				d.err = d.lineErr
+				return false
+			}
+		}
+		return true
+	}
+	if err := d.scanner.Err(); err != nil {
+		d.err = &LineError{Line: d.line + 1, Err: err}
+	}
+	return false
+}
+
+// Value returns the value decoded by the most recent call to Next.
+func (d *LineDecoder[T]) Value() T {
+	return d.value
+}
+
+// Line returns the 1-based line number of the most recent value.
+func (d *LineDecoder[T]) Line() int {
+	return d.line
+}
+
+// LineErr returns the decode error of the most recent line, if any.
+func (d *LineDecoder[T]) LineErr() error {
+	return d.lineErr
+}
+
+// Err returns the error that ended the stream. It is nil when the stream was read
+// to the end, even if individual lines failed to decode.
+func (d *LineDecoder[T]) Err() error {
+	return d.err
+}
+
+// NDJSONWriter encodes values as newline-delimited JSON, one compact value per line.
+type NDJSONWriter struct {
+	encoder *json.Encoder
+	count   int
+}
+
+// NewNDJSONWriter creates an NDJSONWriter writing to w.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// Write encodes item on its own line. A value that cannot be marshaled is not
+// written and its error is returned, leaving the stream usable for further values.
+func (w *NDJSONWriter) Write(item interface{}) error {
+	if err := w.encoder.Encode(item); err != nil {
+		return fmt.Errorf("error marshalling %T, with error %w", item, err)
+	}
+	w.count++
+	return nil
+}
+
+// Count returns the number of values written so far.
+func (w *NDJSONWriter) Count() int {
+	return w.count
+}
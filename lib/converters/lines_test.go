@@ -0,0 +1,150 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDecodeLines(t *testing.T) {
+	t.Run("decode every line", func(t *testing.T) {
+		input := "{\"name\": \"a\", \"value\": 1}\n\n{\"name\": \"b\", \"value\": 2}\r\n"
+		dec := DecodeLines[TestStruct](strings.NewReader(input))
+
+		var results []TestStruct
+		for dec.Next() {
+			if err := dec.LineErr(); err != nil {
+				t.Fatalf("Unexpected line error: %v", err)
+			}
+			results = append(results, dec.Value())
+		}
+		if err := dec.Err(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(results) != 2 || results[0].Name != "a" || results[1].Value != 2 {
+			t.Errorf("Expected two decoded values, got %v", results)
+		}
+		if dec.Line() != 3 {
+			t.Errorf("Expected last line 3, got %d", dec.Line())
+		}
+	})
+
+	t.Run("bad lines do not abort the stream", func(t *testing.T) {
+		input := "{\"name\": \"a\"}\n{broken\n{\"name\": \"c\"}\n"
+		dec := DecodeLines[TestStruct](strings.NewReader(input))
+
+		var names []string
+		var lineErrs []error
+		for dec.Next() {
+			if err := dec.LineErr(); err != nil {
+				lineErrs = append(lineErrs, err)
+				continue
+			}
+			names = append(names, dec.Value().Name)
+		}
+		if err := dec.Err(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(names) != 2 || names[1] != "c" {
+			t.Errorf("Expected [a c], got %v", names)
+		}
+		if len(lineErrs) != 1 {
+			t.Fatalf("Expected one line error, got %d", len(lineErrs))
+		}
+		var lineErr *LineError
+		if !errors.As(lineErrs[0], &lineErr) || lineErr.Line != 2 {
+			t.Errorf("Expected LineError on line 2, got %v", lineErrs[0])
+		}
+		var syntaxErr *json.SyntaxError
+		if !errors.As(lineErrs[0], &syntaxErr) {
+			t.Errorf("Expected wrapped json.SyntaxError, got %T", lineErr.Err)
+		}
+	})
+
+	t.Run("stop on error", func(t *testing.T) {
+		input := "{\"name\": \"a\"}\n{\"value\": \"x\"}\n{\"name\": \"c\"}\n"
+		dec := DecodeLinesWithOptions[TestStruct](strings.NewReader(input), LineOptions{StopOnError: true})
+
+		count := 0
+		for dec.Next() {
+			count++
+		}
+		if count != 1 {
+			t.Errorf("Expected 1 value before stopping, got %d", count)
+		}
+		var lineErr *LineError
+		if !errors.As(dec.Err(), &lineErr) || lineErr.Line != 2 {
+			t.Errorf("Expected LineError on line 2, got %v", dec.Err())
+		}
+		if dec.Next() {
+			t.Error("Expected Next to keep returning false after stopping")
+		}
+	})
+
+	t.Run("line too long", func(t *testing.T) {
+		input := "{\"name\": \"" + strings.Repeat("x", 100) + "\"}\n"
+		dec := DecodeLinesWithOptions[TestStruct](strings.NewReader(input), LineOptions{MaxLineSize: 16})
+		if dec.Next() {
+			t.Error("Expected no values")
+		}
+		if dec.Err() == nil {
+			t.Error("Expected error for oversized line")
+		}
+	})
+
+	t.Run("error on read failure", func(t *testing.T) {
+		dec := DecodeLines[TestStruct](&errorReader{})
+		if dec.Next() {
+			t.Error("Expected no values")
+		}
+		if !errors.Is(dec.Err(), os.ErrInvalid) {
+			t.Errorf("Expected read error, got %v", dec.Err())
+		}
+	})
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	t.Run("write one value per line", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewNDJSONWriter(&buf)
+		for _, item := range []TestStruct{{Name: "a", Value: 1}, {Name: "b", Value: 2}} {
+			if err := w.Write(item); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		expected := "{\"name\":\"a\",\"value\":1}\n{\"name\":\"b\",\"value\":2}\n"
+		if buf.String() != expected {
+			t.Errorf("Expected %q, got %q", expected, buf.String())
+		}
+		if w.Count() != 2 {
+			t.Errorf("Expected count 2, got %d", w.Count())
+		}
+	})
+
+	t.Run("marshal failure does not corrupt the stream", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewNDJSONWriter(&buf)
+		if err := w.Write(errorMarshaler{}); err == nil {
+			t.Error("Expected error for marshal failure")
+		}
+		if err := w.Write(TestStruct{Name: "ok"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if buf.String() != "{\"name\":\"ok\",\"value\":0}\n" || w.Count() != 1 {
+			t.Errorf("Unexpected stream contents %q", buf.String())
+		}
+	})
+
+	t.Run("round trip through DecodeLines", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := NewNDJSONWriter(&buf)
+		_ = w.Write(map[string]interface{}{"name": "multi\nline"})
+		dec := DecodeLines[map[string]interface{}](&buf)
+		if !dec.Next() || dec.Value()["name"] != "multi\nline" {
+			t.Errorf("Expected embedded newline to round trip, got %v", dec.Value())
+		}
+	})
+}
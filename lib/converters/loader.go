@@ -0,0 +1,268 @@
+package converters
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source labels reported by Loader for values that do not come from a file.
+const (
+	SourceEnv      = "env"
+	SourceOverride = "override"
+)
+
+// Sources maps the dotted path of every final configuration value to the source
+// that supplied it: a file path, "env:<VARIABLE>" or "override".
+type Sources map[string]string
+
+// Loader merges configuration from several layers before decoding it into a struct.
+// Layers are applied in the following order, later layers taking precedence:
+//
+//  1. BaseFile
+//  2. the environment overlay, BaseFile with Environment inserted before the extension
+//     (config.yaml becomes config.prod.yaml), if it exists
+//  3. LocalFile, if set and it exists
+//  4. environment variables starting with EnvPrefix, if set
+//  5. Overrides
+//
+// Maps are merged key by key; scalars and lists replace whatever was there before.
+type Loader struct {
+	// BaseFile is the required base configuration file in any format UnmarshalFile reads.
+	BaseFile string
+	// Environment names the overlay to apply, such as "prod". Empty skips the overlay.
+	Environment string
+	// LocalFile is an optional, usually uncommitted, override file.
+	LocalFile string
+	// EnvPrefix selects environment variables to apply, such as "APP_". After the prefix,
+	// "__" separates nesting levels, so APP_DB__HOST sets db.host. Values are parsed as
+	// YAML scalars, so numbers and booleans keep their types.
+	EnvPrefix string
+	// Overrides are applied last and keyed by dotted path, such as "db.port".
+	Overrides map[string]interface{}
+}
+
+// Load merges all layers, decodes the result into t and reports which source supplied
+// each final value.
+// Returns an error if the base file or any existing layer cannot be read, or if the
+// merged configuration cannot be decoded into t.
+func (l Loader) Load(t interface{}) (Sources, error) {
+	tree, sources, err := l.Merge()
+	if err != nil {
+		return nil, err
+	}
+	content, err := yaml.Marshal(tree)
+	if err != nil {
+		return nil, err
+	}
+	if err := UnmarshalYaml(content, t); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+// Merge applies all layers and returns the merged configuration tree without decoding it.
+func (l Loader) Merge() (map[string]interface{}, Sources, error) {
+	layers := make([]configLayer, 0)
+
+	base, err := readConfigLayer(l.BaseFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	layers = append(layers, base)
+
+	if l.Environment != "" {
+		ext := filepath.Ext(l.BaseFile)
+		overlay := strings.TrimSuffix(l.BaseFile, ext) + "." + l.Environment + ext
+		if layer, err := readOptionalConfigLayer(overlay); err != nil {
+			return nil, nil, err
+		} else if layer != nil {
+			layers = append(layers, *layer)
+		}
+	}
+
+	if l.LocalFile != "" {
+		if layer, err := readOptionalConfigLayer(l.LocalFile); err != nil {
+			return nil, nil, err
+		} else if layer != nil {
+			layers = append(layers, *layer)
+		}
+	}
+
+	if l.EnvPrefix != "" {
+		layers = append(layers, envConfigLayers(l.EnvPrefix)...)
+	}
+
+	if len(l.Overrides) > 0 {
+		overrides := configLayer{source: SourceOverride, values: make(map[string]interface{})}
+		paths := make([]string, 0, len(l.Overrides))
+		for path := range l.Overrides {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			setPath(overrides.values, strings.Split(path, "."), l.Overrides[path])
+		}
+		layers = append(layers, overrides)
+	}
+
+	merged := make(map[string]interface{})
+	for _, layer := range layers {
+		mergeTree(merged, layer.values)
+	}
+
+	layerPaths := make([]map[string]struct{}, len(layers))
+	for i, layer := range layers {
+		layerPaths[i] = make(map[string]struct{})
+		for path := range leafPaths(layer.values, "") {
+			layerPaths[i][strings.ToLower(path)] = struct{}{}
+		}
+	}
+	sources := make(Sources)
+	for path := range leafPaths(merged, "") {
+		for i := len(layers) - 1; i >= 0; i-- {
+			if _, ok := layerPaths[i][strings.ToLower(path)]; ok {
+				sources[path] = layers[i].source
+				break
+			}
+		}
+	}
+	return merged, sources, nil
+}
+
+// configLayer is one source of configuration values.
+type configLayer struct {
+	source string
+	values map[string]interface{}
+}
+
+// readConfigLayer reads a configuration file into a layer.
+func readConfigLayer(file string) (configLayer, error) {
+	values := make(map[string]interface{})
+	if err := UnmarshalFile(file, &values); err != nil {
+		return configLayer{}, err
+	}
+	return configLayer{source: file, values: values}, nil
+}
+
+// readOptionalConfigLayer reads a configuration file into a layer, returning nil
+// if the file does not exist.
+func readOptionalConfigLayer(file string) (*configLayer, error) {
+	if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	layer, err := readConfigLayer(file)
+	if err != nil {
+		return nil, err
+	}
+	return &layer, nil
+}
+
+// envConfigLayers turns environment variables starting with prefix into one layer
+// per variable, so each value can be traced back to the variable that set it.
+func envConfigLayers(prefix string) []configLayer {
+	names := make([]string, 0)
+	values := make(map[string]string)
+	for _, entry := range os.Environ() {
+		name, value, found := strings.Cut(entry, "=")
+		if !found || !strings.HasPrefix(name, prefix) || name == prefix {
+			continue
+		}
+		names = append(names, name)
+		values[name] = value
+	}
+	sort.Strings(names)
+
+	layers := make([]configLayer, 0, len(names))
+	for _, name := range names {
+		keys := strings.Split(strings.ToLower(strings.TrimPrefix(name, prefix)), "__")
+		layer := configLayer{source: SourceEnv + ":" + name, values: make(map[string]interface{})}
+		setPath(layer.values, keys, parseScalar(values[name]))
+		layers = append(layers, layer)
+	}
+	return layers
+}
+
+// parseScalar interprets a string the way a YAML scalar would be, falling back to the
+// raw string when it does not parse.
+func parseScalar(value string) interface{} {
+	var parsed interface{}
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+		return value
+	}
+	switch parsed.(type) {
+	case map[string]interface{}, []interface{}, nil:
+		return value
+	}
+	return parsed
+}
+
+// setPath stores value in tree under the nested keys, creating intermediate maps.
+func setPath(tree map[string]interface{}, keys []string, value interface{}) {
+	for _, key := range keys[:len(keys)-1] {
+		child, ok := tree[key].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			tree[key] = child
+		}
+		tree = child
+	}
+	tree[keys[len(keys)-1]] = value
+}
+
+// mergeTree merges src into dest, recursing into maps present on both sides.
+// Keys are matched case-insensitively when no exact match exists, so environment
+// variables can address camelCase keys.
+func mergeTree(dest map[string]interface{}, src map[string]interface{}) {
+	for k, v := range src {
+		key := matchKey(dest, k)
+		srcMap, srcIsMap := v.(map[string]interface{})
+		destMap, destIsMap := dest[key].(map[string]interface{})
+		if srcIsMap && destIsMap {
+			mergeTree(destMap, srcMap)
+			continue
+		}
+		if srcIsMap {
+			copied := make(map[string]interface{})
+			mergeTree(copied, srcMap)
+			v = copied
+		}
+		dest[key] = v
+	}
+}
+
+// matchKey returns the key in tree equal to k, or equal ignoring case, or k itself.
+func matchKey(tree map[string]interface{}, k string) string {
+	if _, ok := tree[k]; ok {
+		return k
+	}
+	for existing := range tree {
+		if strings.EqualFold(existing, k) {
+			return existing
+		}
+	}
+	return k
+}
+
+// leafPaths returns the dotted paths of all values in tree that are not non-empty maps.
+func leafPaths(tree map[string]interface{}, prefix string) map[string]struct{} {
+	paths := make(map[string]struct{})
+	for k, v := range tree {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if child, ok := v.(map[string]interface{}); ok && len(child) > 0 {
+			for p := range leafPaths(child, path) {
+				paths[p] = struct{}{}
+			}
+			continue
+		}
+		paths[path] = struct{}{}
+	}
+	return paths
+}
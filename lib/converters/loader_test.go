@@ -0,0 +1,134 @@
+package converters
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+type loaderConfig struct {
+	Name string `yaml:"name"`
+	DB   struct {
+		Host     string `yaml:"host"`
+		Port     int    `yaml:"port"`
+		MaxConns int    `yaml:"maxConns"`
+		TLS      bool   `yaml:"tls"`
+	} `yaml:"db"`
+	Tags []string `yaml:"tags"`
+}
+
+func TestLoader(t *testing.T) {
+	t.Run("layers apply in precedence order", func(t *testing.T) {
+		dir := t.TempDir()
+		base := writeTestFile(t, filepath.Join(dir, "config.yaml"), "name: app\ndb:\n  host: localhost\n  port: 5432\n  maxConns: 5\ntags: [a, b]\n")
+		prod := writeTestFile(t, filepath.Join(dir, "config.prod.yaml"), "db:\n  host: prod-db\n  maxConns: 50\n")
+		local := writeTestFile(t, filepath.Join(dir, "config.local.json"), `{"tags": ["local"]}`)
+		t.Setenv("APP_DB__MAXCONNS", "100")
+		t.Setenv("APP_DB__TLS", "true")
+
+		loader := Loader{
+			BaseFile:    base,
+			Environment: "prod",
+			LocalFile:   local,
+			EnvPrefix:   "APP_",
+			Overrides:   map[string]interface{}{"db.port": 6543},
+		}
+		var cfg loaderConfig
+		sources, err := loader.Load(&cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if cfg.Name != "app" || cfg.DB.Host != "prod-db" || cfg.DB.Port != 6543 ||
+			cfg.DB.MaxConns != 100 || !cfg.DB.TLS || len(cfg.Tags) != 1 || cfg.Tags[0] != "local" {
+			t.Errorf("Unexpected config %+v", cfg)
+		}
+
+		expected := Sources{
+			"name":        base,
+			"db.host":     prod,
+			"db.port":     SourceOverride,
+			"db.maxConns": "env:APP_DB__MAXCONNS",
+			"db.tls":      "env:APP_DB__TLS",
+			"tags":        local,
+		}
+		if len(sources) != len(expected) {
+			t.Errorf("Expected %d sources, got %v", len(expected), sources)
+		}
+		for path, source := range expected {
+			if sources[path] != source {
+				t.Errorf("Expected %s from %s, got %s", path, source, sources[path])
+			}
+		}
+	})
+
+	t.Run("missing optional layers are skipped", func(t *testing.T) {
+		dir := t.TempDir()
+		base := writeTestFile(t, filepath.Join(dir, "config.yaml"), "name: app\n")
+		loader := Loader{
+			BaseFile:    base,
+			Environment: "staging",
+			LocalFile:   filepath.Join(dir, "config.local.yaml"),
+		}
+		var cfg loaderConfig
+		sources, err := loader.Load(&cfg)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.Name != "app" || sources["name"] != base {
+			t.Errorf("Unexpected result %+v %v", cfg, sources)
+		}
+	})
+
+	t.Run("env values that do not parse stay strings", func(t *testing.T) {
+		dir := t.TempDir()
+		base := writeTestFile(t, filepath.Join(dir, "config.yaml"), "name: app\n")
+		t.Setenv("SVC_NAME", "[not: yaml")
+		tree, _, err := Loader{BaseFile: base, EnvPrefix: "SVC_"}.Merge()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if tree["name"] != "[not: yaml" {
+			t.Errorf("Expected raw string, got %v", tree["name"])
+		}
+	})
+
+	t.Run("error on missing base file", func(t *testing.T) {
+		var cfg loaderConfig
+		_, err := Loader{BaseFile: "nonexistent.yaml"}.Load(&cfg)
+		if err == nil {
+			t.Error("Expected error for missing base file")
+		}
+	})
+
+	t.Run("error on invalid overlay", func(t *testing.T) {
+		dir := t.TempDir()
+		base := writeTestFile(t, filepath.Join(dir, "config.yaml"), "name: app\n")
+		writeTestFile(t, filepath.Join(dir, "config.dev.yaml"), "name: [unclosed\n")
+		var cfg loaderConfig
+		_, err := Loader{BaseFile: base, Environment: "dev"}.Load(&cfg)
+		if err == nil {
+			t.Error("Expected error for invalid overlay")
+		}
+	})
+
+	t.Run("error on invalid local file", func(t *testing.T) {
+		dir := t.TempDir()
+		base := writeTestFile(t, filepath.Join(dir, "config.yaml"), "name: app\n")
+		local := writeTestFile(t, filepath.Join(dir, "config.local.yaml"), "name: [unclosed\n")
+		var cfg loaderConfig
+		_, err := Loader{BaseFile: base, LocalFile: local}.Load(&cfg)
+		if err == nil {
+			t.Error("Expected error for invalid local file")
+		}
+	})
+
+	t.Run("error on decode failure", func(t *testing.T) {
+		dir := t.TempDir()
+		base := writeTestFile(t, filepath.Join(dir, "config.yaml"), "db:\n  port: 5432\n")
+		var cfg loaderConfig
+		_, err := Loader{BaseFile: base, Overrides: map[string]interface{}{"db.port": "high"}}.Load(&cfg)
+		if err == nil {
+			t.Error("Expected error for decode failure")
+		}
+	})
+}
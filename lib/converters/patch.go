@@ -0,0 +1,478 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/skhatri/go-fns/lib/collections"
+	"gopkg.in/yaml.v3"
+)
+
+// JSON Patch operation names (RFC 6902).
+const (
+	PatchAdd     = "add"
+	PatchRemove  = "remove"
+	PatchReplace = "replace"
+	PatchMove    = "move"
+	PatchCopy    = "copy"
+	PatchTest    = "test"
+)
+
+// PatchOperation is a single JSON Patch (RFC 6902) operation. Path and From are JSON Pointers.
+type PatchOperation struct {
+	Op    string      `json:"op" yaml:"op"`
+	Path  string      `json:"path" yaml:"path"`
+	From  string      `json:"from,omitempty" yaml:"from,omitempty"`
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// MarshalJSON writes the value member for add, replace and test even when it is null.
+func (op PatchOperation) MarshalJSON() ([]byte, error) {
+	type plain PatchOperation
+	if op.Op != PatchAdd && op.Op != PatchReplace && op.Op != PatchTest {
+		return json.Marshal(plain(op))
+	}
+	return json.Marshal(struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}{op.Op, op.Path, op.Value})
+}
+
+// JSONPatch is an ordered list of JSON Patch operations.
+type JSONPatch []PatchOperation
+
+// ApplyJSONPatch applies patch to a generic document tree, such as the result of
+// UnmarshalYaml into an interface{} or collections.MapByStringKey, and returns the patched
+// tree. The input is not modified. Operations are applied in order and the first failure,
+// including a failed test operation, aborts the patch.
+func ApplyJSONPatch(doc interface{}, patch JSONPatch) (interface{}, error) {
+	result := collections.MapByStringKey(doc)
+	for i, op := range patch {
+		var err error
+		result, err = applyPatchOperation(result, op)
+		if err != nil {
+			return nil, fmt.Errorf("operation: [%d %s %s], error: [%v]", i, op.Op, op.Path, err)
+		}
+	}
+	return result, nil
+}
+
+// ApplyJSONPatchBytes applies a JSON encoded patch to a JSON document and returns the
+// patched document as compact JSON. Numbers are preserved exactly.
+func ApplyJSONPatchBytes(doc []byte, patch []byte) ([]byte, error) {
+	var tree interface{}
+	if err := decodeJsonNumbers(doc, &tree); err != nil {
+		return nil, err
+	}
+	var ops JSONPatch
+	if err := decodeJsonNumbers(patch, &ops); err != nil {
+		return nil, err
+	}
+	result, err := ApplyJSONPatch(tree, ops)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// ApplyJSONPatchYaml applies a patch to a YAML document, both read with the YAML codec,
+// and returns the patched document as YAML. As YAML is a superset of JSON, either input
+// may also be JSON.
+func ApplyJSONPatchYaml(doc []byte, patch []byte) ([]byte, error) {
+	var tree interface{}
+	if err := UnmarshalYaml(doc, &tree); err != nil {
+		return nil, err
+	}
+	var ops JSONPatch
+	if err := UnmarshalYaml(patch, &ops); err != nil {
+		return nil, err
+	}
+	result, err := ApplyJSONPatch(tree, ops)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(result)
+}
+
+// CreateJSONPatch returns the operations that turn original into modified. Objects are
+// compared key by key and arrays index by index, with trailing items added or removed.
+func CreateJSONPatch(original interface{}, modified interface{}) JSONPatch {
+	patch := make(JSONPatch, 0)
+	diffJSONPatch(collections.MapByStringKey(original), collections.MapByStringKey(modified), "", &patch)
+	return patch
+}
+
+// CreateJSONPatchBytes compares two JSON documents and returns the JSON Patch between them.
+func CreateJSONPatchBytes(original []byte, modified []byte) ([]byte, error) {
+	var a, b interface{}
+	if err := decodeJsonNumbers(original, &a); err != nil {
+		return nil, err
+	}
+	if err := decodeJsonNumbers(modified, &b); err != nil {
+		return nil, err
+	}
+	return json.Marshal(CreateJSONPatch(a, b))
+}
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7386) to a generic document tree and
+// returns the result. Objects in the patch are merged recursively, null removes a key and
+// any other value replaces the target. The input is not modified.
+func ApplyMergePatch(doc interface{}, patch interface{}) interface{} {
+	return mergePatch(collections.MapByStringKey(doc), collections.MapByStringKey(patch))
+}
+
+// ApplyMergePatchBytes applies a JSON encoded merge patch to a JSON document and returns
+// the result as compact JSON.
+func ApplyMergePatchBytes(doc []byte, patch []byte) ([]byte, error) {
+	var tree, changes interface{}
+	if err := decodeJsonNumbers(doc, &tree); err != nil {
+		return nil, err
+	}
+	if err := decodeJsonNumbers(patch, &changes); err != nil {
+		return nil, err
+	}
+	return json.Marshal(ApplyMergePatch(tree, changes))
+}
+
+// ApplyMergePatchYaml applies a merge patch to a YAML document, both read with the YAML
+// codec, and returns the result as YAML.
+func ApplyMergePatchYaml(doc []byte, patch []byte) ([]byte, error) {
+	var tree, changes interface{}
+	if err := UnmarshalYaml(doc, &tree); err != nil {
+		return nil, err
+	}
+	if err := UnmarshalYaml(patch, &changes); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(ApplyMergePatch(tree, changes))
+}
+
+// CreateMergePatch returns the merge patch that turns original into modified. Merge
+// patches cannot set a value to null, so such changes are expressed as removals.
+func CreateMergePatch(original interface{}, modified interface{}) interface{} {
+	return diffMergePatch(collections.MapByStringKey(original), collections.MapByStringKey(modified))
+}
+
+// CreateMergePatchBytes compares two JSON documents and returns the merge patch between them.
+func CreateMergePatchBytes(original []byte, modified []byte) ([]byte, error) {
+	var a, b interface{}
+	if err := decodeJsonNumbers(original, &a); err != nil {
+		return nil, err
+	}
+	if err := decodeJsonNumbers(modified, &b); err != nil {
+		return nil, err
+	}
+	return json.Marshal(CreateMergePatch(a, b))
+}
+
+// decodeJsonNumbers unmarshals JSON keeping numbers as json.Number so they survive a round trip.
+func decodeJsonNumbers(content []byte, t interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err := decoder.Decode(t); err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+	}
+	return nil
+}
+
+func applyPatchOperation(doc interface{}, op PatchOperation) (interface{}, error) {
+	switch op.Op {
+	case PatchAdd:
+		return addAtPointer(doc, op.Path, collections.MapByStringKey(op.Value))
+	case PatchRemove:
+		result, _, err := removeAtPointer(doc, op.Path)
+		return result, err
+	case PatchReplace:
+		if _, err := getAtPointer(doc, op.Path); err != nil {
+			return nil, err
+		}
+		result, _, err := removeAtPointer(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		return addAtPointer(result, op.Path, collections.MapByStringKey(op.Value))
+	case PatchMove:
+		if op.From == op.Path {
+			return doc, nil
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("cannot move %q into one of its children", op.From)
+		}
+		result, value, err := removeAtPointer(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return addAtPointer(result, op.Path, value)
+	case PatchCopy:
+		value, err := getAtPointer(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return addAtPointer(doc, op.Path, collections.MapByStringKey(value))
+	case PatchTest:
+		value, err := getAtPointer(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !schemaEqual(value, collections.MapByStringKey(op.Value)) {
+			return nil, fmt.Errorf("test failed, expected %v, found %v", op.Value, value)
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// parsePointer splits a JSON Pointer into unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON Pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = unescapePointer(token)
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses an array reference token. With allowEnd the index may equal the length,
+// and "-" addresses the position after the last item.
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if index > length || (index == length && !allowEnd) {
+		return 0, fmt.Errorf("array index %d out of range", index)
+	}
+	return index, nil
+}
+
+func getAtPointer(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	current := doc
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", pointer)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("path %q not found", pointer)
+		}
+	}
+	return current, nil
+}
+
+// updateParent walks to the container holding the last token of pointer, lets update
+// change it and stores the possibly reallocated container back into the document.
+func updateParent(doc interface{}, tokens []string, pointer string, update func(container interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(tokens) == 1 {
+		return update(doc, tokens[0])
+	}
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[tokens[0]]
+		if !ok {
+			return nil, fmt.Errorf("path %q not found", pointer)
+		}
+		updated, err := updateParent(child, tokens[1:], pointer, update)
+		if err != nil {
+			return nil, err
+		}
+		node[tokens[0]] = updated
+		return node, nil
+	case []interface{}:
+		index, err := arrayIndex(tokens[0], len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := updateParent(node[index], tokens[1:], pointer, update)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = updated
+		return node, nil
+	}
+	return nil, fmt.Errorf("path %q not found", pointer)
+}
+
+func addAtPointer(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return updateParent(doc, tokens, pointer, func(container interface{}, token string) (interface{}, error) {
+		switch node := container.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), true)
+			if err != nil {
+				return nil, err
+			}
+			node = append(node, nil)
+			copy(node[index+1:], node[index:])
+			node[index] = value
+			return node, nil
+		}
+		return nil, fmt.Errorf("path %q not found", pointer)
+	})
+}
+
+func removeAtPointer(doc interface{}, pointer string) (interface{}, interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, doc, nil
+	}
+	var removed interface{}
+	result, err := updateParent(doc, tokens, pointer, func(container interface{}, token string) (interface{}, error) {
+		switch node := container.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path %q not found", pointer)
+			}
+			removed = value
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = node[index]
+			return append(node[:index], node[index+1:]...), nil
+		}
+		return nil, fmt.Errorf("path %q not found", pointer)
+	})
+	return result, removed, err
+}
+
+func diffJSONPatch(a interface{}, b interface{}, pointer string, patch *JSONPatch) {
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, key := range sortedKeys(x) {
+			if _, present := y[key]; !present {
+				*patch = append(*patch, PatchOperation{Op: PatchRemove, Path: pointer + "/" + escapePointer(key)})
+			}
+		}
+		for _, key := range sortedKeys(y) {
+			child := pointer + "/" + escapePointer(key)
+			if value, present := x[key]; present {
+				diffJSONPatch(value, y[key], child, patch)
+			} else {
+				*patch = append(*patch, PatchOperation{Op: PatchAdd, Path: child, Value: y[key]})
+			}
+		}
+		return
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		common := min(len(x), len(y))
+		for i := 0; i < common; i++ {
+			diffJSONPatch(x[i], y[i], pointer+"/"+strconv.Itoa(i), patch)
+		}
+		for i := len(x) - 1; i >= common; i-- {
+			*patch = append(*patch, PatchOperation{Op: PatchRemove, Path: pointer + "/" + strconv.Itoa(i)})
+		}
+		for i := common; i < len(y); i++ {
+			*patch = append(*patch, PatchOperation{Op: PatchAdd, Path: pointer + "/" + strconv.Itoa(i), Value: y[i]})
+		}
+		return
+	}
+	if !schemaEqual(a, b) {
+		*patch = append(*patch, PatchOperation{Op: PatchReplace, Path: pointer, Value: b})
+	}
+}
+
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	changes, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	result, ok := target.(map[string]interface{})
+	if !ok {
+		result = make(map[string]interface{})
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = mergePatch(result[key], value)
+	}
+	return result
+}
+
+func diffMergePatch(a interface{}, b interface{}) interface{} {
+	x, xIsMap := a.(map[string]interface{})
+	y, yIsMap := b.(map[string]interface{})
+	if !xIsMap || !yIsMap {
+		return b
+	}
+	patch := make(map[string]interface{})
+	for key, value := range x {
+		if _, present := y[key]; !present {
+			patch[key] = nil
+			continue
+		}
+		if schemaEqual(value, y[key]) {
+			continue
+		}
+		if y[key] == nil {
+			patch[key] = nil
+			continue
+		}
+		patch[key] = diffMergePatch(value, y[key])
+	}
+	for key, value := range y {
+		if _, present := x[key]; !present && value != nil {
+			patch[key] = value
+		}
+	}
+	return patch
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
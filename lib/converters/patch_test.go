@@ -0,0 +1,260 @@
+package converters
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyJSONPatchBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+		wantErr  bool
+	}{
+		{"add object member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`, false},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`, false},
+		{"append to array", `{"foo":[1]}`, `[{"op":"add","path":"/foo/-","value":2}]`, `{"foo":[1,2]}`, false},
+		{"add null value", `{}`, `[{"op":"add","path":"/a","value":null}]`, `{"a":null}`, false},
+		{"remove object member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`, false},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`, false},
+		{"replace value", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`, false},
+		{"replace root", `{"a":1}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`, false},
+		{"move value", `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`, false},
+		{"move array element", `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`, false},
+		{"copy value", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`, false},
+		{"test success", `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`, false},
+		{"escaped pointer", `{"a/b":{"m~n":1}}`, `[{"op":"replace","path":"/a~1b/m~0n","value":2}]`, `{"a/b":{"m~n":2}}`, false},
+		{"large numbers are preserved", `{"id":12345678901234567890}`, `[{"op":"add","path":"/n","value":1.50}]`, `{"id":12345678901234567890,"n":1.50}`, false},
+		{"test failure", `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`, "", true},
+		{"remove missing", `{"a":1}`, `[{"op":"remove","path":"/b"}]`, "", true},
+		{"replace missing", `{"a":1}`, `[{"op":"replace","path":"/b","value":1}]`, "", true},
+		{"add to missing parent", `{"a":1}`, `[{"op":"add","path":"/b/c","value":1}]`, "", true},
+		{"index out of range", `{"a":[1]}`, `[{"op":"add","path":"/a/5","value":1}]`, "", true},
+		{"leading zero index", `{"a":[1,2]}`, `[{"op":"remove","path":"/a/01"}]`, "", true},
+		{"move into child", `{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/c"}]`, "", true},
+		{"copy missing", `{"a":1}`, `[{"op":"copy","from":"/x","path":"/b"}]`, "", true},
+		{"invalid pointer", `{"a":1}`, `[{"op":"add","path":"a","value":1}]`, "", true},
+		{"unknown operation", `{"a":1}`, `[{"op":"frob","path":"/a"}]`, "", true},
+		{"invalid document", `{`, `[]`, "", true},
+		{"invalid patch", `{}`, `{}`, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ApplyJSONPatchBytes([]byte(tt.doc), []byte(tt.patch))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyJSONPatchBytes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && string(result) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	t.Run("input is not modified", func(t *testing.T) {
+		doc := map[string]interface{}{"a": map[string]interface{}{"b": 1}, "list": []interface{}{1, 2}}
+		patch := JSONPatch{
+			{Op: PatchReplace, Path: "/a/b", Value: 2},
+			{Op: PatchRemove, Path: "/list/0"},
+		}
+		result, err := ApplyJSONPatch(doc, patch)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if doc["a"].(map[string]interface{})["b"] != 1 || len(doc["list"].([]interface{})) != 2 {
+			t.Errorf("Input was modified: %v", doc)
+		}
+		expected := map[string]interface{}{"a": map[string]interface{}{"b": 2}, "list": []interface{}{2}}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Expected %v, got %v", expected, result)
+		}
+	})
+
+	t.Run("error names the operation", func(t *testing.T) {
+		_, err := ApplyJSONPatch(map[string]interface{}{}, JSONPatch{{Op: PatchAdd, Path: "/a", Value: 1}, {Op: PatchRemove, Path: "/b"}})
+		if err == nil || !strings.Contains(err.Error(), "operation: [1 remove /b]") {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+}
+
+func TestApplyJSONPatchYaml(t *testing.T) {
+	doc := []byte("# comment\nimage:\n  tag: v1\nports: [80]\n")
+	patch := []byte("- op: replace\n  path: /image/tag\n  value: v2\n- op: add\n  path: /ports/-\n  value: 443\n")
+	result, err := ApplyJSONPatchYaml(doc, patch)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var out map[string]interface{}
+	if err := UnmarshalYaml(result, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out["image"].(map[string]interface{})["tag"] != "v2" || len(out["ports"].([]interface{})) != 2 {
+		t.Errorf("Unexpected result %s", result)
+	}
+
+	if _, err := ApplyJSONPatchYaml([]byte("a: [unclosed"), patch); err == nil {
+		t.Error("Expected error for invalid document")
+	}
+	if _, err := ApplyJSONPatchYaml(doc, []byte("op: add")); err == nil {
+		t.Error("Expected error for invalid patch")
+	}
+	if _, err := ApplyJSONPatchYaml(doc, []byte("- {op: remove, path: /missing}")); err == nil {
+		t.Error("Expected error for failing patch")
+	}
+}
+
+func TestCreateJSONPatch(t *testing.T) {
+	original := []byte(`{"name":"a","removed":true,"nested":{"x":1,"y":2},"list":[1,2,3],"short":[1],"kind":{"a":1}}`)
+	modified := []byte(`{"name":"b","added":null,"nested":{"x":1,"y":3},"list":[1],"short":[1,2],"kind":"flat"}`)
+
+	patch, err := CreateJSONPatchBytes(original, modified)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `[{"op":"remove","path":"/removed"},{"op":"add","path":"/added","value":null},` +
+		`{"op":"replace","path":"/kind","value":"flat"},{"op":"remove","path":"/list/2"},{"op":"remove","path":"/list/1"},` +
+		`{"op":"replace","path":"/name","value":"b"},{"op":"replace","path":"/nested/y","value":3},` +
+		`{"op":"add","path":"/short/1","value":2}]`
+	if string(patch) != expected {
+		t.Errorf("Expected %s\ngot      %s", expected, patch)
+	}
+
+	applied, err := ApplyJSONPatchBytes(original, patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a, b interface{}
+	_ = json.Unmarshal(applied, &a)
+	_ = json.Unmarshal(modified, &b)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Round trip produced %s", applied)
+	}
+
+	if _, err := CreateJSONPatchBytes([]byte("{"), modified); err == nil {
+		t.Error("Expected error for invalid original")
+	}
+	if _, err := CreateJSONPatchBytes(original, []byte("{")); err == nil {
+		t.Error("Expected error for invalid modified")
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name, doc, patch, expected string
+	}{
+		{"replace value", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add value", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"remove value", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"replace array", `{"a":["b"]}`, `{"a":["c","d"]}`, `{"a":["c","d"]}`},
+		{"replace object with scalar", `{"a":{"b":"c"}}`, `{"a":"d"}`, `{"a":"d"}`},
+		{"nested merge", `{"a":{"b":"c","d":"e"}}`, `{"a":{"d":null,"f":"g"}}`, `{"a":{"b":"c","f":"g"}}`},
+		{"scalar document", `["a"]`, `{"a":{"b":null}}`, `{"a":{}}`},
+		{"non-object patch", `{"a":"b"}`, `["c"]`, `["c"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ApplyMergePatchBytes([]byte(tt.doc), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		if _, err := ApplyMergePatchBytes([]byte("{"), []byte("{}")); err == nil {
+			t.Error("Expected error for invalid document")
+		}
+		if _, err := ApplyMergePatchBytes([]byte("{}"), []byte("{")); err == nil {
+			t.Error("Expected error for invalid patch")
+		}
+	})
+}
+
+func TestApplyMergePatchYaml(t *testing.T) {
+	result, err := ApplyMergePatchYaml([]byte("image:\n  name: api\n  tag: v1\ndebug: true\n"), []byte("image: {tag: v2}\ndebug: null\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(result) != "image:\n    name: api\n    tag: v2\n" {
+		t.Errorf("Unexpected result %q", result)
+	}
+	if _, err := ApplyMergePatchYaml([]byte("a: [unclosed"), []byte("{}")); err == nil {
+		t.Error("Expected error for invalid document")
+	}
+	if _, err := ApplyMergePatchYaml([]byte("{}"), []byte("a: [unclosed")); err == nil {
+		t.Error("Expected error for invalid patch")
+	}
+}
+
+func TestPatchNonStringKeys(t *testing.T) {
+	doc := []byte("1: a\nb: c\ntrue: {2: x}\n")
+	result, err := ApplyJSONPatchYaml(doc, []byte("- {op: replace, path: /1, value: z}\n- {op: add, path: /true/3, value: y}\n"))
+	if err != nil || string(result) != "\"1\": z\nb: c\n\"true\":\n    \"2\": x\n    \"3\": \"y\"\n" {
+		t.Errorf("Unexpected result %q, %v", result, err)
+	}
+	result, err = ApplyMergePatchYaml(doc, []byte("1: null\n2: d\n"))
+	if err != nil || string(result) != "\"2\": d\nb: c\n\"true\":\n    \"2\": x\n" {
+		t.Errorf("Unexpected result %q, %v", result, err)
+	}
+
+	var original, modified interface{}
+	_ = UnmarshalYaml(doc, &original)
+	_ = UnmarshalYaml([]byte("1: a\nb: d\n"), &modified)
+	patch := CreateJSONPatch(original, modified)
+	if !reflect.DeepEqual(patch, JSONPatch{{Op: PatchRemove, Path: "/true"}, {Op: PatchReplace, Path: "/b", Value: "d"}}) {
+		t.Errorf("Unexpected patch %v", patch)
+	}
+	patched, err := ApplyJSONPatch(original, patch)
+	if err != nil || !reflect.DeepEqual(patched, map[string]interface{}{"1": "a", "b": "d"}) {
+		t.Errorf("Unexpected result %v, %v", patched, err)
+	}
+	if merge := CreateMergePatch(original, modified); !reflect.DeepEqual(merge, map[string]interface{}{"b": "d", "true": nil}) {
+		t.Errorf("Unexpected merge patch %v", merge)
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	original := []byte(`{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"text","gone":1}`)
+	modified := []byte(`{"title":"Hello!","author":{"givenName":"John"},"tags":["example"],"content":"text","phoneNumber":"+01-123-456-7890","gone":null}`)
+
+	patch, err := CreateMergePatchBytes(original, modified)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := `{"author":{"familyName":null},"gone":null,"phoneNumber":"+01-123-456-7890","tags":["example"],"title":"Hello!"}`
+	if string(patch) != expected {
+		t.Errorf("Expected %s, got %s", expected, patch)
+	}
+
+	if _, err := CreateMergePatchBytes([]byte("{"), modified); err == nil {
+		t.Error("Expected error for invalid original")
+	}
+	if _, err := CreateMergePatchBytes(original, []byte("{")); err == nil {
+		t.Error("Expected error for invalid modified")
+	}
+}
+
+func TestPatchOperationMarshalJSON(t *testing.T) {
+	patch := JSONPatch{
+		{Op: PatchAdd, Path: "/a", Value: nil},
+		{Op: PatchRemove, Path: "/b"},
+		{Op: PatchMove, From: "/c", Path: "/d"},
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"},{"op":"move","path":"/d","from":"/c"}]`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
@@ -0,0 +1,185 @@
+package converters
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnmarshalProperties decodes Java .properties content into t. Keys and values are separated
+// by "=", ":" or whitespace, lines starting with # or ! are comments, a trailing backslash
+// continues a line and \t, \n, \uXXXX and other escapes are honoured. Dotted keys nest, so
+// db.pool.size=5 decodes like {"db": {"pool": {"size": 5}}}, and keys 0 to n-1 form a list.
+// Values are typed like plain YAML scalars.
+// Returns an error if an escape is malformed, one key is a prefix of another, or the values
+// cannot be decoded into t.
+func UnmarshalProperties(content []byte, t interface{}) error {
+	flat, err := parseProperties(content)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+	}
+	tree, err := Unflatten(flat)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+	}
+	if err := plainScalarNode(tree).Decode(t); err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+	}
+	return nil
+}
+
+// MarshalProperties encodes a struct or map, flattened as by Flatten, as sorted key=value
+// lines with dotted keys. Fields are named by their yaml tags, or their lowercased names, as
+// UnmarshalProperties reads them.
+// Returns an error if t is not a struct or map.
+func MarshalProperties(t interface{}) ([]byte, error) {
+	tree, err := toYamlMap(t)
+	if err != nil {
+		return nil, err
+	}
+	flat := Flatten(tree)
+	bff := bytes.Buffer{}
+	for _, key := range sortedKeys(flat) {
+		fmt.Fprintf(&bff, "%s=%s\n", escapeProperty(key, true), escapeProperty(csvCell(flat[key]), false))
+	}
+	return bff.Bytes(), nil
+}
+
+func parseProperties(content []byte) (map[string]interface{}, error) {
+	flat := make(map[string]interface{})
+	lines := strings.Split(strings.ReplaceAll(string(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))), "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		// An odd number of trailing backslashes joins the next line, without its leading space.
+		for propertyContinues(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		if propertyContinues(line) {
+			line = line[:len(line)-1]
+		}
+
+		end := 0
+		for end < len(line) && strings.IndexByte("=: \t\f", line[end]) < 0 {
+			if line[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		end = min(end, len(line))
+		key, rest := line[:end], strings.TrimLeft(line[end:], " \t\f")
+		if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+			rest = strings.TrimLeft(rest[1:], " \t\f")
+		}
+
+		unescapedKey, err := unescapeProperty(key)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		value, err := unescapeProperty(rest)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		flat[unescapedKey] = value
+	}
+	return flat, nil
+}
+
+func propertyContinues(line string) bool {
+	backslashes := len(line) - len(strings.TrimRight(line, `\`))
+	return backslashes%2 == 1
+}
+
+func unescapeProperty(text string) (string, error) {
+	if !strings.Contains(text, `\`) {
+		return text, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 == len(text) {
+			b.WriteByte(text[i])
+			continue
+		}
+		i++
+		switch text[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(text) {
+				return "", fmt.Errorf("malformed \\u escape")
+			}
+			code, err := strconv.ParseUint(text[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape \\u%s", text[i+1:i+5])
+			}
+			b.WriteRune(rune(code))
+			i += 4
+		default:
+			b.WriteByte(text[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// escapeProperty escapes backslashes, line breaks and tabs, and in keys also the separators
+// and comment markers; in values only a leading space needs escaping.
+func escapeProperty(text string, key bool) string {
+	var b strings.Builder
+	for i, r := range text {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\f':
+			b.WriteString(`\f`)
+		case key && strings.ContainsRune("=: #!", r), !key && i == 0 && r == ' ':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// plainScalarNode builds a node from a tree of maps, lists and strings, with the strings as
+// plain scalars so they are typed like unquoted YAML.
+func plainScalarNode(value interface{}) *yaml.Node {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range sortedKeys(v) {
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, plainScalarNode(v[key]))
+		}
+		return node
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			node.Content = append(node.Content, plainScalarNode(item))
+		}
+		return node
+	}
+	text := fmt.Sprint(value)
+	if text == "" {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: text}
+}
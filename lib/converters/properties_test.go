@@ -0,0 +1,92 @@
+package converters
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshalProperties(t *testing.T) {
+	content := `# comment
+! also a comment
+db.host = localhost
+db.port: 5432
+db.hosts.0 = a
+db.hosts.1 = b
+greeting   hello \
+    world
+path=C:\\temp\ttab
+unicode=caf\u00e9
+key\ with\ spaces=yes
+empty=
+`
+	var props map[string]interface{}
+	if err := UnmarshalProperties([]byte(content), &props); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"db": map[string]interface{}{
+			"host":  "localhost",
+			"port":  5432,
+			"hosts": []interface{}{"a", "b"},
+		},
+		"greeting":        "hello world",
+		"path":            "C:\\temp\ttab",
+		"unicode":         "café",
+		"key with spaces": "yes",
+		"empty":           "",
+	}
+	if !reflect.DeepEqual(props, expected) {
+		t.Errorf("Expected %v, got %v", expected, props)
+	}
+
+	var typed struct {
+		Db struct {
+			Port  int      `yaml:"port"`
+			Hosts []string `yaml:"hosts"`
+		} `yaml:"db"`
+	}
+	if err := UnmarshalProperties([]byte(content), &typed); err != nil || typed.Db.Port != 5432 || len(typed.Db.Hosts) != 2 {
+		t.Errorf("Unexpected struct %+v, %v", typed, err)
+	}
+
+	if err := UnmarshalProperties([]byte("a=1\nb=\\u12"), &props); err == nil || !strings.Contains(err.Error(), "line 2: malformed \\u escape") {
+		t.Errorf("Expected escape error, got %v", err)
+	}
+	if err := UnmarshalProperties([]byte("db=1\ndb.host=x"), &props); err == nil || !strings.Contains(err.Error(), "conflicts with the value at db") {
+		t.Errorf("Expected conflict error, got %v", err)
+	}
+}
+
+func TestMarshalProperties(t *testing.T) {
+	out, err := MarshalProperties(map[string]interface{}{
+		"db":      map[string]interface{}{"host": "localhost", "hosts": []string{"a", "b"}},
+		"a key":   " padded",
+		"escapes": "tab\there\\",
+		"none":    nil,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `a\ key=\ padded
+db.host=localhost
+db.hosts.0=a
+db.hosts.1=b
+escapes=tab\there\\
+none=
+`
+	if string(out) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	var back map[string]interface{}
+	if err := UnmarshalProperties(out, &back); err != nil || back["a key"] != " padded" || back["escapes"] != "tab\there\\" {
+		t.Errorf("Expected a round trip, got %v, %v", back, err)
+	}
+	if out, err := MarshalProperties(map[string]interface{}{"limits": map[string]interface{}{"bytes": 1234567.0}}); err != nil || string(out) != "limits.bytes=1234567\n" {
+		t.Errorf("Expected a plain number, got %q, %v", out, err)
+	}
+	if _, err := MarshalProperties("text"); err == nil {
+		t.Error("Expected error for a string")
+	}
+}
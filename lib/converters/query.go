@@ -0,0 +1,728 @@
+package converters
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrPathNotFound is returned, wrapped with details, when a path does not exist in a document.
+var ErrPathNotFound = errors.New("path not found")
+
+// Get returns the value at a dotted path, such as "services.0.name", in a generic document
+// tree as produced by UnmarshalYaml into an interface{} or collections.MapByStringKey.
+// Numeric segments index arrays, negative ones counting from the end, and a literal dot in
+// a key is written as "\.". An empty path returns doc itself.
+// Returns an error wrapping ErrPathNotFound that names the first missing segment.
+func Get(doc interface{}, path string) (interface{}, error) {
+	current := doc
+	walked := make([]string, 0)
+	for _, segment := range splitDottedPath(path) {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[segment]
+			if !ok {
+				return nil, missingPath(path, walked, "key %q does not exist", segment)
+			}
+			current = value
+		case map[interface{}]interface{}:
+			value, ok := node[segment]
+			if !ok {
+				return nil, missingPath(path, walked, "key %q does not exist", segment)
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil {
+				return nil, missingPath(path, walked, "%q is not an index into an array", segment)
+			}
+			if index < 0 {
+				index += len(node)
+			}
+			if index < 0 || index >= len(node) {
+				return nil, missingPath(path, walked, "index %s out of range for array of length %d", segment, len(node))
+			}
+			current = node[index]
+		default:
+			return nil, missingPath(path, walked, "cannot look up %q in %s", segment, schemaTypeOf(current))
+		}
+		walked = append(walked, segment)
+	}
+	return current, nil
+}
+
+// GetAs returns the value at a dotted path converted to T. Numbers convert between Go
+// numeric types when no precision is lost, so a YAML int or JSON float64 can be read as
+// any numeric T.
+// Returns an error wrapping ErrPathNotFound for missing paths, or naming the actual type
+// when the value cannot be converted.
+func GetAs[T any](doc interface{}, path string) (T, error) {
+	var out T
+	value, err := Get(doc, path)
+	if err != nil {
+		return out, err
+	}
+	if err := convertValue(value, &out); err != nil {
+		return out, fmt.Errorf("path: [%s], error: [%v]", path, err)
+	}
+	return out, nil
+}
+
+// Query evaluates a JSONPath expression against a generic document tree and returns every
+// matching value in document order, or an empty slice when nothing matches. Supported are
+// the root "$", child ".name" and "['name']", wildcards ".*" and "[*]", indexes "[0]" and
+// "[-1]", unions "[0,2]", slices "[start:end:step]", recursive descent "..name" and filters
+// "[?(@.enabled)]" or "[?(@.port >= 8000 && @.name != 'x')]". Filters support ==, !=, <,
+// <=, >, >=, =~ against /regex/, &&, || and !; a bare path is true when it exists and is
+// neither null nor false.
+// Returns an error if the expression cannot be parsed.
+func Query(doc interface{}, expression string) ([]interface{}, error) {
+	parser := &jsonPathParser{input: expression}
+	segments, err := parser.parse()
+	if err != nil {
+		return nil, err
+	}
+	return evaluateSegments(segments, []interface{}{doc}, doc), nil
+}
+
+// QueryAs evaluates a JSONPath expression like Query and converts every match to T.
+// Returns an error if the expression cannot be parsed or a match cannot be converted.
+func QueryAs[T any](doc interface{}, expression string) ([]T, error) {
+	matches, err := Query(doc, expression)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]T, 0, len(matches))
+	for i, match := range matches {
+		var out T
+		if err := convertValue(match, &out); err != nil {
+			return nil, fmt.Errorf("query: [%s], match: [%d], error: [%v]", expression, i, err)
+		}
+		results = append(results, out)
+	}
+	return results, nil
+}
+
+func missingPath(path string, walked []string, format string, args ...interface{}) error {
+	at := strings.Join(walked, ".")
+	if at == "" {
+		at = "$"
+	}
+	return fmt.Errorf("path: [%s], error: [%w, %s at %s]", path, ErrPathNotFound, fmt.Sprintf(format, args...), at)
+}
+
+// splitDottedPath splits a path on dots that are not escaped with a backslash.
+func splitDottedPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	segments := make([]string, 0)
+	var current strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch {
+		case path[i] == '\\' && i+1 < len(path) && path[i+1] == '.':
+			current.WriteByte('.')
+			i++
+		case path[i] == '.':
+			segments = append(segments, current.String())
+			current.Reset()
+		default:
+			current.WriteByte(path[i])
+		}
+	}
+	return append(segments, current.String())
+}
+
+// convertValue stores value in out, converting between numeric types without losing precision.
+func convertValue(value interface{}, out interface{}) error {
+	target := reflect.ValueOf(out).Elem()
+	if value == nil {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+	source := reflect.ValueOf(value)
+	if source.Type().AssignableTo(target.Type()) {
+		target.Set(source)
+		return nil
+	}
+	if number, ok := schemaNumber(value); ok {
+		switch target.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if number == float64(int64(number)) && !target.OverflowInt(int64(number)) {
+				target.SetInt(int64(number))
+				return nil
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if number >= 0 && number == float64(uint64(number)) && !target.OverflowUint(uint64(number)) {
+				target.SetUint(uint64(number))
+				return nil
+			}
+		case reflect.Float32, reflect.Float64:
+			target.SetFloat(number)
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot use %s value %v as %s", schemaTypeOf(value), value, target.Type())
+}
+
+// jsonPathSegment is one step of a JSONPath: a set of selectors applied to the children of
+// the current nodes, or to all descendants when recursive.
+type jsonPathSegment struct {
+	recursive bool
+	selectors []jsonPathSelector
+}
+
+type jsonPathSelector struct {
+	kind   string
+	name   string
+	index  int
+	slice  [3]*int
+	filter filterExpr
+}
+
+func evaluateSegments(segments []jsonPathSegment, nodes []interface{}, root interface{}) []interface{} {
+	for _, segment := range segments {
+		next := make([]interface{}, 0)
+		for _, node := range nodes {
+			targets := []interface{}{node}
+			if segment.recursive {
+				targets = descendants(node, targets)
+			}
+			for _, target := range targets {
+				for _, selector := range segment.selectors {
+					next = append(next, selector.apply(target, root)...)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// descendants appends node's children, recursively and in document order, to list.
+func descendants(node interface{}, list []interface{}) []interface{} {
+	for _, child := range children(node) {
+		list = append(list, child)
+		list = descendants(child, list)
+	}
+	return list
+}
+
+// children returns the values of an object, in key order, or the items of an array.
+func children(node interface{}) []interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		values := make([]interface{}, 0, len(n))
+		for _, key := range sortedKeys(n) {
+			values = append(values, n[key])
+		}
+		return values
+	case map[interface{}]interface{}:
+		keys := make([]string, 0, len(n))
+		byName := make(map[string]interface{}, len(n))
+		for k, v := range n {
+			name := fmt.Sprint(k)
+			keys = append(keys, name)
+			byName[name] = v
+		}
+		sort.Strings(keys)
+		values := make([]interface{}, 0, len(n))
+		for _, key := range keys {
+			values = append(values, byName[key])
+		}
+		return values
+	case []interface{}:
+		return n
+	}
+	return nil
+}
+
+func (s jsonPathSelector) apply(node interface{}, root interface{}) []interface{} {
+	switch s.kind {
+	case "name":
+		switch n := node.(type) {
+		case map[string]interface{}:
+			if value, ok := n[s.name]; ok {
+				return []interface{}{value}
+			}
+		case map[interface{}]interface{}:
+			if value, ok := n[s.name]; ok {
+				return []interface{}{value}
+			}
+		}
+	case "wildcard":
+		return children(node)
+	case "index":
+		if list, ok := node.([]interface{}); ok {
+			index := s.index
+			if index < 0 {
+				index += len(list)
+			}
+			if index >= 0 && index < len(list) {
+				return []interface{}{list[index]}
+			}
+		}
+	case "slice":
+		if list, ok := node.([]interface{}); ok {
+			return sliceItems(list, s.slice)
+		}
+	case "filter":
+		matches := make([]interface{}, 0)
+		for _, child := range children(node) {
+			if s.filter.truthy(child, root) {
+				matches = append(matches, child)
+			}
+		}
+		return matches
+	}
+	return nil
+}
+
+// sliceItems applies [start:end:step] to list with Python-style bounds.
+func sliceItems(list []interface{}, bounds [3]*int) []interface{} {
+	length := len(list)
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	if step == 0 {
+		return nil
+	}
+	normalize := func(bound *int, fallback int) int {
+		if bound == nil {
+			return fallback
+		}
+		value := *bound
+		if value < 0 {
+			value += length
+		}
+		if step > 0 {
+			return max(0, min(value, length))
+		}
+		return max(-1, min(value, length-1))
+	}
+	items := make([]interface{}, 0)
+	if step > 0 {
+		for i := normalize(bounds[0], 0); i < normalize(bounds[1], length); i += step {
+			items = append(items, list[i])
+		}
+	} else {
+		for i := normalize(bounds[0], length-1); i > normalize(bounds[1], -1); i += step {
+			items = append(items, list[i])
+		}
+	}
+	return items
+}
+
+// jsonPathParser parses JSONPath expressions and the paths inside filter expressions.
+type jsonPathParser struct {
+	input string
+	pos   int
+}
+
+func (p *jsonPathParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("query: [%s], error: [%s at offset %d]", p.input, fmt.Sprintf(format, args...), p.pos)
+}
+
+func (p *jsonPathParser) peek() byte {
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *jsonPathParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *jsonPathParser) parse() ([]jsonPathSegment, error) {
+	p.skipSpaces()
+	if p.peek() != '$' {
+		return nil, p.errorf("expected $")
+	}
+	p.pos++
+	segments, err := p.segments()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos != len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos:])
+	}
+	return segments, nil
+}
+
+// segments parses child and descendant segments until a character that cannot continue a path.
+func (p *jsonPathParser) segments() ([]jsonPathSegment, error) {
+	segments := make([]jsonPathSegment, 0)
+	for {
+		switch {
+		case strings.HasPrefix(p.input[p.pos:], ".."):
+			p.pos += 2
+			segment := jsonPathSegment{recursive: true}
+			selector, err := p.dotSelectorOrBracket()
+			if err != nil {
+				return nil, err
+			}
+			segment.selectors = selector
+			segments = append(segments, segment)
+		case p.peek() == '.':
+			p.pos++
+			selector, err := p.dotSelector()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, jsonPathSegment{selectors: []jsonPathSelector{selector}})
+		case p.peek() == '[':
+			selectors, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, jsonPathSegment{selectors: selectors})
+		default:
+			return segments, nil
+		}
+	}
+}
+
+func (p *jsonPathParser) dotSelectorOrBracket() ([]jsonPathSelector, error) {
+	if p.peek() == '[' {
+		return p.bracket()
+	}
+	selector, err := p.dotSelector()
+	if err != nil {
+		return nil, err
+	}
+	return []jsonPathSelector{selector}, nil
+}
+
+func (p *jsonPathParser) dotSelector() (jsonPathSelector, error) {
+	if p.peek() == '*' {
+		p.pos++
+		return jsonPathSelector{kind: "wildcard"}, nil
+	}
+	start := p.pos
+	for p.pos < len(p.input) && isNameChar(p.input[p.pos]) {
+		p.pos++
+	}
+	if start == p.pos {
+		return jsonPathSelector{}, p.errorf("expected a member name")
+	}
+	return jsonPathSelector{kind: "name", name: p.input[start:p.pos]}, nil
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '-' || c >= 0x80 || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+func (p *jsonPathParser) bracket() ([]jsonPathSelector, error) {
+	p.pos++
+	selectors := make([]jsonPathSelector, 0)
+	for {
+		p.skipSpaces()
+		selector, err := p.bracketSelector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return selectors, nil
+		default:
+			return nil, p.errorf("expected , or ]")
+		}
+	}
+}
+
+func (p *jsonPathParser) bracketSelector() (jsonPathSelector, error) {
+	switch c := p.peek(); {
+	case c == '*':
+		p.pos++
+		return jsonPathSelector{kind: "wildcard"}, nil
+	case c == '\'' || c == '"':
+		name, err := p.quoted()
+		return jsonPathSelector{kind: "name", name: name}, err
+	case c == '?':
+		p.pos++
+		p.skipSpaces()
+		filter, err := p.filterOr()
+		return jsonPathSelector{kind: "filter", filter: filter}, err
+	}
+
+	var bounds [3]*int
+	part := 0
+	isSlice := false
+	for {
+		p.skipSpaces()
+		if number, ok := p.integer(); ok {
+			bounds[part] = &number
+		}
+		p.skipSpaces()
+		if p.peek() != ':' || part == 2 {
+			break
+		}
+		p.pos++
+		part++
+		isSlice = true
+	}
+	if isSlice {
+		return jsonPathSelector{kind: "slice", slice: bounds}, nil
+	}
+	if bounds[0] == nil {
+		return jsonPathSelector{}, p.errorf("expected a selector")
+	}
+	return jsonPathSelector{kind: "index", index: *bounds[0]}, nil
+}
+
+func (p *jsonPathParser) integer() (int, bool) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.input) && '0' <= p.input[p.pos] && p.input[p.pos] <= '9' {
+		p.pos++
+	}
+	number, err := strconv.Atoi(p.input[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, false
+	}
+	return number, true
+}
+
+func (p *jsonPathParser) quoted() (string, error) {
+	quote := p.input[p.pos]
+	p.pos++
+	var out strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+		switch {
+		case c == '\\' && p.pos < len(p.input):
+			out.WriteByte(p.input[p.pos])
+			p.pos++
+		case c == quote:
+			return out.String(), nil
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// filterExpr is a node of a parsed filter expression.
+type filterExpr struct {
+	op          string
+	left, right *filterExpr
+	path        []jsonPathSegment
+	fromRoot    bool
+	literal     interface{}
+	isLiteral   bool
+	pattern     *regexp.Regexp
+}
+
+func (p *jsonPathParser) filterOr() (filterExpr, error) {
+	left, err := p.filterAnd()
+	if err != nil {
+		return left, err
+	}
+	for p.skipSpaces(); strings.HasPrefix(p.input[p.pos:], "||"); p.skipSpaces() {
+		p.pos += 2
+		right, err := p.filterAnd()
+		if err != nil {
+			return right, err
+		}
+		l, r := left, right
+		left = filterExpr{op: "||", left: &l, right: &r}
+	}
+	return left, nil
+}
+
+func (p *jsonPathParser) filterAnd() (filterExpr, error) {
+	left, err := p.filterUnary()
+	if err != nil {
+		return left, err
+	}
+	for p.skipSpaces(); strings.HasPrefix(p.input[p.pos:], "&&"); p.skipSpaces() {
+		p.pos += 2
+		right, err := p.filterUnary()
+		if err != nil {
+			return right, err
+		}
+		l, r := left, right
+		left = filterExpr{op: "&&", left: &l, right: &r}
+	}
+	return left, nil
+}
+
+func (p *jsonPathParser) filterUnary() (filterExpr, error) {
+	p.skipSpaces()
+	if p.peek() == '!' && !strings.HasPrefix(p.input[p.pos:], "!=") {
+		p.pos++
+		inner, err := p.filterUnary()
+		return filterExpr{op: "!", left: &inner}, err
+	}
+	if p.peek() == '(' {
+		p.pos++
+		inner, err := p.filterOr()
+		if err != nil {
+			return inner, err
+		}
+		p.skipSpaces()
+		if p.peek() != ')' {
+			return inner, p.errorf("expected )")
+		}
+		p.pos++
+		return inner, nil
+	}
+	left, err := p.filterOperand()
+	if err != nil {
+		return left, err
+	}
+	p.skipSpaces()
+	for _, op := range []string{"==", "!=", "<=", ">=", "=~", "<", ">"} {
+		if strings.HasPrefix(p.input[p.pos:], op) {
+			p.pos += len(op)
+			p.skipSpaces()
+			var right filterExpr
+			if op == "=~" {
+				right, err = p.filterRegex()
+			} else {
+				right, err = p.filterOperand()
+			}
+			if err != nil {
+				return right, err
+			}
+			return filterExpr{op: op, left: &left, right: &right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *jsonPathParser) filterOperand() (filterExpr, error) {
+	switch c := p.peek(); {
+	case c == '@' || c == '$':
+		p.pos++
+		segments, err := p.segments()
+		return filterExpr{path: segments, fromRoot: c == '$'}, err
+	case c == '\'' || c == '"':
+		text, err := p.quoted()
+		return filterExpr{literal: text, isLiteral: true}, err
+	}
+	for _, word := range []struct {
+		text  string
+		value interface{}
+	}{{"true", true}, {"false", false}, {"null", nil}} {
+		if strings.HasPrefix(p.input[p.pos:], word.text) {
+			p.pos += len(word.text)
+			return filterExpr{literal: word.value, isLiteral: true}, nil
+		}
+	}
+	start := p.pos
+	for p.pos < len(p.input) && strings.IndexByte("+-.0123456789eE", p.input[p.pos]) >= 0 {
+		p.pos++
+	}
+	number, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return filterExpr{}, p.errorf("expected a path or literal")
+	}
+	return filterExpr{literal: number, isLiteral: true}, nil
+}
+
+func (p *jsonPathParser) filterRegex() (filterExpr, error) {
+	if p.peek() != '/' {
+		return filterExpr{}, p.errorf("expected /regex/")
+	}
+	p.pos++
+	end := strings.IndexByte(p.input[p.pos:], '/')
+	if end < 0 {
+		return filterExpr{}, p.errorf("unterminated regex")
+	}
+	pattern, err := regexp.Compile(p.input[p.pos : p.pos+end])
+	if err != nil {
+		return filterExpr{}, p.errorf("invalid regex: %v", err)
+	}
+	p.pos += end + 1
+	return filterExpr{pattern: pattern}, nil
+}
+
+// value evaluates an operand, reporting false when a path matches nothing.
+func (f filterExpr) value(current interface{}, root interface{}) (interface{}, bool) {
+	if f.isLiteral {
+		return f.literal, true
+	}
+	start := current
+	if f.fromRoot {
+		start = root
+	}
+	matches := evaluateSegments(f.path, []interface{}{start}, root)
+	if len(matches) == 0 {
+		return nil, false
+	}
+	return matches[0], true
+}
+
+func (f filterExpr) truthy(current interface{}, root interface{}) bool {
+	switch f.op {
+	case "":
+		value, ok := f.value(current, root)
+		return ok && value != nil && value != false
+	case "!":
+		return !f.left.truthy(current, root)
+	case "&&":
+		return f.left.truthy(current, root) && f.right.truthy(current, root)
+	case "||":
+		return f.left.truthy(current, root) || f.right.truthy(current, root)
+	case "=~":
+		value, ok := f.left.value(current, root)
+		text, isString := value.(string)
+		return ok && isString && f.right.pattern.MatchString(text)
+	}
+	left, leftOk := f.left.value(current, root)
+	right, rightOk := f.right.value(current, root)
+	if !leftOk || !rightOk {
+		return f.op == "!=" && leftOk != rightOk
+	}
+	switch f.op {
+	case "==":
+		return schemaEqual(left, right)
+	case "!=":
+		return !schemaEqual(left, right)
+	}
+	if x, ok := schemaNumber(left); ok {
+		if y, ok := schemaNumber(right); ok {
+			return compareOrdered(x, y, f.op)
+		}
+	}
+	if x, ok := left.(string); ok {
+		if y, ok := right.(string); ok {
+			return compareOrdered(x, y, f.op)
+		}
+	}
+	return false
+}
+
+func compareOrdered[T int | float64 | string](x T, y T, op string) bool {
+	switch op {
+	case "<":
+		return x < y
+	case "<=":
+		return x <= y
+	case ">":
+		return x > y
+	case ">=":
+		return x >= y
+	}
+	return false
+}
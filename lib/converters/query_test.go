@@ -0,0 +1,174 @@
+package converters
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const queryDocument = `
+name: platform
+services:
+  - name: api
+    enabled: true
+    port: 8080
+    tags: [web, public]
+  - name: worker
+    enabled: false
+    port: 9090
+  - name: cache
+    port: 6379
+    tags: [internal]
+owners:
+  team.lead: alice
+`
+
+func queryDoc(t *testing.T) interface{} {
+	var doc interface{}
+	if err := UnmarshalYaml([]byte(queryDocument), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestGet(t *testing.T) {
+	doc := queryDoc(t)
+
+	t.Run("found", func(t *testing.T) {
+		for path, expected := range map[string]interface{}{
+			"name":              "platform",
+			"services.0.name":   "api",
+			"services.-1.port":  6379,
+			"services.0.tags.1": "public",
+			`owners.team\.lead`: "alice",
+		} {
+			value, err := Get(doc, path)
+			if err != nil {
+				t.Errorf("Path %s: unexpected error %v", path, err)
+				continue
+			}
+			if !reflect.DeepEqual(value, expected) {
+				t.Errorf("Path %s: expected %v, got %v", path, expected, value)
+			}
+		}
+		if value, err := Get(doc, ""); err != nil || !reflect.DeepEqual(value, doc) {
+			t.Errorf("Expected empty path to return the document, got %v, %v", value, err)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		for path, expected := range map[string]string{
+			"services.3.name":  "index 3 out of range for array of length 3 at services",
+			"services.0.host":  `key "host" does not exist at services.0`,
+			"services.first":   `"first" is not an index into an array at services`,
+			"name.first":       `cannot look up "first" in string at name`,
+			"unknown":          `key "unknown" does not exist at $`,
+			"services.1.ports": `key "ports" does not exist at services.1`,
+		} {
+			_, err := Get(doc, path)
+			if !errors.Is(err, ErrPathNotFound) {
+				t.Errorf("Path %s: expected ErrPathNotFound, got %v", path, err)
+				continue
+			}
+			if !strings.Contains(err.Error(), expected) || !strings.Contains(err.Error(), "path: ["+path+"]") {
+				t.Errorf("Path %s: expected %q in %q", path, expected, err.Error())
+			}
+		}
+	})
+
+	t.Run("typed", func(t *testing.T) {
+		port, err := GetAs[uint16](doc, "services.1.port")
+		if err != nil || port != 9090 {
+			t.Errorf("Expected 9090, got %v, %v", port, err)
+		}
+		ratio, err := GetAs[float64](doc, "services.1.port")
+		if err != nil || ratio != 9090 {
+			t.Errorf("Expected 9090, got %v, %v", ratio, err)
+		}
+		enabled, err := GetAs[bool](doc, "services.0.enabled")
+		if err != nil || !enabled {
+			t.Errorf("Expected true, got %v, %v", enabled, err)
+		}
+		if _, err := GetAs[int](doc, "name"); err == nil || !strings.Contains(err.Error(), "cannot use string value platform as int") {
+			t.Errorf("Expected conversion error, got %v", err)
+		}
+		if _, err := GetAs[int8](doc, "services.0.port"); err == nil {
+			t.Error("Expected overflow error")
+		}
+		if _, err := GetAs[string](doc, "missing"); !errors.Is(err, ErrPathNotFound) {
+			t.Errorf("Expected ErrPathNotFound, got %v", err)
+		}
+	})
+}
+
+func TestQuery(t *testing.T) {
+	doc := queryDoc(t)
+
+	cases := []struct {
+		expression string
+		expected   []interface{}
+	}{
+		{"$.name", []interface{}{"platform"}},
+		{"$.services[?(@.enabled)].name", []interface{}{"api"}},
+		{"$.services[?(!@.enabled)].name", []interface{}{"worker", "cache"}},
+		{"$.services[?(@.port >= 8000 && @.name != 'api')].name", []interface{}{"worker"}},
+		{"$.services[?(@.port < 7000 || @.enabled == true)].name", []interface{}{"api", "cache"}},
+		{"$.services[?(@.name =~ /^c/)].port", []interface{}{6379}},
+		{"$.services[?(@.tags)].name", []interface{}{"api", "cache"}},
+		{"$.services[?(@.port > $.services[0].port)].name", []interface{}{"worker"}},
+		{"$.services[*].name", []interface{}{"api", "worker", "cache"}},
+		{"$.services.*.port", []interface{}{8080, 9090, 6379}},
+		{"$['services'][0]['name']", []interface{}{"api"}},
+		{`$["owners"]["team.lead"]`, []interface{}{"alice"}},
+		{"$.services[-1].name", []interface{}{"cache"}},
+		{"$.services[0,2].name", []interface{}{"api", "cache"}},
+		{"$.services[1:].name", []interface{}{"worker", "cache"}},
+		{"$.services[::-1].name", []interface{}{"cache", "worker", "api"}},
+		{"$.services[:2:1].port", []interface{}{8080, 9090}},
+		{"$..tags[0]", []interface{}{"web", "internal"}},
+		{"$..port", []interface{}{8080, 9090, 6379}},
+		{"$.services[5].name", []interface{}{}},
+		{"$.missing", []interface{}{}},
+	}
+	for _, c := range cases {
+		t.Run(c.expression, func(t *testing.T) {
+			result, err := Query(doc, c.expression)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, c.expected) {
+				t.Errorf("Expected %v, got %v", c.expected, result)
+			}
+		})
+	}
+
+	t.Run("typed", func(t *testing.T) {
+		ports, err := QueryAs[int64](doc, "$.services[*].port")
+		if err != nil || !reflect.DeepEqual(ports, []int64{8080, 9090, 6379}) {
+			t.Errorf("Unexpected result %v, %v", ports, err)
+		}
+		if _, err := QueryAs[int](doc, "$.services[*].name"); err == nil || !strings.Contains(err.Error(), "match: [0]") {
+			t.Errorf("Expected conversion error, got %v", err)
+		}
+	})
+
+	t.Run("syntax errors", func(t *testing.T) {
+		for expression, expected := range map[string]string{
+			"services":                  "expected $",
+			"$.":                        "expected a member name",
+			"$.services[":               "expected a selector",
+			"$.services[0":              "expected , or ]",
+			"$['name":                   "unterminated string",
+			"$.services[?(@.port >":     "expected a path or literal",
+			"$.services[?(@.a =~ x":     "expected /regex/",
+			"$.services[?(@.a =~ /[/)]": "invalid regex",
+			"$.name extra":              "unexpected",
+		} {
+			_, err := Query(doc, expression)
+			if err == nil || !strings.Contains(err.Error(), expected) {
+				t.Errorf("Expression %s: expected %q, got %v", expression, expected, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,264 @@
+package converters
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"unsafe"
+
+	"github.com/skhatri/go-fns/lib/types"
+)
+
+// DefaultSecretKeyPattern matches map keys that commonly hold credentials, such as
+// "password", "db_pass", "apiKey", "client-secret", "token" or "private_key".
+var DefaultSecretKeyPattern = regexp.MustCompile(`(?i)pass(word|wd)?|secret|token|api[-_]?key|credential|private[-_]?key`)
+
+// maxRedactDepth stops Redact from following pointer cycles forever.
+const maxRedactDepth = 64
+
+var secretType = reflect.TypeOf(types.Secret(""))
+
+// RedactOptions controls RedactWithOptions.
+type RedactOptions struct {
+	// KeyPatterns masks the values of map entries whose key matches any pattern, such as
+	// DefaultSecretKeyPattern, in untyped documents.
+	KeyPatterns []*regexp.Regexp
+}
+
+// Redact returns a copy of item with the values of struct fields tagged `secret:"true"`, and
+// of types.Secret values, replaced by types.Redacted. String fields, and the strings of
+// slices, maps and pointers, become "***", empty values stay empty and fields of other types
+// are zeroed. The input is not modified and is returned as is when nothing needs masking.
+// It is the only place secrets are masked: JsonOptions and YamlOptions apply it when their
+// Redact option is set, for logs, while the file writers, codecs and canonical encoding
+// always keep real values so saved data reads back and hashes tell secrets apart.
+func Redact(item interface{}) interface{} {
+	return RedactWithOptions(item, RedactOptions{})
+}
+
+// RedactWithOptions redacts item like Redact and additionally masks map entries whose key
+// matches one of opts.KeyPatterns.
+func RedactWithOptions(item interface{}, opts RedactOptions) interface{} {
+	if item == nil {
+		return nil
+	}
+	redacted, changed := redactValue(reflect.ValueOf(item), opts, 0)
+	if !changed {
+		return item
+	}
+	return redacted.Interface()
+}
+
+// redactValue returns a redacted copy of v and true, or v and false when nothing inside it
+// is secret. Only the containers on the way to a secret are copied.
+func redactValue(v reflect.Value, opts RedactOptions, depth int) (reflect.Value, bool) {
+	if depth > maxRedactDepth {
+		return v, false
+	}
+	if v.Type() == secretType {
+		return maskValue(v, depth)
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, false
+		}
+		inner, changed := redactValue(v.Elem(), opts, depth+1)
+		if !changed {
+			return v, false
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(inner)
+		return out, true
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v, false
+		}
+		inner, changed := redactValue(v.Elem(), opts, depth+1)
+		if !changed {
+			return v, false
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(inner)
+		return out, true
+	case reflect.Struct:
+		return redactStruct(v, opts, depth)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return v, false
+		}
+		var out reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			item, changed := redactValue(v.Index(i), opts, depth+1)
+			if !changed {
+				continue
+			}
+			if !out.IsValid() {
+				out = copyList(v)
+			}
+			out.Index(i).Set(item)
+		}
+		if !out.IsValid() {
+			return v, false
+		}
+		return out, true
+	case reflect.Map:
+		if v.IsNil() {
+			return v, false
+		}
+		var out reflect.Value
+		iter := v.MapRange()
+		for iter.Next() {
+			value, changed := iter.Value(), false
+			if matchesSecretKey(iter.Key(), opts.KeyPatterns) {
+				value, changed = maskValue(value, depth+1)
+			} else {
+				value, changed = redactValue(value, opts, depth+1)
+			}
+			if !changed {
+				continue
+			}
+			if !out.IsValid() {
+				out = reflect.MakeMapWithSize(v.Type(), v.Len())
+				copyIter := v.MapRange()
+				for copyIter.Next() {
+					out.SetMapIndex(copyIter.Key(), copyIter.Value())
+				}
+			}
+			out.SetMapIndex(iter.Key(), value)
+		}
+		if !out.IsValid() {
+			return v, false
+		}
+		return out, true
+	}
+	return v, false
+}
+
+func redactStruct(v reflect.Value, opts RedactOptions, depth int) (reflect.Value, bool) {
+	var out reflect.Value
+	changedAny := false
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		current := v.Field(i)
+		if !field.IsExported() {
+			// Values read through an embedded unexported struct are read-only and cannot be
+			// copied with Set, so the field is redacted through the copy's address instead.
+			if !out.IsValid() {
+				out = copyStruct(v)
+			}
+			current = settableField(out, i)
+		}
+		var value reflect.Value
+		var changed bool
+		if field.Tag.Get("secret") == "true" {
+			value, changed = maskValue(current, depth+1)
+		} else {
+			value, changed = redactValue(current, opts, depth+1)
+		}
+		if !changed {
+			continue
+		}
+		if !out.IsValid() {
+			out = copyStruct(v)
+		}
+		settableField(out, i).Set(value)
+		changedAny = true
+	}
+	if !changedAny {
+		return v, false
+	}
+	return out, true
+}
+
+// copyStruct returns a settable shallow copy of a struct.
+func copyStruct(v reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	out.Set(v)
+	return out
+}
+
+// settableField returns field i of the addressable struct v, reaching unexported embedded
+// fields through their address so they can be read and set like exported ones.
+// encoding/json and yaml marshal the exported fields promoted from an unexported embedded
+// struct, so their secrets must be masked too, but reflect marks everything reached through
+// the embedded field read-only and offers no other way to set it. The write only ever
+// touches v, the private copy made by copyStruct, never the caller's value.
+func settableField(v reflect.Value, i int) reflect.Value {
+	field := v.Field(i)
+	if field.CanSet() {
+		return field
+	}
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+}
+
+// maskValue replaces the strings in v with types.Redacted, keeping containers and empty
+// strings, and zeroes any other non-zero value.
+func maskValue(v reflect.Value, depth int) (reflect.Value, bool) {
+	if v.IsZero() || depth > maxRedactDepth {
+		return v, false
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(types.Redacted).Convert(v.Type()), true
+	case reflect.Interface, reflect.Pointer:
+		inner, changed := maskValue(v.Elem(), depth+1)
+		if !changed {
+			return v, false
+		}
+		if v.Kind() == reflect.Interface {
+			out := reflect.New(v.Type()).Elem()
+			out.Set(inner)
+			return out, true
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(inner)
+		return out, true
+	case reflect.Slice, reflect.Array:
+		out := copyList(v)
+		for i := 0; i < v.Len(); i++ {
+			if item, changed := maskValue(v.Index(i), depth+1); changed {
+				out.Index(i).Set(item)
+			}
+		}
+		return out, true
+	case reflect.Map:
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			item, _ := maskValue(iter.Value(), depth+1)
+			out.SetMapIndex(iter.Key(), item)
+		}
+		return out, true
+	}
+	return reflect.Zero(v.Type()), true
+}
+
+// copyList returns a settable shallow copy of a slice or array.
+func copyList(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Array {
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		return out
+	}
+	out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	reflect.Copy(out, v)
+	return out
+}
+
+func matchesSecretKey(key reflect.Value, patterns []*regexp.Regexp) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	name := fmt.Sprint(key.Interface())
+	for _, pattern := range patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,242 @@
+package converters
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/skhatri/go-fns/lib/types"
+)
+
+type redactDatabase struct {
+	Host     string            `json:"host" yaml:"host"`
+	Password string            `json:"password" yaml:"password" secret:"true"`
+	Replicas []*redactDatabase `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+}
+
+type redactConfig struct {
+	Name     string                 `json:"name" yaml:"name"`
+	Token    *string                `json:"token" yaml:"token" secret:"true"`
+	Keys     []string               `json:"keys" yaml:"keys" secret:"true"`
+	Headers  map[string]string      `json:"headers" yaml:"headers" secret:"true"`
+	Pin      int                    `json:"pin" yaml:"pin" secret:"true"`
+	Empty    string                 `json:"empty" yaml:"empty" secret:"true"`
+	Database redactDatabase         `json:"database" yaml:"database"`
+	Backups  map[string]interface{} `json:"backups" yaml:"backups"`
+	Key      types.Secret           `json:"key" yaml:"key"`
+	hidden   string
+}
+
+type redactCredentials struct {
+	Password string `json:"password" yaml:"password" secret:"true"`
+}
+
+type redactToken struct {
+	Host  string `json:"host" yaml:"host"`
+	Token string `json:"token" yaml:"token" secret:"true"`
+}
+
+type redactEmbedded struct {
+	redactCredentials
+	*redactToken
+	Name string `json:"name" yaml:"name"`
+}
+
+func newRedactConfig() redactConfig {
+	token := "t0ken"
+	return redactConfig{
+		Name:    "app",
+		Token:   &token,
+		Keys:    []string{"k1", ""},
+		Headers: map[string]string{"Authorization": "Bearer x"},
+		Pin:     1234,
+		Database: redactDatabase{Host: "db", Password: "pw", Replicas: []*redactDatabase{
+			{Host: "replica", Password: "pw2"},
+		}},
+		Backups: map[string]interface{}{"primary": redactDatabase{Host: "b", Password: "pw3"}},
+		Key:     "real-key",
+		hidden:  "kept",
+	}
+}
+
+func TestRedact(t *testing.T) {
+	t.Run("tagged fields are masked in a copy", func(t *testing.T) {
+		config := newRedactConfig()
+		redacted := Redact(config).(redactConfig)
+
+		if *redacted.Token != "***" || redacted.Pin != 0 || redacted.Empty != "" {
+			t.Errorf("Unexpected redaction %+v", redacted)
+		}
+		if !reflect.DeepEqual(redacted.Keys, []string{"***", ""}) {
+			t.Errorf("Unexpected keys %v", redacted.Keys)
+		}
+		if redacted.Headers["Authorization"] != "***" {
+			t.Errorf("Unexpected headers %v", redacted.Headers)
+		}
+		if redacted.Database.Password != "***" || redacted.Database.Replicas[0].Password != "***" || redacted.Database.Host != "db" {
+			t.Errorf("Unexpected database %+v", redacted.Database)
+		}
+		if redacted.Backups["primary"].(redactDatabase).Password != "***" {
+			t.Errorf("Unexpected backups %v", redacted.Backups)
+		}
+		if redacted.hidden != "kept" || redacted.Key.Value() != "***" {
+			t.Errorf("Expected other fields to be copied, got %+v", redacted)
+		}
+
+		original := newRedactConfig()
+		if !reflect.DeepEqual(config, original) {
+			t.Errorf("Expected the input to be unchanged, got %+v", config)
+		}
+	})
+
+	t.Run("nothing to mask returns the input", func(t *testing.T) {
+		plain := []TestStruct{{Name: "a"}}
+		if result := Redact(plain); !reflect.DeepEqual(result, plain) {
+			t.Errorf("Unexpected result %v", result)
+		}
+		if Redact(nil) != nil {
+			t.Error("Expected nil")
+		}
+		pointer := &redactDatabase{Host: "db"}
+		if Redact(pointer) != pointer {
+			t.Error("Expected the same pointer when nothing is secret")
+		}
+		if Redact(&redactDatabase{Password: "pw"}).(*redactDatabase).Password != "***" {
+			t.Error("Expected pointers to be redacted")
+		}
+	})
+
+	t.Run("embedded unexported structs", func(t *testing.T) {
+		embedded := redactEmbedded{redactCredentials{"hunter2"}, &redactToken{Host: "db", Token: "t0ken"}, "x"}
+		redacted := Redact(embedded).(redactEmbedded)
+		if redacted.Password != "***" || redacted.Token != "***" || redacted.Host != "db" || redacted.Name != "x" {
+			t.Errorf("Unexpected redaction %+v", redacted)
+		}
+		if embedded.Password != "hunter2" || embedded.Token != "t0ken" {
+			t.Errorf("Expected the input to be unchanged, got %+v", embedded)
+		}
+		data, _ := MarshalToJsonWithOptions(embedded, JsonOptions{Redact: true})
+		if text := string(data); strings.Contains(text, "hunter2") || strings.Contains(text, "t0ken") || !strings.Contains(text, `"name": "x"`) {
+			t.Errorf("Unexpected json %s", text)
+		}
+		plain := redactEmbedded{Name: "x"}
+		if !reflect.DeepEqual(Redact(plain), plain) {
+			t.Error("Expected nothing to be masked")
+		}
+	})
+
+	t.Run("key patterns mask untyped maps", func(t *testing.T) {
+		var doc interface{}
+		_ = UnmarshalYaml([]byte(`
+db:
+  user: admin
+  password: hunter2
+  apiKey: [a, b]
+services:
+  - name: api
+    client_secret: {value: s}
+    timeout: 5
+`), &doc)
+		redacted := RedactWithOptions(doc, RedactOptions{KeyPatterns: []*regexp.Regexp{DefaultSecretKeyPattern}})
+		text := MarshalToJson(redacted)
+		for _, leaked := range []string{"hunter2", `"a"`, `"s"`} {
+			if strings.Contains(text, leaked) {
+				t.Errorf("Leaked %s in %s", leaked, text)
+			}
+		}
+		expected := map[string]interface{}{
+			"db": map[string]interface{}{"user": "admin", "password": "***", "apiKey": []interface{}{"***", "***"}},
+			"services": []interface{}{map[string]interface{}{
+				"name": "api", "client_secret": map[string]interface{}{"value": "***"}, "timeout": 5,
+			}},
+		}
+		if !reflect.DeepEqual(redacted, expected) {
+			t.Errorf("Expected %v, got %v", expected, redacted)
+		}
+		if !strings.Contains(MarshalToJson(doc), "hunter2") {
+			t.Error("Expected the input to be unchanged")
+		}
+	})
+
+	t.Run("only the redact options mask secrets", func(t *testing.T) {
+		config := newRedactConfig()
+		logged := make(map[string]string)
+		for name, opts := range map[string]JsonOptions{"json": {Redact: true}, "compact json": {Compact: true, Redact: true}} {
+			data, err := MarshalToJsonWithOptions(config, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			logged[name] = string(data)
+		}
+		data, err := MarshalToYamlWithOptions(config, YamlOptions{Redact: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		logged["yaml"] = string(data)
+		for name, output := range logged {
+			for _, leaked := range []string{"t0ken", "k1", "Bearer", "1234", "pw", "real-key"} {
+				if strings.Contains(output, leaked) {
+					t.Errorf("%s leaked %s:\n%s", name, leaked, output)
+				}
+			}
+			if !strings.Contains(output, "***") {
+				t.Errorf("%s has no masked values:\n%s", name, output)
+			}
+		}
+
+		persisted := map[string]string{"json": MarshalToJson(config), "json pretty": MarshalToJsonPretty(config)}
+		persisted["json string"], _ = MarshalToJsonString(config)
+		data, _ = MarshalToYamlWithOptions(config, YamlOptions{})
+		persisted["yaml"] = string(data)
+		documents, err := MarshalYamlDocuments([]redactConfig{config})
+		if err != nil {
+			t.Fatal(err)
+		}
+		persisted["yaml documents"] = string(documents)
+		canonical, err := MarshalCanonicalJson(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		persisted["canonical"] = string(canonical)
+		bff := bytes.Buffer{}
+		if err := NewNDJSONWriter(&bff).Write(config); err != nil {
+			t.Fatal(err)
+		}
+		persisted["ndjson"] = bff.String()
+		for _, name := range []string{"config.yaml", "config.json", "config.toml", "config.env"} {
+			file := t.TempDir() + "/" + name
+			if err := MarshalFile(config, file); err != nil {
+				t.Fatal(err)
+			}
+			content, _ := os.ReadFile(file)
+			persisted[name] = string(content)
+		}
+		for name, output := range persisted {
+			for _, kept := range []string{"t0ken", "k1", "Bearer", "1234", "pw3", "real-key"} {
+				if !strings.Contains(output, kept) {
+					t.Errorf("%s lost %s:\n%s", name, kept, output)
+				}
+			}
+		}
+
+		file := t.TempDir() + "/config.yaml"
+		if err := MarshalToYamlFile(config, file); err != nil {
+			t.Fatal(err)
+		}
+		var decoded redactConfig
+		if err := UnmarshalFile(file, &decoded); err != nil || decoded.Key.Value() != "real-key" || decoded.Database.Password != "pw" {
+			t.Errorf("Expected real values to read back, got %+v, %v", decoded, err)
+		}
+
+		other := newRedactConfig()
+		other.Key = "other-key"
+		first, _ := CanonicalJsonSha256(config)
+		second, _ := CanonicalJsonSha256(other)
+		if first == second {
+			t.Error("Expected configs differing in a secret to hash differently")
+		}
+	})
+}
@@ -0,0 +1,403 @@
+package converters
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/skhatri/go-fns/lib/types"
+)
+
+// maxSchemaRefDepth bounds how many $ref hops may be followed without consuming any of
+// the document, which stops self-referencing schemas from looping forever.
+const maxSchemaRefDepth = 32
+
+// Schema validates decoded documents against a JSON Schema. It supports the following
+// subset of draft 2020-12: type, enum, const, required, properties, additionalProperties,
+// items, pattern, minLength, maxLength, minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, minItems, maxItems, minProperties, maxProperties and $ref to
+// "#"-relative JSON Pointers such as "#/$defs/server". Other keywords are ignored.
+// A Schema is safe for concurrent use.
+type Schema struct {
+	root interface{}
+	// mu guards patterns, the compiled pattern keywords.
+	mu       sync.Mutex
+	patterns map[string]*types.Regex
+}
+
+// NewSchema creates a Schema from a decoded schema document, such as the result of
+// UnmarshalJson or UnmarshalYaml into an interface{}.
+// Returns an error if the document is neither an object nor a boolean.
+func NewSchema(doc interface{}) (*Schema, error) {
+	switch doc.(type) {
+	case map[string]interface{}, bool:
+	default:
+		return nil, fmt.Errorf("error creating schema, expected an object or boolean, got %T", doc)
+	}
+	return &Schema{root: doc, patterns: make(map[string]*types.Regex)}, nil
+}
+
+// LoadSchema reads a JSON or YAML schema file with UnmarshalFile.
+// Returns an error if the file cannot be read or is not a schema document.
+func LoadSchema(file string) (*Schema, error) {
+	var doc interface{}
+	if err := UnmarshalFile(file, &doc); err != nil {
+		return nil, err
+	}
+	schema, err := NewSchema(doc)
+	if err != nil {
+		return nil, fmt.Errorf("file: [%s], error: [%w]", file, err)
+	}
+	return schema, nil
+}
+
+// Validate checks a decoded document, as produced by UnmarshalYaml or UnmarshalJson into
+// an interface{}, against the schema.
+// Returns FieldErrors whose Path is the JSON Pointer of each failing value, "" being the root.
+func (s *Schema) Validate(doc interface{}) error {
+	errs := make(FieldErrors, 0)
+	s.validate(s.root, doc, "", 0, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ValidateFile reads a file with UnmarshalFile and validates its contents against the schema.
+// Every problem found is reported with the file name.
+func (s *Schema) ValidateFile(file string) error {
+	var doc interface{}
+	if err := UnmarshalFile(file, &doc); err != nil {
+		return err
+	}
+	err := s.Validate(doc)
+	if errs, ok := err.(FieldErrors); ok {
+		for _, e := range errs {
+			e.File = file
+		}
+	}
+	return err
+}
+
+// UnmarshalFileWithSchema validates a file against schema before unmarshaling it into t
+// with UnmarshalFile, so t is left untouched when the document is invalid.
+func UnmarshalFileWithSchema(file string, schema *Schema, t interface{}) error {
+	if err := schema.ValidateFile(file); err != nil {
+		return err
+	}
+	return UnmarshalFile(file, t)
+}
+
+func (s *Schema) fail(errs *FieldErrors, pointer string, format string, args ...interface{}) {
+	*errs = append(*errs, &FieldError{Path: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+func (s *Schema) validate(schema interface{}, value interface{}, pointer string, refDepth int, errs *FieldErrors) {
+	switch sc := schema.(type) {
+	case bool:
+		if !sc {
+			s.fail(errs, pointer, "no value is allowed here")
+		}
+		return
+	case map[string]interface{}:
+		s.validateObject(sc, value, pointer, refDepth, errs)
+	default:
+		s.fail(errs, pointer, "invalid schema of type %T", schema)
+	}
+}
+
+func (s *Schema) validateObject(schema map[string]interface{}, value interface{}, pointer string, refDepth int, errs *FieldErrors) {
+	if ref, ok := schema["$ref"].(string); ok {
+		if refDepth >= maxSchemaRefDepth {
+			s.fail(errs, pointer, "$ref %q nested too deeply", ref)
+			return
+		}
+		target, err := s.resolveRef(ref)
+		if err != nil {
+			s.fail(errs, pointer, "%v", err)
+			return
+		}
+		s.validate(target, value, pointer, refDepth+1, errs)
+	}
+
+	if expected, ok := schema["type"]; ok && !matchesSchemaType(expected, value) {
+		s.fail(errs, pointer, "expected type %s, got %s", describeSchemaType(expected), schemaTypeOf(value))
+		return
+	}
+	if constant, ok := schema["const"]; ok && !schemaEqual(constant, value) {
+		s.fail(errs, pointer, "must equal %v", constant)
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, option := range enum {
+			if schemaEqual(option, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			s.fail(errs, pointer, "must be one of %v", enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		s.validateString(schema, v, pointer, errs)
+	case map[string]interface{}:
+		s.validateProperties(schema, v, pointer, errs)
+	case []interface{}:
+		s.validateItems(schema, v, pointer, errs)
+	default:
+		if number, ok := schemaNumber(value); ok {
+			s.validateNumber(schema, number, pointer, errs)
+		}
+	}
+}
+
+func (s *Schema) validateString(schema map[string]interface{}, value string, pointer string, errs *FieldErrors) {
+	length := float64(len([]rune(value)))
+	if limit, ok := schemaNumber(schema["minLength"]); ok && length < limit {
+		s.fail(errs, pointer, "must be at least %v characters", limit)
+	}
+	if limit, ok := schemaNumber(schema["maxLength"]); ok && length > limit {
+		s.fail(errs, pointer, "must be at most %v characters", limit)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := s.compile(pattern)
+		if err != nil {
+			s.fail(errs, pointer, "invalid pattern %q: %v", pattern, err)
+		} else if !re.MatchString(value) {
+			s.fail(errs, pointer, "must match pattern %s", pattern)
+		}
+	}
+}
+
+func (s *Schema) validateNumber(schema map[string]interface{}, value float64, pointer string, errs *FieldErrors) {
+	if limit, ok := schemaNumber(schema["minimum"]); ok && value < limit {
+		s.fail(errs, pointer, "must be at least %v", limit)
+	}
+	if limit, ok := schemaNumber(schema["maximum"]); ok && value > limit {
+		s.fail(errs, pointer, "must be at most %v", limit)
+	}
+	if limit, ok := schemaNumber(schema["exclusiveMinimum"]); ok && value <= limit {
+		s.fail(errs, pointer, "must be greater than %v", limit)
+	}
+	if limit, ok := schemaNumber(schema["exclusiveMaximum"]); ok && value >= limit {
+		s.fail(errs, pointer, "must be less than %v", limit)
+	}
+}
+
+func (s *Schema) validateProperties(schema map[string]interface{}, value map[string]interface{}, pointer string, errs *FieldErrors) {
+	count := float64(len(value))
+	if limit, ok := schemaNumber(schema["minProperties"]); ok && count < limit {
+		s.fail(errs, pointer, "must have at least %v properties", limit)
+	}
+	if limit, ok := schemaNumber(schema["maxProperties"]); ok && count > limit {
+		s.fail(errs, pointer, "must have at most %v properties", limit)
+	}
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			key := fmt.Sprint(name)
+			if _, present := value[key]; !present {
+				s.fail(errs, pointer+"/"+escapePointer(key), "is required")
+			}
+		}
+	}
+	properties, _ := schema["properties"].(map[string]interface{})
+	additional, hasAdditional := schema["additionalProperties"]
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		child := pointer + "/" + escapePointer(key)
+		if propertySchema, ok := properties[key]; ok {
+			s.validate(propertySchema, value[key], child, 0, errs)
+		} else if hasAdditional {
+			if allowed, ok := additional.(bool); ok && !allowed {
+				s.fail(errs, child, "additional property %q is not allowed", key)
+			} else {
+				s.validate(additional, value[key], child, 0, errs)
+			}
+		}
+	}
+}
+
+func (s *Schema) validateItems(schema map[string]interface{}, value []interface{}, pointer string, errs *FieldErrors) {
+	count := float64(len(value))
+	if limit, ok := schemaNumber(schema["minItems"]); ok && count < limit {
+		s.fail(errs, pointer, "must have at least %v items", limit)
+	}
+	if limit, ok := schemaNumber(schema["maxItems"]); ok && count > limit {
+		s.fail(errs, pointer, "must have at most %v items", limit)
+	}
+	if items, ok := schema["items"]; ok {
+		for i, item := range value {
+			s.validate(items, item, pointer+"/"+strconv.Itoa(i), 0, errs)
+		}
+	}
+}
+
+// resolveRef finds the schema addressed by a "#"-relative JSON Pointer.
+func (s *Schema) resolveRef(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %q, only references within the document are allowed", ref)
+	}
+	current := s.root
+	pointer := strings.TrimPrefix(ref, "#")
+	if pointer == "" {
+		return current, nil
+	}
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = unescapePointer(token)
+		switch node := current.(type) {
+		case map[string]interface{}:
+			next, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("unresolved $ref %q", ref)
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, fmt.Errorf("unresolved $ref %q", ref)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("unresolved $ref %q", ref)
+		}
+	}
+	return current, nil
+}
+
+func (s *Schema) compile(pattern string) (*types.Regex, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if re, ok := s.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := types.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	s.patterns[pattern] = re
+	return re, nil
+}
+
+// escapePointer escapes a key for use as a JSON Pointer reference token (RFC 6901).
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// unescapePointer reverses escapePointer.
+func unescapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// schemaTypeOf returns the JSON Schema type name of a decoded value.
+func schemaTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		if number, ok := schemaNumber(v); ok {
+			if number == math.Trunc(number) && !math.IsInf(number, 0) {
+				return "integer"
+			}
+			return "number"
+		}
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func matchesSchemaType(expected interface{}, value interface{}) bool {
+	actual := schemaTypeOf(value)
+	matches := func(name interface{}) bool {
+		return name == actual || (name == "number" && actual == "integer")
+	}
+	if list, ok := expected.([]interface{}); ok {
+		for _, name := range list {
+			if matches(name) {
+				return true
+			}
+		}
+		return false
+	}
+	return matches(expected)
+}
+
+func describeSchemaType(expected interface{}) string {
+	if list, ok := expected.([]interface{}); ok {
+		names := make([]string, 0, len(list))
+		for _, name := range list {
+			names = append(names, fmt.Sprint(name))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(expected)
+}
+
+// schemaNumber converts any decoded numeric value to float64.
+func schemaNumber(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	if n, ok := value.(interface{ Float64() (float64, error) }); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// schemaEqual compares decoded values the way JSON Schema does, treating numbers of
+// different Go types as equal when their values are.
+func schemaEqual(a interface{}, b interface{}) bool {
+	if x, ok := schemaNumber(a); ok {
+		y, ok := schemaNumber(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if other, ok := y[k]; !ok || !schemaEqual(v, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !schemaEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
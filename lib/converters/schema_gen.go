@@ -0,0 +1,257 @@
+package converters
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skhatri/go-fns/lib/types"
+)
+
+// SchemaDialect is the JSON Schema draft that SchemaFor documents declare.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	regexType = reflect.TypeOf(types.Regex{})
+	timeType  = reflect.TypeOf(time.Time{})
+)
+
+// SchemaFor generates a JSON Schema document describing T, ready to be written out with
+// MarshalToJsonPretty or loaded with NewSchema.
+// Property names follow the yaml, then json, tags, and fields without a tag name are
+// lowercased as UnmarshalFile decodes YAML; embedded and inline structs contribute their
+// properties to the enclosing object. Objects reject unknown properties unless the struct
+// has an inline map, whose values then describe them. `default` tags become
+// defaults and `validate` tags become required, minimum/maximum (or the matching length
+// keywords), enum and pattern. Named struct types are emitted once under $defs and
+// referenced, so recursive types are supported. types.Regex is described as a string with
+// format "regex", time.Time as "date-time" and other text unmarshalers as plain strings.
+func SchemaFor[T any]() map[string]interface{} {
+	return SchemaForType(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// SchemaForType is the reflect.Type variant of SchemaFor.
+func SchemaForType(t reflect.Type) map[string]interface{} {
+	gen := &schemaGenerator{defs: make(map[string]interface{}), names: make(map[reflect.Type]string)}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var root map[string]interface{}
+	if t.Kind() == reflect.Struct && !isTextValue(t) {
+		root = gen.structSchema(t)
+	} else {
+		root = gen.schema(t)
+	}
+	root["$schema"] = SchemaDialect
+	if t.Name() != "" {
+		root["title"] = t.Name()
+	}
+	if len(gen.defs) > 0 {
+		root["$defs"] = gen.defs
+	}
+	return root
+}
+
+type schemaGenerator struct {
+	defs  map[string]interface{}
+	names map[reflect.Type]string
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == regexType:
+		return map[string]interface{}{"type": "string", "format": "regex"}
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == durationType:
+		return map[string]interface{}{"type": "string"}
+	case isTextValue(t):
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/$defs/" + g.define(t)}
+	}
+	return map[string]interface{}{}
+}
+
+// define registers the schema of a named struct under $defs and returns its key.
+func (g *schemaGenerator) define(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	for i := 2; g.defs[name] != nil; i++ {
+		name = fmt.Sprintf("%s%d", t.Name(), i)
+	}
+	g.names[t] = name
+	// reserve the name while the struct's own fields are generated
+	g.defs[name] = true
+	g.defs[name] = g.structSchema(t)
+	return name
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := make([]interface{}, 0)
+	var additional interface{} = false
+	g.collectProperties(t, properties, &required, &additional)
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": additional,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// collectProperties adds the fields of t to properties and required, and sets additional to
+// the schema of an inline map's values.
+func (g *schemaGenerator) collectProperties(t reflect.Type, properties map[string]interface{}, required *[]interface{}, additional *interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		if field.Tag.Get("yaml") == "-" && field.Tag.Get("json") == "-" {
+			continue
+		}
+		name := schemaPropertyName(field)
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if name == "" {
+			switch {
+			case fieldType.Kind() == reflect.Struct && !isTextValue(fieldType):
+				g.collectProperties(fieldType, properties, required, additional)
+			case fieldType.Kind() == reflect.Map:
+				*additional = g.schema(fieldType.Elem())
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		property := g.schema(field.Type)
+		if value, ok := field.Tag.Lookup("default"); ok {
+			property["default"] = schemaValue(fieldType, property, value)
+		}
+		if rules, ok := field.Tag.Lookup("validate"); ok {
+			if applySchemaRules(fieldType, property, rules) {
+				*required = append(*required, name)
+			}
+		}
+		properties[name] = property
+	}
+}
+
+// schemaPropertyName names a field as UnmarshalFile decodes it from YAML: by its yaml, then
+// json, tag name, or else its lowercased name.
+func schemaPropertyName(field reflect.StructField) string {
+	name := documentFieldName(field)
+	for _, key := range []string{"yaml", "json"} {
+		if tagName, _, _ := strings.Cut(field.Tag.Get(key), ","); tagName != "" && tagName != "-" {
+			return name
+		}
+	}
+	return strings.ToLower(name)
+}
+
+// applySchemaRules translates validate tag rules into schema keywords and reports
+// whether the field is required.
+func applySchemaRules(t reflect.Type, property map[string]interface{}, tag string) bool {
+	isRequired := false
+	for tag != "" {
+		name, arg := nextRule(&tag)
+		switch name {
+		case "required":
+			isRequired = true
+		case "min", "max":
+			keyword := boundKeyword(t, name)
+			if keyword == "" {
+				continue
+			}
+			if bound, err := strconv.ParseFloat(arg, 64); err == nil {
+				property[keyword] = bound
+			}
+		case "oneof":
+			options := make([]interface{}, 0)
+			for _, option := range strings.Fields(arg) {
+				options = append(options, schemaValue(t, property, option))
+			}
+			property["enum"] = options
+		case "pattern":
+			property["pattern"] = arg
+		}
+	}
+	return isRequired
+}
+
+// boundKeyword returns the schema keyword that expresses a min or max rule for t.
+func boundKeyword(t reflect.Type, rule string) string {
+	if t == durationType {
+		return ""
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return rule + "Length"
+	case reflect.Slice, reflect.Array:
+		return rule + "Items"
+	case reflect.Map:
+		return rule + "Properties"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return rule + "imum"
+	}
+	return ""
+}
+
+// schemaValue converts a tag value into the JSON value matching the property's type,
+// keeping the raw text for string-typed properties.
+func schemaValue(t reflect.Type, property map[string]interface{}, text string) interface{} {
+	if property["type"] == "string" {
+		return text
+	}
+	value := reflect.New(t).Elem()
+	if err := setFromString(value, text); err != nil {
+		return text
+	}
+	if value.Kind() == reflect.Slice {
+		items := make([]interface{}, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			items = append(items, value.Index(i).Interface())
+		}
+		return items
+	}
+	return value.Interface()
+}
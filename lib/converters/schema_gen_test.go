@@ -0,0 +1,204 @@
+package converters
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skhatri/go-fns/lib/types"
+)
+
+type genBase struct {
+	Region string `yaml:"region"`
+}
+
+type genServer struct {
+	Host string `yaml:"host" validate:"required"`
+	Port uint16 `yaml:"port" default:"8080" validate:"max=9000"`
+}
+
+type genNode struct {
+	Name     string     `json:"name"`
+	Children []*genNode `json:"children,omitempty"`
+}
+
+type genConfig struct {
+	genBase  `yaml:",inline"`
+	Name     string               `yaml:"name" validate:"required,min=3,pattern=^[a-z,]+$"`
+	Mode     string               `yaml:"mode" default:"dev" validate:"oneof=dev prod"`
+	Level    int                  `yaml:"level" validate:"oneof=1 2 3"`
+	Ratio    float64              `yaml:"ratio" validate:"min=0,max=1"`
+	Enabled  bool                 `yaml:"enabled" default:"true"`
+	Tags     []string             `yaml:"tags" default:"a,b" validate:"max=5"`
+	Timeout  time.Duration        `yaml:"timeout" default:"5s" validate:"max=1m"`
+	Match    *types.Regex         `yaml:"match"`
+	Created  time.Time            `yaml:"created"`
+	Payload  []byte               `yaml:"payload"`
+	Primary  genServer            `yaml:"primary"`
+	Backups  map[string]genServer `yaml:"backups" validate:"min=1"`
+	Extra    interface{}          `yaml:"extra"`
+	Tree     genNode              `yaml:"tree"`
+	Ignored  string               `yaml:"-" json:"-"`
+	internal string
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema := SchemaFor[genConfig]()
+
+	t.Run("document shape", func(t *testing.T) {
+		if schema["$schema"] != SchemaDialect || schema["title"] != "genConfig" || schema["type"] != "object" {
+			t.Errorf("Unexpected root %v", schema)
+		}
+		if !reflect.DeepEqual(schema["required"], []interface{}{"name"}) {
+			t.Errorf("Expected name to be required, got %v", schema["required"])
+		}
+		properties := schema["properties"].(map[string]interface{})
+		for _, name := range []string{"region", "name", "backups", "tree"} {
+			if _, ok := properties[name]; !ok {
+				t.Errorf("Expected property %s", name)
+			}
+		}
+		for _, name := range []string{"genBase", "Ignored", "internal"} {
+			if _, ok := properties[name]; ok {
+				t.Errorf("Unexpected property %s", name)
+			}
+		}
+	})
+
+	t.Run("property keywords", func(t *testing.T) {
+		properties := schema["properties"].(map[string]interface{})
+		expected := map[string]map[string]interface{}{
+			"name":    {"type": "string", "minLength": 3.0, "pattern": "^[a-z,]+$"},
+			"mode":    {"type": "string", "default": "dev", "enum": []interface{}{"dev", "prod"}},
+			"level":   {"type": "integer", "enum": []interface{}{1, 2, 3}},
+			"ratio":   {"type": "number", "minimum": 0.0, "maximum": 1.0},
+			"enabled": {"type": "boolean", "default": true},
+			"tags":    {"type": "array", "items": map[string]interface{}{"type": "string"}, "default": []interface{}{"a", "b"}, "maxItems": 5.0},
+			"timeout": {"type": "string", "default": "5s"},
+			"match":   {"type": "string", "format": "regex"},
+			"created": {"type": "string", "format": "date-time"},
+			"payload": {"type": "string", "contentEncoding": "base64"},
+			"primary": {"$ref": "#/$defs/genServer"},
+			"backups": {"type": "object", "additionalProperties": map[string]interface{}{"$ref": "#/$defs/genServer"}, "minProperties": 1.0},
+			"extra":   {},
+		}
+		for name, exp := range expected {
+			if !reflect.DeepEqual(properties[name], exp) {
+				t.Errorf("Property %s: expected %v, got %v", name, exp, properties[name])
+			}
+		}
+	})
+
+	t.Run("named structs are shared definitions", func(t *testing.T) {
+		defs := schema["$defs"].(map[string]interface{})
+		server := defs["genServer"].(map[string]interface{})
+		port := server["properties"].(map[string]interface{})["port"]
+		if !reflect.DeepEqual(port, map[string]interface{}{"type": "integer", "minimum": 0, "maximum": 9000.0, "default": uint16(8080)}) {
+			t.Errorf("Unexpected port schema %v", port)
+		}
+		node := defs["genNode"].(map[string]interface{})
+		children := node["properties"].(map[string]interface{})["children"]
+		if !reflect.DeepEqual(children, map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/$defs/genNode"}}) {
+			t.Errorf("Expected recursive reference, got %v", children)
+		}
+	})
+
+	t.Run("generated schema validates documents", func(t *testing.T) {
+		var doc interface{}
+		if err := UnmarshalJson([]byte(MarshalToJsonPretty(schema)), &doc); err != nil {
+			t.Fatal(err)
+		}
+		validator, err := NewSchema(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var valid interface{}
+		_ = UnmarshalYaml([]byte("name: app\nregion: eu\nbackups: {b1: {host: x}}\ntree: {name: root, children: [{name: leaf}]}\n"), &valid)
+		if err := validator.Validate(valid); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		var invalid interface{}
+		_ = UnmarshalYaml([]byte("name: a\nmode: test\nbackups: {b1: {port: 9999}}\nunknown: 1\n"), &invalid)
+		var errs FieldErrors
+		if !errors.As(validator.Validate(invalid), &errs) {
+			t.Fatal("Expected validation errors")
+		}
+		messages := errs.Error()
+		for _, expected := range []string{"/backups/b1/host", "/backups/b1/port", "/mode", "/name", "/unknown"} {
+			if !strings.Contains(messages, "field: ["+expected+"]") {
+				t.Errorf("Expected error for %s in:\n%s", expected, messages)
+			}
+		}
+	})
+
+	t.Run("untagged fields and inline maps", func(t *testing.T) {
+		type plugin struct {
+			Name    string
+			Workers int            `validate:"min=1"`
+			Options map[string]int `yaml:",inline"`
+		}
+		generated := SchemaFor[plugin]()
+		properties := generated["properties"].(map[string]interface{})
+		for _, name := range []string{"name", "workers"} {
+			if _, ok := properties[name]; !ok {
+				t.Errorf("Expected lowercased property %s in %v", name, properties)
+			}
+		}
+		if !reflect.DeepEqual(generated["additionalProperties"], map[string]interface{}{"type": "integer"}) {
+			t.Errorf("Expected inline map values as additionalProperties, got %v", generated["additionalProperties"])
+		}
+
+		validator := loadGeneratedSchema(t, generated)
+		var doc interface{}
+		_ = UnmarshalYaml([]byte("name: x\nworkers: 2\nretries: 3\n"), &doc)
+		if err := validator.Validate(doc); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		var decoded plugin
+		if err := UnmarshalYaml([]byte("name: x\nworkers: 2\nretries: 3\n"), &decoded); err != nil || decoded.Workers != 2 || decoded.Options["retries"] != 3 {
+			t.Errorf("Expected the document to decode into the struct, got %+v, %v", decoded, err)
+		}
+		_ = UnmarshalYaml([]byte("name: x\nworkers: 0\nretries: many\n"), &doc)
+		if err := validator.Validate(doc); err == nil || !strings.Contains(err.Error(), "field: [/retries]") || !strings.Contains(err.Error(), "field: [/workers]") {
+			t.Errorf("Expected errors for retries and workers, got %v", err)
+		}
+	})
+
+	t.Run("map property counts", func(t *testing.T) {
+		validator := loadGeneratedSchema(t, schema)
+		var doc interface{}
+		_ = UnmarshalYaml([]byte("name: app\nbackups: {}\n"), &doc)
+		if err := validator.Validate(doc); err == nil || !strings.Contains(err.Error(), "field: [/backups], error: [must have at least 1 properties]") {
+			t.Errorf("Expected backups to need a property, got %v", err)
+		}
+	})
+
+	t.Run("non-struct types", func(t *testing.T) {
+		list := SchemaFor[[]int]()
+		if list["type"] != "array" || list["title"] != nil {
+			t.Errorf("Unexpected schema %v", list)
+		}
+		regex := SchemaFor[*types.Regex]()
+		if regex["format"] != "regex" {
+			t.Errorf("Unexpected schema %v", regex)
+		}
+	})
+}
+
+// loadGeneratedSchema loads a generated schema the way it is read back from a written file.
+func loadGeneratedSchema(t *testing.T, schema map[string]interface{}) *Schema {
+	t.Helper()
+	var doc interface{}
+	if err := UnmarshalJson([]byte(MarshalToJsonPretty(schema)), &doc); err != nil {
+		t.Fatal(err)
+	}
+	validator, err := NewSchema(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return validator
+}
@@ -0,0 +1,249 @@
+package converters
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const serviceSchema = `
+$schema: https://json-schema.org/draft/2020-12/schema
+type: object
+required: [name, servers]
+additionalProperties: false
+properties:
+  name:
+    type: string
+    minLength: 3
+    pattern: "^[a-z-]+$"
+  mode:
+    enum: [dev, prod]
+  replicas:
+    type: integer
+    minimum: 1
+    exclusiveMaximum: 10
+  ratio:
+    type: number
+    maximum: 1
+  labels:
+    type: object
+    additionalProperties:
+      type: string
+  servers:
+    type: array
+    minItems: 1
+    items:
+      $ref: "#/$defs/server"
+$defs:
+  server:
+    type: object
+    required: [host]
+    properties:
+      host: {type: string}
+      port: {type: [integer, "null"], maximum: 65535}
+      path/with~chars: {const: fixed}
+`
+
+func loadTestSchema(t *testing.T) *Schema {
+	t.Helper()
+	schemaFile := filepath.Join(t.TempDir(), "schema.yaml")
+	if err := os.WriteFile(schemaFile, []byte(serviceSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := LoadSchema(schemaFile)
+	if err != nil {
+		t.Fatalf("Unexpected error loading schema: %v", err)
+	}
+	return schema
+}
+
+func TestSchemaValidate(t *testing.T) {
+	schema := loadTestSchema(t)
+
+	t.Run("valid document", func(t *testing.T) {
+		var doc interface{}
+		content := `{"name": "api", "mode": "prod", "replicas": 3, "ratio": 0.5, "labels": {"team": "core"},
+			"servers": [{"host": "a", "port": 80}, {"host": "b", "port": null}]}`
+		if err := UnmarshalJson([]byte(content), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if err := schema.Validate(doc); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("errors keyed by JSON Pointer", func(t *testing.T) {
+		var doc interface{}
+		content := `
+name: A
+mode: test
+replicas: 10
+ratio: 1.5
+labels: {team: 1}
+servers:
+  - port: 70000
+  - host: b
+    port: 1.5
+    path/with~chars: other
+extra: true
+`
+		if err := UnmarshalYaml([]byte(content), &doc); err != nil {
+			t.Fatal(err)
+		}
+		var errs FieldErrors
+		if !errors.As(schema.Validate(doc), &errs) {
+			t.Fatal("Expected FieldErrors")
+		}
+		expected := []struct{ pointer, message string }{
+			{"/extra", "additional property \"extra\" is not allowed"},
+			{"/labels/team", "expected type string, got integer"},
+			{"/mode", "must be one of [dev prod]"},
+			{"/name", "must be at least 3 characters"},
+			{"/name", "must match pattern ^[a-z-]+$"},
+			{"/ratio", "must be at most 1"},
+			{"/replicas", "must be less than 10"},
+			{"/servers/0/host", "is required"},
+			{"/servers/0/port", "must be at most 65535"},
+			{"/servers/1/path~1with~0chars", "must equal fixed"},
+			{"/servers/1/port", "expected type integer or null, got number"},
+		}
+		if len(errs) != len(expected) {
+			t.Fatalf("Expected %d errors, got %d:\n%v", len(expected), len(errs), errs)
+		}
+		for i, exp := range expected {
+			if errs[i].Path != exp.pointer || errs[i].Message != exp.message {
+				t.Errorf("Expected %s: %s, got %s: %s", exp.pointer, exp.message, errs[i].Path, errs[i].Message)
+			}
+		}
+	})
+
+	t.Run("root type mismatch", func(t *testing.T) {
+		var errs FieldErrors
+		if !errors.As(schema.Validate([]interface{}{}), &errs) || errs[0].Path != "" {
+			t.Errorf("Expected root error, got %v", errs)
+		}
+	})
+
+	t.Run("boolean and invalid schemas", func(t *testing.T) {
+		never, _ := NewSchema(map[string]interface{}{
+			"properties": map[string]interface{}{"a": false, "b": "oops", "c": map[string]interface{}{"pattern": "["}},
+			"items":      true,
+		})
+		err := never.Validate(map[string]interface{}{"a": 1, "b": 2, "c": "x"})
+		var errs FieldErrors
+		if !errors.As(err, &errs) || len(errs) != 3 {
+			t.Errorf("Expected 3 errors, got %v", err)
+		}
+	})
+
+	t.Run("property counts", func(t *testing.T) {
+		counted, _ := NewSchema(map[string]interface{}{"minProperties": 1.0, "maxProperties": 2.0})
+		for doc, message := range map[string]string{
+			`{}`:                       "must have at least 1 properties",
+			`{"a": 1}`:                 "",
+			`{"a": 1, "b": 2, "c": 3}`: "must have at most 2 properties",
+		} {
+			var value interface{}
+			_ = UnmarshalJson([]byte(doc), &value)
+			err := counted.Validate(value)
+			if message == "" && err != nil || message != "" && (err == nil || !strings.Contains(err.Error(), message)) {
+				t.Errorf("Document %s: expected %q, got %v", doc, message, err)
+			}
+		}
+	})
+
+	t.Run("unresolved and cyclic references", func(t *testing.T) {
+		cases := []map[string]interface{}{
+			{"$ref": "#/$defs/missing"},
+			{"$ref": "other.json#/a"},
+			{"$ref": "#"},
+			{"$ref": "#/allOf/5", "allOf": []interface{}{}},
+		}
+		for _, doc := range cases {
+			schema, _ := NewSchema(doc)
+			if err := schema.Validate("value"); err == nil {
+				t.Errorf("Expected error for %v", doc)
+			}
+		}
+	})
+}
+
+func TestSchemaValidateFile(t *testing.T) {
+	schema := loadTestSchema(t)
+	type service struct {
+		Name string `yaml:"name"`
+	}
+
+	t.Run("valid file is decoded", func(t *testing.T) {
+		dataFile := filepath.Join(t.TempDir(), "service.yaml")
+		if err := os.WriteFile(dataFile, []byte("name: api\nservers: [{host: a}]\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var svc service
+		if err := UnmarshalFileWithSchema(dataFile, schema, &svc); err != nil || svc.Name != "api" {
+			t.Errorf("Unexpected result %+v %v", svc, err)
+		}
+	})
+
+	t.Run("invalid file is not decoded", func(t *testing.T) {
+		dataFile := filepath.Join(t.TempDir(), "service.json")
+		if err := os.WriteFile(dataFile, []byte(`{"name": "api"}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var svc service
+		err := UnmarshalFileWithSchema(dataFile, schema, &svc)
+		var errs FieldErrors
+		if !errors.As(err, &errs) || errs[0].File != dataFile || errs[0].Path != "/servers" {
+			t.Errorf("Unexpected error %v", err)
+		}
+		if svc.Name != "" {
+			t.Errorf("Expected struct to be untouched, got %+v", svc)
+		}
+	})
+
+	t.Run("error on non-existent file", func(t *testing.T) {
+		if err := schema.ValidateFile("nonexistent.yaml"); err == nil {
+			t.Error("Expected error for non-existent file")
+		}
+		if _, err := LoadSchema("nonexistent.yaml"); err == nil {
+			t.Error("Expected error for non-existent schema")
+		}
+	})
+
+	t.Run("error on non-schema document", func(t *testing.T) {
+		schemaFile := filepath.Join(t.TempDir(), "schema.yaml")
+		if err := os.WriteFile(schemaFile, []byte("- a\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSchema(schemaFile); err == nil {
+			t.Error("Expected error for array schema")
+		}
+	})
+}
+
+func TestSchemaValidateConcurrent(t *testing.T) {
+	schema, err := NewSchema(map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{"type": "string", "pattern": "^[a-z]+[0-9]*$"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	group := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		group.Add(1)
+		go func(i int) {
+			defer group.Done()
+			doc := []interface{}{"host" + strconv.Itoa(i), "Bad"}
+			var errs FieldErrors
+			if err := schema.Validate(doc); !errors.As(err, &errs) || len(errs) != 1 || errs[0].Path != "/1" {
+				t.Errorf("Expected one pattern error, got %v", err)
+			}
+		}(i)
+	}
+	group.Wait()
+}
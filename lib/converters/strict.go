@@ -0,0 +1,637 @@
+package converters
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldError describes a problem with a single field of a document.
+// Line and Column are 1-based and zero when unknown. Snippet, when the source is known, holds
+// the offending line with a caret under the column, and is printed below the error by the
+// %+v verb. Err is the underlying decode error, such as a *json.SyntaxError or
+// *yaml.TypeError, for use with errors.As.
+type FieldError struct {
+	File    string
+	Line    int
+	Column  int
+	Path    string
+	Message string
+	Snippet string
+	Err     error
+}
+
+func (e *FieldError) Error() string {
+	parts := make([]string, 0, 4)
+	if e.File != "" {
+		parts = append(parts, fmt.Sprintf("file: [%s]", e.File))
+	}
+	if e.Line > 0 {
+		parts = append(parts, fmt.Sprintf("line: [%d], column: [%d]", e.Line, e.Column))
+	}
+	if e.Path != "" {
+		parts = append(parts, fmt.Sprintf("field: [%s]", e.Path))
+	}
+	parts = append(parts, fmt.Sprintf("error: [%s]", e.Message))
+	return strings.Join(parts, ", ")
+}
+
+// Format prints the error as Error does; the %+v verb adds the Snippet, when known, on the
+// lines below it.
+func (e *FieldError) Format(s fmt.State, verb rune) {
+	formatError(s, verb, e.Error())
+	if verb == 'v' && s.Flag('+') && e.Snippet != "" {
+		io.WriteString(s, "\n"+e.Snippet)
+	}
+}
+
+// Unwrap returns the underlying decode error, if any.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors collects every FieldError found in a document so they can be reported at once.
+type FieldErrors []*FieldError
+
+func (errs FieldErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Format prints the errors as Error does; the %+v verb prints each with its Snippet.
+func (errs FieldErrors) Format(s fmt.State, verb rune) {
+	if verb != 'v' || !s.Flag('+') {
+		formatError(s, verb, errs.Error())
+		return
+	}
+	for i, err := range errs {
+		if i > 0 {
+			io.WriteString(s, "\n")
+		}
+		err.Format(s, verb)
+	}
+}
+
+// formatError writes message for the %s, %v and %q verbs.
+func formatError(s fmt.State, verb rune, message string) {
+	if verb == 'q' {
+		fmt.Fprintf(s, "%q", message)
+		return
+	}
+	io.WriteString(s, message)
+}
+
+// Unwrap returns the individual errors for use with errors.Is and errors.As.
+func (errs FieldErrors) Unwrap() []error {
+	list := make([]error, 0, len(errs))
+	for _, err := range errs {
+		list = append(list, err)
+	}
+	return list
+}
+
+// UnmarshalYamlStrict unmarshals YAML like UnmarshalYaml, but first checks the document
+// against t and fails on unknown fields, duplicate keys and scalars that would only fit
+// their field through type coercion, such as a number or boolean given for a string.
+// Returns FieldErrors listing every problem found, or the decode error.
+func UnmarshalYamlStrict(content []byte, t interface{}) error {
+	return unmarshalYamlStrict("", content, t)
+}
+
+// UnmarshalJsonStrict unmarshals JSON like UnmarshalJson, but first checks the document
+// against t and fails on unknown fields, duplicate keys and mismatched types.
+// Returns FieldErrors listing every problem found, or the decode error.
+func UnmarshalJsonStrict(content []byte, t interface{}) error {
+	return unmarshalJsonStrict("", content, t)
+}
+
+// UnmarshalFileStrict reads a file and unmarshals it like UnmarshalFile, applying the
+// checks of UnmarshalJsonStrict to .json files and those of UnmarshalYamlStrict to others.
+// Every problem found is reported with the file name.
+func UnmarshalFileStrict(file string, t interface{}) error {
+	content, err := readFile(file)
+	if err != nil {
+		return fmt.Errorf("file: [%s], error: [%w]", file, err)
+	}
+	_, name, _ := CompressionForFile(file)
+	if codec, ok := CodecForFile(name); ok && codec.Format == FormatJSON {
+		return unmarshalJsonStrict(file, content, t)
+	}
+	return unmarshalYamlStrict(file, content, t)
+}
+
+// UnmarshalJsonFileStrict reads a JSON file and unmarshals it like UnmarshalJsonFile,
+// applying the checks of UnmarshalJsonStrict. Every problem found is reported with the file name.
+func UnmarshalJsonFileStrict(file string, t interface{}) error {
+	content, err := readFile(file)
+	if err != nil {
+		return fmt.Errorf("file: [%s], error: [%w]", file, err)
+	}
+	return unmarshalJsonStrict(file, content, t)
+}
+
+func unmarshalYamlStrict(file string, content []byte, t interface{}) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return withFile(file, content, fmt.Errorf("error unmarshalling to %T, with error %w", t, err))
+	}
+	checker := &strictChecker{file: file, content: content, tagKey: "yaml"}
+	checker.check(&root, reflect.TypeOf(t), "")
+	if len(checker.errs) > 0 {
+		return checker.errs
+	}
+	if err := root.Decode(t); err != nil {
+		return withFile(file, content, fmt.Errorf("error unmarshalling to %T, with error %w", t, err))
+	}
+	return nil
+}
+
+func unmarshalJsonStrict(file string, content []byte, t interface{}) error {
+	root, err := jsonNode(content)
+	if err != nil {
+		return withFile(file, content, fmt.Errorf("error unmarshalling to %T, with error %w", t, err))
+	}
+	checker := &strictChecker{file: file, content: content, tagKey: "json"}
+	checker.check(root, reflect.TypeOf(t), "")
+	if len(checker.errs) > 0 {
+		return checker.errs
+	}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(t); err != nil {
+		return withFile(file, content, fmt.Errorf("error unmarshalling to %T, with error %w", t, err))
+	}
+	return nil
+}
+
+// withFile locates a decode error of content read from file, if there is one, with the
+// line, column and field path it occurred at.
+func withFile(file string, content []byte, err error) error {
+	if file == "" {
+		return err
+	}
+	return locateError(file, content, err)
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	emptyInterfaceType  = reflect.TypeOf((*interface{})(nil)).Elem()
+)
+
+// strictChecker walks a document alongside the Go type it is decoded into and
+// records every mismatch instead of stopping at the first.
+type strictChecker struct {
+	file    string
+	content []byte
+	tagKey  string
+	errs    FieldErrors
+}
+
+func (c *strictChecker) fail(node *yaml.Node, path string, format string, args ...interface{}) {
+	c.errs = append(c.errs, &FieldError{
+		File:    c.file,
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+		Snippet: sourceSnippet(c.content, node.Line, node.Column),
+	})
+}
+
+func (c *strictChecker) check(node *yaml.Node, t reflect.Type, path string) {
+	switch node.Kind {
+	case 0:
+		return
+	case yaml.DocumentNode:
+		if len(node.Content) > 0 {
+			c.check(node.Content[0], t, path)
+		}
+		return
+	case yaml.AliasNode:
+		c.check(node.Alias, t, path)
+		return
+	}
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return
+	}
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t == emptyInterfaceType || c.isOpaque(t) {
+		c.checkDuplicates(node, path)
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if c.expectKind(node, yaml.MappingNode, t, path) {
+			c.checkStruct(node, structFieldsOf(t, c.tagKey), path)
+		}
+	case reflect.Map:
+		if c.expectKind(node, yaml.MappingNode, t, path) {
+			c.checkMapping(node, path, func(key *yaml.Node, value *yaml.Node, valuePath string) {
+				c.check(value, t.Elem(), valuePath)
+			})
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && node.Kind == yaml.ScalarNode {
+			return
+		}
+		if c.expectKind(node, yaml.SequenceNode, t, path) {
+			for i, item := range node.Content {
+				c.check(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+			}
+		}
+	case reflect.Interface:
+		c.checkDuplicates(node, path)
+	default:
+		if c.expectKind(node, yaml.ScalarNode, t, path) {
+			c.checkScalar(node, t, path)
+		}
+	}
+}
+
+// isOpaque reports whether t decodes itself, in which case its contents are not checked.
+func (c *strictChecker) isOpaque(t reflect.Type) bool {
+	ptr := reflect.PointerTo(t)
+	if ptr.Implements(textUnmarshalerType) {
+		return true
+	}
+	if c.tagKey == "json" {
+		return ptr.Implements(jsonUnmarshalerType)
+	}
+	return ptr.Implements(yamlUnmarshalerType)
+}
+
+func (c *strictChecker) expectKind(node *yaml.Node, kind yaml.Kind, t reflect.Type, path string) bool {
+	if node.Kind == kind {
+		return true
+	}
+	c.fail(node, path, "expected %s for %s, found %s", kindName(kind), t, kindName(node.Kind))
+	return false
+}
+
+func (c *strictChecker) checkScalar(node *yaml.Node, t reflect.Type, path string) {
+	tag := node.ShortTag()
+	if t == durationType && c.tagKey == "yaml" && (tag == "!!str" || tag == "!!int") {
+		return
+	}
+	var ok bool
+	switch t.Kind() {
+	case reflect.String:
+		ok = tag == "!!str"
+	case reflect.Bool:
+		ok = tag == "!!bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		ok = tag == "!!int"
+	case reflect.Float32, reflect.Float64:
+		ok = tag == "!!int" || tag == "!!float"
+	}
+	if !ok {
+		c.fail(node, path, "cannot use %s value %q as %s", strings.TrimPrefix(tag, "!!"), node.Value, t)
+	}
+}
+
+func (c *strictChecker) checkStruct(node *yaml.Node, fields structFields, path string) {
+	c.checkMapping(node, path, func(key *yaml.Node, value *yaml.Node, valuePath string) {
+		if fieldType, ok := fields.lookup(key.Value); ok {
+			c.check(value, fieldType, valuePath)
+		} else if fields.inlineMap != nil {
+			c.check(value, fields.inlineMap, valuePath)
+		} else {
+			c.fail(key, valuePath, "unknown field %q", key.Value)
+		}
+	})
+}
+
+// checkMapping reports duplicate keys in a mapping node and hands every other pair,
+// including those pulled in through "<<" merge keys, to visit.
+func (c *strictChecker) checkMapping(node *yaml.Node, path string, visit func(key *yaml.Node, value *yaml.Node, valuePath string)) {
+	seen := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Kind == yaml.ScalarNode && key.ShortTag() == "!!merge" {
+			c.visitMerge(value, path, visit)
+			continue
+		}
+		if first, ok := seen[key.Value]; ok {
+			c.fail(key, joinFieldPath(path, key.Value), "duplicate key %q, first defined at line %d", key.Value, first.Line)
+			continue
+		}
+		seen[key.Value] = key
+		visit(key, value, joinFieldPath(path, key.Value))
+	}
+}
+
+func (c *strictChecker) visitMerge(node *yaml.Node, path string, visit func(key *yaml.Node, value *yaml.Node, valuePath string)) {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		c.checkMapping(node, path, visit)
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			c.visitMerge(item, path, visit)
+		}
+	}
+}
+
+// checkDuplicates looks for duplicate keys anywhere below node without type information.
+func (c *strictChecker) checkDuplicates(node *yaml.Node, path string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		c.checkMapping(node, path, func(key *yaml.Node, value *yaml.Node, valuePath string) {
+			c.checkDuplicates(value, valuePath)
+		})
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			c.checkDuplicates(item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// structFields describes the keys a struct accepts when decoding.
+type structFields struct {
+	fields    map[string]reflect.Type
+	fold      bool
+	inlineMap reflect.Type
+}
+
+func (f structFields) lookup(name string) (reflect.Type, bool) {
+	if t, ok := f.fields[name]; ok {
+		return t, true
+	}
+	if f.fold {
+		for field, t := range f.fields {
+			if strings.EqualFold(field, name) {
+				return t, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// structFieldsOf collects the document keys of struct t following the naming rules of
+// yaml.v3 (tagKey "yaml") or encoding/json (tagKey "json").
+func structFieldsOf(t reflect.Type, tagKey string) structFields {
+	fields := structFields{fields: make(map[string]reflect.Type), fold: tagKey == "json"}
+	if tagKey == "json" {
+		collectJsonFields(t, &fields)
+	} else {
+		collectYamlFields(t, &fields)
+	}
+	return fields
+}
+
+// jsonField is a candidate for a JSON key, found depth embedded structs below the top.
+type jsonField struct {
+	t      reflect.Type
+	depth  int
+	tagged bool
+}
+
+// collectJsonFields collects the keys of struct t as encoding/json does: untagged embedded
+// structs promote their fields, and when several fields share a name the shallowest wins,
+// then the one with a json tag; any other tie hides the name.
+func collectJsonFields(t reflect.Type, fields *structFields) {
+	candidates := make(map[string][]jsonField)
+	level := []reflect.Type{t}
+	visited := make(map[reflect.Type]bool)
+	for depth := 0; len(level) > 0; depth++ {
+		next := make([]reflect.Type, 0)
+		for _, st := range level {
+			if visited[st] {
+				continue
+			}
+			visited[st] = true
+			for i := 0; i < st.NumField(); i++ {
+				field := st.Field(i)
+				tag := field.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+				name, _, _ := strings.Cut(tag, ",")
+				fieldType := field.Type
+				if field.Anonymous && fieldType.Kind() == reflect.Pointer {
+					fieldType = fieldType.Elem()
+				}
+				if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+					next = append(next, fieldType)
+					continue
+				}
+				if !field.IsExported() {
+					continue
+				}
+				tagged := name != ""
+				if !tagged {
+					name = field.Name
+				}
+				candidates[name] = append(candidates[name], jsonField{t: field.Type, depth: depth, tagged: tagged})
+			}
+		}
+		level = next
+	}
+	for name, found := range candidates {
+		if field, ok := dominantJsonField(found); ok {
+			fields.fields[name] = field.t
+		}
+	}
+}
+
+// dominantJsonField picks the field encoding/json decodes a name into, if any.
+func dominantJsonField(found []jsonField) (jsonField, bool) {
+	shallowest := make([]jsonField, 0, len(found))
+	for _, field := range found {
+		if len(shallowest) > 0 && field.depth > shallowest[0].depth {
+			continue
+		}
+		if len(shallowest) > 0 && field.depth < shallowest[0].depth {
+			shallowest = shallowest[:0]
+		}
+		shallowest = append(shallowest, field)
+	}
+	if len(shallowest) == 1 {
+		return shallowest[0], true
+	}
+	var dominant []jsonField
+	for _, field := range shallowest {
+		if field.tagged {
+			dominant = append(dominant, field)
+		}
+	}
+	if len(dominant) == 1 {
+		return dominant[0], true
+	}
+	return jsonField{}, false
+}
+
+// collectYamlFields collects the keys of struct t as yaml.v3 does, adding the fields of
+// structs and maps tagged `yaml:",inline"`.
+func collectYamlFields(t reflect.Type, fields *structFields) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		inline := strings.Contains(","+opts+",", ",inline,")
+		if !field.IsExported() && !(inline && field.Anonymous) {
+			continue
+		}
+		if inline {
+			fieldType := field.Type
+			for fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			switch fieldType.Kind() {
+			case reflect.Struct:
+				collectYamlFields(fieldType, fields)
+				continue
+			case reflect.Map:
+				fields.inlineMap = fieldType.Elem()
+				continue
+			}
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if _, exists := fields.fields[name]; !exists {
+			fields.fields[name] = field.Type
+		}
+	}
+}
+
+func joinFieldPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func kindName(kind yaml.Kind) string {
+	switch kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "sequence"
+	case yaml.ScalarNode:
+		return "scalar"
+	case yaml.AliasNode:
+		return "alias"
+	default:
+		return "document"
+	}
+}
+
+// jsonNode parses JSON into a yaml.Node tree carrying the line and column of every
+// value, so JSON documents can be checked with the same walker as YAML.
+func jsonNode(content []byte) (*yaml.Node, error) {
+	parser := &jsonNodeParser{content: content, decoder: json.NewDecoder(bytes.NewReader(content))}
+	parser.decoder.UseNumber()
+	root, err := parser.value()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parser.decoder.Token(); err != io.EOF {
+		if err == nil {
+			err = fmt.Errorf("invalid character after top-level value")
+		}
+		return nil, err
+	}
+	return &yaml.Node{Kind: yaml.DocumentNode, Line: 1, Column: 1, Content: []*yaml.Node{root}}, nil
+}
+
+type jsonNodeParser struct {
+	content []byte
+	decoder *json.Decoder
+}
+
+// position returns the line and column of the next token.
+func (p *jsonNodeParser) position() (int, int) {
+	offset := int(p.decoder.InputOffset())
+	for offset < len(p.content) && strings.IndexByte(" \t\r\n,:", p.content[offset]) >= 0 {
+		offset++
+	}
+	return offsetPosition(p.content, offset)
+}
+
+func (p *jsonNodeParser) value() (*yaml.Node, error) {
+	line, column := p.position()
+	token, err := p.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	node := &yaml.Node{Line: line, Column: column}
+	switch v := token.(type) {
+	case json.Delim:
+		if v == '{' {
+			node.Kind, node.Tag = yaml.MappingNode, "!!map"
+			for p.decoder.More() {
+				keyLine, keyColumn := p.position()
+				key, err := p.decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string), Line: keyLine, Column: keyColumn},
+					value)
+			}
+		} else {
+			node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+			for p.decoder.More() {
+				item, err := p.value()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, item)
+			}
+		}
+		if _, err := p.decoder.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!str", v
+	case json.Number:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!int", v.String()
+		if strings.ContainsAny(v.String(), ".eE") {
+			node.Tag = "!!float"
+		}
+	case bool:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!bool", fmt.Sprint(v)
+	case nil:
+		node.Kind, node.Tag, node.Value = yaml.ScalarNode, "!!null", "null"
+	}
+	return node, nil
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column, counting the
+// column in characters as yaml does.
+func offsetPosition(content []byte, offset int) (int, int) {
+	if offset > len(content) {
+		offset = len(content)
+	}
+	line := 1 + bytes.Count(content[:offset], []byte("\n"))
+	column := 1 + utf8.RuneCount(content[bytes.LastIndexByte(content[:offset], '\n')+1:offset])
+	return line, column
+}
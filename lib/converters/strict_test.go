@@ -0,0 +1,279 @@
+package converters
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skhatri/go-fns/lib/types"
+)
+
+type strictConfig struct {
+	Name           string            `yaml:"name" json:"name"`
+	Port           int               `yaml:"port" json:"port"`
+	Debug          bool              `yaml:"debug" json:"debug"`
+	Ratio          float64           `yaml:"ratio" json:"ratio"`
+	Timeout        time.Duration     `yaml:"timeout" json:"timeout"`
+	Match          *types.Regex      `yaml:"match" json:"match"`
+	Labels         map[string]string `yaml:"labels" json:"labels"`
+	Servers        []strictServer    `yaml:"servers" json:"servers"`
+	Extra          interface{}       `yaml:"extra" json:"extra"`
+	strictEmbedded `yaml:",inline"`
+}
+
+type strictEmbedded struct {
+	Region string `yaml:"region" json:"region"`
+}
+
+type strictServer struct {
+	Host string `yaml:"host" json:"host"`
+}
+
+func fieldErrors(t *testing.T, err error) FieldErrors {
+	t.Helper()
+	var errs FieldErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("Expected FieldErrors, got %v", err)
+	}
+	return errs
+}
+
+func TestUnmarshalYamlStrict(t *testing.T) {
+	t.Run("valid document", func(t *testing.T) {
+		content := []byte(`
+name: app
+port: 8080
+debug: true
+ratio: 1
+timeout: 5s
+match: "^a+$"
+region: eu
+labels: {team: core}
+servers:
+  - host: a
+extra: {anything: [1, 2]}
+`)
+		var cfg strictConfig
+		if err := UnmarshalYamlStrict(content, &cfg); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.Name != "app" || cfg.Port != 8080 || cfg.Timeout != 5*time.Second ||
+			cfg.Region != "eu" || !cfg.Match.MatchString("aa") || cfg.Servers[0].Host != "a" {
+			t.Errorf("Unexpected config %+v", cfg)
+		}
+	})
+
+	t.Run("all problems reported at once", func(t *testing.T) {
+		content := []byte(`name: 123
+port: "8080"
+debug: yes please
+servers:
+  - host: a
+    hots: b
+naem: typo
+port: 9090
+labels:
+  team: core
+  team: other
+extra:
+  a: 1
+  a: 2
+`)
+		var cfg strictConfig
+		errs := fieldErrors(t, UnmarshalYamlStrict(content, &cfg))
+
+		expected := []struct {
+			path string
+			line int
+			text string
+		}{
+			{"name", 1, "cannot use int value \"123\" as string"},
+			{"port", 2, "cannot use str value \"8080\" as int"},
+			{"debug", 3, "as bool"},
+			{"servers[0].hots", 6, "unknown field \"hots\""},
+			{"naem", 7, "unknown field \"naem\""},
+			{"port", 8, "duplicate key \"port\", first defined at line 2"},
+			{"labels.team", 11, "duplicate key"},
+			{"extra.a", 14, "duplicate key"},
+		}
+		if len(errs) != len(expected) {
+			t.Fatalf("Expected %d errors, got %d:\n%v", len(expected), len(errs), errs)
+		}
+		for i, exp := range expected {
+			if errs[i].Path != exp.path || errs[i].Line != exp.line || !strings.Contains(errs[i].Message, exp.text) {
+				t.Errorf("Expected %s at line %d with %q, got %v", exp.path, exp.line, exp.text, errs[i])
+			}
+		}
+	})
+
+	t.Run("structure mismatch", func(t *testing.T) {
+		var cfg strictConfig
+		errs := fieldErrors(t, UnmarshalYamlStrict([]byte("servers: {host: a}\nlabels: [a]\n"), &cfg))
+		if len(errs) != 2 || !strings.Contains(errs[0].Message, "expected sequence") {
+			t.Errorf("Unexpected errors %v", errs)
+		}
+	})
+
+	t.Run("merge keys are checked", func(t *testing.T) {
+		content := []byte("defaults: &d\n  host: a\n  bad: b\nservers:\n  - <<: *d\n")
+		var doc struct {
+			Defaults map[string]string `yaml:"defaults"`
+			Servers  []strictServer    `yaml:"servers"`
+		}
+		errs := fieldErrors(t, UnmarshalYamlStrict(content, &doc))
+		if len(errs) != 1 || errs[0].Path != "servers[0].bad" {
+			t.Errorf("Unexpected errors %v", errs)
+		}
+	})
+
+	t.Run("error on invalid yaml", func(t *testing.T) {
+		var cfg strictConfig
+		if err := UnmarshalYamlStrict([]byte("name: [unclosed"), &cfg); err == nil {
+			t.Error("Expected error for invalid yaml")
+		}
+	})
+
+	t.Run("error on decode failure", func(t *testing.T) {
+		var cfg strictConfig
+		if err := UnmarshalYamlStrict([]byte("match: \"[\""), &cfg); err == nil {
+			t.Error("Expected error for invalid regex")
+		}
+	})
+}
+
+func TestUnmarshalJsonStrict(t *testing.T) {
+	t.Run("valid document", func(t *testing.T) {
+		content := []byte(`{"name": "app", "Port": 8080, "ratio": 0.5, "region": "eu", "match": "a\/b"}`)
+		var cfg strictConfig
+		if err := UnmarshalJsonStrict(content, &cfg); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.Port != 8080 || cfg.Region != "eu" || cfg.Match.String() != "a/b" {
+			t.Errorf("Unexpected config %+v", cfg)
+		}
+	})
+
+	t.Run("all problems reported with positions", func(t *testing.T) {
+		content := []byte("{\n  \"name\": \"a\",\n  \"port\": \"80\",\n  \"nmae\": 1,\n  \"name\": \"b\",\n  \"servers\": [{\"host\": true}]\n}")
+		var cfg strictConfig
+		errs := fieldErrors(t, UnmarshalJsonStrict(content, &cfg))
+		if len(errs) != 4 {
+			t.Fatalf("Expected 4 errors, got %v", errs)
+		}
+		if errs[0].Path != "port" || errs[0].Line != 3 || errs[0].Column != 11 {
+			t.Errorf("Unexpected first error %+v", errs[0])
+		}
+		if errs[1].Path != "nmae" || errs[2].Path != "name" || errs[3].Path != "servers[0].host" {
+			t.Errorf("Unexpected errors %v", errs)
+		}
+	})
+
+	t.Run("embedded structs without tags are promoted", func(t *testing.T) {
+		var cfg struct {
+			strictServer
+			Port int `json:"port"`
+		}
+		if err := UnmarshalJsonStrict([]byte(`{"host": "a", "port": 1}`), &cfg); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.Host != "a" {
+			t.Errorf("Expected promoted host, got %+v", cfg)
+		}
+	})
+
+	t.Run("field names follow encoding/json", func(t *testing.T) {
+		type strictLabels map[string]string
+		type strictPrimary struct{ Host string }
+		type strictSecondary struct{ Host string }
+		var cfg struct {
+			strictLabels
+			strictPrimary
+			strictSecondary
+			Port string `json:"port"`
+		}
+		content := []byte(`{"port": 1, "host": "a", "env": "prod"}`)
+		errs := fieldErrors(t, UnmarshalJsonStrict(content, &cfg))
+		if len(errs) != 3 || errs[0].Path != "port" || errs[1].Message != `unknown field "host"` || errs[2].Message != `unknown field "env"` {
+			t.Errorf("Unexpected errors %v", errs)
+		}
+
+		var shadowed struct {
+			strictServer
+			Host int `json:"host"`
+		}
+		if err := UnmarshalJsonStrict([]byte(`{"host": 2}`), &shadowed); err != nil || shadowed.Host != 2 {
+			t.Errorf("Expected the shallower field to win, got %+v, %v", shadowed, err)
+		}
+	})
+
+	t.Run("error on invalid json", func(t *testing.T) {
+		var cfg strictConfig
+		for _, content := range []string{`{"name": }`, `{"name": "a"} {}`, ``} {
+			if err := UnmarshalJsonStrict([]byte(content), &cfg); err == nil {
+				t.Errorf("Expected error for %q", content)
+			}
+		}
+	})
+}
+
+func TestUnmarshalFileStrict(t *testing.T) {
+	t.Run("problems name the file", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(testFile, []byte("name: a\nnmae: b\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var cfg strictConfig
+		err := UnmarshalFileStrict(testFile, &cfg)
+		errs := fieldErrors(t, err)
+		if errs[0].File != testFile || !strings.Contains(err.Error(), "file: ["+testFile+"], line: [2], column: [1], field: [nmae]") {
+			t.Errorf("Unexpected error %v", err)
+		}
+	})
+
+	t.Run("json file checked by json rules", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(testFile, []byte("{\n  \"Name\": \"a\",\n  \"nmae\": \"b\"\n}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var cfg strictConfig
+		err := UnmarshalFileStrict(testFile, &cfg)
+		errs := fieldErrors(t, err)
+		if len(errs) != 1 || !strings.Contains(err.Error(), "file: ["+testFile+"], line: [3], column: [3], field: [nmae]") {
+			t.Errorf("Unexpected error %v", err)
+		}
+
+		var named struct {
+			ServerName string `json:"server_name" yaml:"servername"`
+		}
+		if err := os.WriteFile(testFile, []byte(`{"server_name": "a"}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := UnmarshalFileStrict(testFile, &named); err != nil || named.ServerName != "a" {
+			t.Errorf("Unexpected result %+v %v", named, err)
+		}
+	})
+
+	t.Run("json file", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(testFile, []byte(`{"name": "a"}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var cfg strictConfig
+		if err := UnmarshalJsonFileStrict(testFile, &cfg); err != nil || cfg.Name != "a" {
+			t.Errorf("Unexpected result %+v %v", cfg, err)
+		}
+	})
+
+	t.Run("error on non-existent file", func(t *testing.T) {
+		var cfg strictConfig
+		if err := UnmarshalFileStrict("nonexistent.yaml", &cfg); err == nil {
+			t.Error("Expected error for non-existent file")
+		}
+		if err := UnmarshalJsonFileStrict("nonexistent.json", &cfg); err == nil {
+			t.Error("Expected error for non-existent file")
+		}
+	})
+}
@@ -0,0 +1,801 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnmarshalToml decodes a TOML document into t. Tables decode like YAML mappings, so struct
+// fields are matched by their yaml names, and offset date-times decode into time.Time.
+// Local dates, times and date-times are kept as text.
+// Returns an error, naming the line, if the content is not valid TOML or cannot be decoded into t.
+func UnmarshalToml(content []byte, t interface{}) error {
+	tree, err := parseToml(content)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+	}
+	node, err := treeNode(tree)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+	}
+	if err := node.Decode(t); err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+	}
+	return nil
+}
+
+// MarshalToml encodes a struct or map as a TOML document. Fields are named by their yaml
+// tags, or their lowercased names, as UnmarshalToml reads them. Keys are sorted, nested maps
+// become [tables] and lists of maps become [[arrays of tables]]. Nil values are left out as
+// TOML has no null.
+// Returns an error if t is not a struct or map, or holds a value TOML cannot represent.
+func MarshalToml(t interface{}) ([]byte, error) {
+	tree, err := toYamlMap(t)
+	if err != nil {
+		return nil, err
+	}
+	bff := bytes.Buffer{}
+	if err := writeTomlTable(&bff, nil, tree); err != nil {
+		return nil, fmt.Errorf("error marshalling %T, with error %v", t, err)
+	}
+	return bff.Bytes(), nil
+}
+
+// treeNode builds a node from a tree of maps, lists and scalars. Unlike yaml's own encoder it
+// keeps whole floats such as 72.0 tagged as floats, so they do not decode as ints.
+func treeNode(value interface{}) (*yaml.Node, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, key := range sortedKeys(v) {
+			child, err := treeNode(v[key])
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, child)
+		}
+		return node, nil
+	case []interface{}:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, item := range v {
+			child, err := treeNode(item)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	case float64:
+		text := strconv.FormatFloat(v, 'g', -1, 64)
+		switch {
+		case math.IsInf(v, 1):
+			text = ".inf"
+		case math.IsInf(v, -1):
+			text = "-.inf"
+		case math.IsNaN(v):
+			text = ".nan"
+		case !strings.ContainsAny(text, ".e"):
+			text += ".0"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!float", Value: text}, nil
+	case time.Time:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!timestamp", Value: v.Format(time.RFC3339Nano)}, nil
+	}
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+type tomlParser struct {
+	content []byte
+	pos     int
+	line    int
+	// defined holds the headers of explicitly defined tables, to reject redefinitions.
+	defined map[string]bool
+}
+
+func parseToml(content []byte) (map[string]interface{}, error) {
+	p := &tomlParser{content: bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")), line: 1, defined: make(map[string]bool)}
+	root := make(map[string]interface{})
+	current := root
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return root, nil
+		}
+		var err error
+		if p.peek() == '[' {
+			current, err = p.header(root)
+		} else {
+			err = p.keyValue(current)
+		}
+		if err == nil {
+			err = p.endOfLine()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", p.line, err)
+		}
+	}
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.content)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.content[p.pos]
+}
+
+func (p *tomlParser) hasPrefix(prefix string) bool {
+	return bytes.HasPrefix(p.content[p.pos:], []byte(prefix))
+}
+
+// skipBlank skips spaces, tabs and comments, and with newlines also line breaks.
+func (p *tomlParser) skipBlank(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.pos++
+			p.line++
+		case c == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) endOfLine() error {
+	p.skipBlank(false)
+	if p.eof() {
+		return nil
+	}
+	if p.peek() != '\n' {
+		return fmt.Errorf("unexpected %q after value", p.peek())
+	}
+	return nil
+}
+
+// header parses a [table] or [[array of tables]] header and returns the table it opens.
+func (p *tomlParser) header(root map[string]interface{}) (map[string]interface{}, error) {
+	array := p.hasPrefix("[[")
+	p.pos++
+	if array {
+		p.pos++
+	}
+	keys, err := p.key()
+	if err != nil {
+		return nil, err
+	}
+	closing := "]"
+	if array {
+		closing = "]]"
+	}
+	p.skipBlank(false)
+	if !p.hasPrefix(closing) {
+		return nil, fmt.Errorf("expected %s to close the table header", closing)
+	}
+	p.pos += len(closing)
+
+	parent, err := tomlDescend(root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+	last := keys[len(keys)-1]
+	if array {
+		existing, exists := parent[last]
+		tables, ok := existing.([]interface{})
+		if exists && (!ok || !tomlTableArray(tables)) {
+			return nil, fmt.Errorf("key %q is already defined", strings.Join(keys, "."))
+		}
+		table := make(map[string]interface{})
+		parent[last] = append(tables, table)
+		return table, nil
+	}
+
+	name := strings.Join(escapeDottedSegments(keys), ".")
+	if p.defined[name] {
+		return nil, fmt.Errorf("table %q is defined twice", strings.Join(keys, "."))
+	}
+	p.defined[name] = true
+	return tomlDescend(root, keys)
+}
+
+// tomlDescend walks from table through keys, creating missing tables and entering the last
+// table of arrays of tables.
+func tomlDescend(table map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for _, key := range keys {
+		switch next := table[key].(type) {
+		case nil:
+			child := make(map[string]interface{})
+			table[key] = child
+			table = child
+		case map[string]interface{}:
+			table = next
+		case []interface{}:
+			if !tomlTableArray(next) || len(next) == 0 {
+				return nil, fmt.Errorf("key %q is not a table", key)
+			}
+			table = next[len(next)-1].(map[string]interface{})
+		default:
+			return nil, fmt.Errorf("key %q is not a table", key)
+		}
+	}
+	return table, nil
+}
+
+func tomlTableArray(items []interface{}) bool {
+	for _, item := range items {
+		if _, ok := item.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *tomlParser) keyValue(table map[string]interface{}) error {
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	p.skipBlank(false)
+	if p.peek() != '=' {
+		return fmt.Errorf("expected = after key %q", strings.Join(keys, "."))
+	}
+	p.pos++
+	p.skipBlank(false)
+	value, err := p.value()
+	if err != nil {
+		return err
+	}
+	parent, err := tomlDescend(table, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, exists := parent[last]; exists {
+		return fmt.Errorf("key %q is already defined", strings.Join(keys, "."))
+	}
+	parent[last] = value
+	return nil
+}
+
+// key parses a bare, quoted or dotted key into its parts.
+func (p *tomlParser) key() ([]string, error) {
+	keys := make([]string, 0, 1)
+	for {
+		p.skipBlank(false)
+		var key string
+		switch c := p.peek(); {
+		case c == '"':
+			s, err := p.basicString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		case c == '\'':
+			s, err := p.literalString()
+			if err != nil {
+				return nil, err
+			}
+			key = s
+		default:
+			start := p.pos
+			for !p.eof() && isTomlBareKeyByte(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, fmt.Errorf("expected a key, found %q", p.peek())
+			}
+			key = string(p.content[start:p.pos])
+		}
+		keys = append(keys, key)
+		p.skipBlank(false)
+		if p.peek() != '.' {
+			return keys, nil
+		}
+		p.pos++
+	}
+}
+
+func isTomlBareKeyByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) value() (interface{}, error) {
+	switch c := p.peek(); {
+	case p.eof():
+		return nil, fmt.Errorf("expected a value")
+	case p.hasPrefix(`"""`):
+		return p.multilineBasicString()
+	case c == '"':
+		return p.basicString()
+	case p.hasPrefix("'''"):
+		return p.multilineLiteralString()
+	case c == '\'':
+		return p.literalString()
+	case c == '[':
+		return p.array()
+	case c == '{':
+		return p.inlineTable()
+	case p.hasPrefix("true"):
+		p.pos += 4
+		return true, nil
+	case p.hasPrefix("false"):
+		p.pos += 5
+		return false, nil
+	}
+	return p.scalar()
+}
+
+var (
+	tomlDatePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	tomlTimePattern = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}(\.\d+)?$`)
+	tomlIntPattern  = regexp.MustCompile(`^[+-]?(0|[1-9](_?\d)*)$`)
+)
+
+// scalar parses a number, date or time.
+func (p *tomlParser) scalar() (interface{}, error) {
+	start := p.pos
+	for !p.eof() && strings.IndexByte("0123456789abcdefABCDEFxoinTZ+-_.:", p.peek()) >= 0 {
+		p.pos++
+	}
+	// A space may separate the date and time of a date-time.
+	if tomlDatePattern.Match(p.content[start:p.pos]) && p.peek() == ' ' && p.pos+3 < len(p.content) && p.content[p.pos+3] == ':' {
+		p.pos++
+		for !p.eof() && strings.IndexByte("0123456789Z+-.:", p.peek()) >= 0 {
+			p.pos++
+		}
+	}
+	token := string(p.content[start:p.pos])
+	if token == "" {
+		return nil, fmt.Errorf("unexpected %q", p.peek())
+	}
+
+	switch {
+	case token == "inf" || token == "+inf":
+		return math.Inf(1), nil
+	case token == "-inf":
+		return math.Inf(-1), nil
+	case token == "nan" || token == "+nan" || token == "-nan":
+		return math.NaN(), nil
+	case len(token) > 2 && token[0] == '0' && strings.IndexByte("xob", token[1]) >= 0:
+		base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[token[1]]
+		digits := token[2:]
+		if strings.HasPrefix(digits, "_") || strings.HasSuffix(digits, "_") || strings.Contains(digits, "__") {
+			return nil, fmt.Errorf("invalid number %q", token)
+		}
+		n, err := strconv.ParseInt(strings.ReplaceAll(digits, "_", ""), base, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", token)
+		}
+		return n, nil
+	case tomlIntPattern.MatchString(token):
+		n, err := strconv.ParseInt(strings.ReplaceAll(token, "_", ""), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", token)
+		}
+		return n, nil
+	case strings.Count(token, "-") >= 2 || strings.Contains(token, ":"):
+		return parseTomlDateTime(token)
+	}
+	if strings.Contains(token, "__") || strings.HasPrefix(strings.TrimLeft(token, "+-"), "_") || strings.HasSuffix(token, "_") || strings.Contains(token, "_.") || strings.Contains(token, "._") {
+		return nil, fmt.Errorf("invalid number %q", token)
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(token, "_", ""), 64)
+	if err != nil || strings.HasPrefix(strings.TrimLeft(token, "+-"), ".") || strings.HasSuffix(token, ".") {
+		return nil, fmt.Errorf("invalid value %q", token)
+	}
+	return f, nil
+}
+
+// parseTomlDateTime parses an offset date-time into a time.Time and validates local dates,
+// times and date-times, which are returned as text.
+func parseTomlDateTime(token string) (interface{}, error) {
+	normalized := strings.Replace(strings.Replace(token, " ", "T", 1), "t", "T", 1)
+	normalized = strings.Replace(normalized, "z", "Z", 1)
+	if t, err := time.Parse(time.RFC3339Nano, normalized); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05.999999999", "2006-01-02", "15:04:05.999999999"} {
+		if _, err := time.Parse(layout, normalized); err == nil {
+			return token, nil
+		}
+	}
+	if tomlTimePattern.MatchString(token) {
+		return token, nil
+	}
+	return nil, fmt.Errorf("invalid date-time %q", token)
+}
+
+func (p *tomlParser) array() (interface{}, error) {
+	p.pos++
+	items := make([]interface{}, 0)
+	for {
+		p.skipBlank(true)
+		if p.peek() == ']' {
+			p.pos++
+			return items, nil
+		}
+		item, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		p.skipBlank(true)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+		default:
+			return nil, fmt.Errorf("expected , or ] in array")
+		}
+	}
+}
+
+func (p *tomlParser) inlineTable() (interface{}, error) {
+	p.pos++
+	table := make(map[string]interface{})
+	p.skipBlank(false)
+	if p.peek() == '}' {
+		p.pos++
+		return table, nil
+	}
+	for {
+		if err := p.keyValue(table); err != nil {
+			return nil, err
+		}
+		p.skipBlank(false)
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return table, nil
+		default:
+			return nil, fmt.Errorf("expected , or } in inline table")
+		}
+	}
+}
+
+func (p *tomlParser) basicString() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", fmt.Errorf("unterminated string")
+		}
+		c := p.peek()
+		switch c {
+		case '"':
+			p.pos++
+			return b.String(), nil
+		case '\\':
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+func (p *tomlParser) multilineBasicString() (string, error) {
+	p.pos += 3
+	p.skipNewline()
+	var b strings.Builder
+	for {
+		switch {
+		case p.eof():
+			return "", fmt.Errorf("unterminated string")
+		case p.hasPrefix(`"""`):
+			p.pos += 3
+			// Up to two quotes may end the content right before the closing delimiter.
+			for i := 0; i < 2 && p.peek() == '"'; i++ {
+				b.WriteByte('"')
+				p.pos++
+			}
+			return b.String(), nil
+		case p.peek() == '\\':
+			rest := bytes.TrimLeft(p.content[p.pos+1:], " \t\r")
+			if len(rest) > 0 && rest[0] == '\n' {
+				// A line ending backslash trims the line break and following whitespace.
+				p.pos++
+				for !p.eof() && strings.IndexByte(" \t\r\n", p.peek()) >= 0 {
+					if p.peek() == '\n' {
+						p.line++
+					}
+					p.pos++
+				}
+				continue
+			}
+			if err := p.escape(&b); err != nil {
+				return "", err
+			}
+		default:
+			if p.peek() == '\n' {
+				p.line++
+			}
+			b.WriteByte(p.peek())
+			p.pos++
+		}
+	}
+}
+
+func (p *tomlParser) literalString() (string, error) {
+	p.pos++
+	start := p.pos
+	for !p.eof() && p.peek() != '\'' {
+		if p.peek() == '\n' {
+			return "", fmt.Errorf("unterminated string")
+		}
+		p.pos++
+	}
+	if p.eof() {
+		return "", fmt.Errorf("unterminated string")
+	}
+	p.pos++
+	return string(p.content[start : p.pos-1]), nil
+}
+
+func (p *tomlParser) multilineLiteralString() (string, error) {
+	p.pos += 3
+	p.skipNewline()
+	rest := p.content[p.pos:]
+	end := bytes.Index(rest, []byte("'''"))
+	if end < 0 {
+		return "", fmt.Errorf("unterminated string")
+	}
+	// Up to two quotes may end the content right before the closing delimiter.
+	for i := 0; i < 2 && end+3 < len(rest) && rest[end+3] == '\''; i++ {
+		end++
+	}
+	s := string(p.content[p.pos : p.pos+end])
+	p.line += strings.Count(s, "\n")
+	p.pos += end + 3
+	return s, nil
+}
+
+func (p *tomlParser) skipNewline() {
+	if p.hasPrefix("\r\n") {
+		p.pos += 2
+		p.line++
+	} else if p.peek() == '\n' {
+		p.pos++
+		p.line++
+	}
+}
+
+func (p *tomlParser) escape(b *strings.Builder) error {
+	if p.pos+1 >= len(p.content) {
+		return fmt.Errorf("unterminated escape")
+	}
+	c := p.content[p.pos+1]
+	p.pos += 2
+	switch c {
+	case 'b':
+		b.WriteByte('\b')
+	case 't':
+		b.WriteByte('\t')
+	case 'n':
+		b.WriteByte('\n')
+	case 'f':
+		b.WriteByte('\f')
+	case 'r':
+		b.WriteByte('\r')
+	case '"':
+		b.WriteByte('"')
+	case '\\':
+		b.WriteByte('\\')
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.content) {
+			return fmt.Errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(string(p.content[p.pos:p.pos+size]), 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return fmt.Errorf("invalid unicode escape \\%c%s", c, p.content[p.pos:p.pos+size])
+		}
+		b.WriteRune(rune(code))
+		p.pos += size
+	default:
+		return fmt.Errorf("invalid escape \\%c", c)
+	}
+	return nil
+}
+
+// writeTomlTable writes the plain keys of table, then its sub-tables and arrays of tables.
+func writeTomlTable(b *bytes.Buffer, path []string, table map[string]interface{}) error {
+	tables := make([]string, 0)
+	arrays := make([]string, 0)
+	for _, key := range sortedKeys(table) {
+		switch value := table[key].(type) {
+		case nil:
+			continue
+		case map[string]interface{}:
+			tables = append(tables, key)
+			continue
+		case []interface{}:
+			if len(value) > 0 && tomlTableArray(value) {
+				arrays = append(arrays, key)
+				continue
+			}
+		}
+		text, err := tomlValue(table[key])
+		if err != nil {
+			return fmt.Errorf("%s: %v", strings.Join(append(path, key), "."), err)
+		}
+		fmt.Fprintf(b, "%s = %s\n", tomlKey(key), text)
+	}
+
+	for _, key := range tables {
+		child := append(append([]string{}, path...), key)
+		tomlHeader(b, "[", child, "]")
+		if err := writeTomlTable(b, child, table[key].(map[string]interface{})); err != nil {
+			return err
+		}
+	}
+	for _, key := range arrays {
+		child := append(append([]string{}, path...), key)
+		for _, item := range table[key].([]interface{}) {
+			tomlHeader(b, "[[", child, "]]")
+			if err := writeTomlTable(b, child, item.(map[string]interface{})); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func tomlHeader(b *bytes.Buffer, open string, path []string, closing string) {
+	if b.Len() > 0 {
+		b.WriteByte('\n')
+	}
+	keys := make([]string, len(path))
+	for i, key := range path {
+		keys[i] = tomlKey(key)
+	}
+	fmt.Fprintf(b, "%s%s%s\n", open, strings.Join(keys, "."), closing)
+}
+
+func tomlKey(key string) string {
+	for i := 0; i < len(key); i++ {
+		if !isTomlBareKeyByte(key[i]) {
+			return tomlString(key)
+		}
+	}
+	if key == "" {
+		return `""`
+	}
+	return key
+}
+
+// tomlValue formats a value inline, with maps as inline tables.
+func tomlValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", fmt.Errorf("toml cannot represent null")
+	case string:
+		return tomlString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		return v.String(), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case []byte:
+		return tomlString(string(v)), nil
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			text, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, text)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]interface{}:
+		items := make([]string, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			if v[key] == nil {
+				continue
+			}
+			text, err := tomlValue(v[key])
+			if err != nil {
+				return "", err
+			}
+			items = append(items, tomlKey(key)+" = "+text)
+		}
+		if len(items) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(items, ", ") + " }", nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		return tomlString(rv.String()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() > math.MaxInt64 {
+			return "", fmt.Errorf("integer %d is out of range", rv.Uint())
+		}
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		switch {
+		case math.IsNaN(f):
+			return "nan", nil
+		case math.IsInf(f, 1):
+			return "inf", nil
+		case math.IsInf(f, -1):
+			return "-inf", nil
+		}
+		text := strconv.FormatFloat(f, 'g', -1, rv.Type().Bits())
+		if !strings.ContainsAny(text, ".eE") {
+			text += ".0"
+		}
+		return text, nil
+	}
+	return "", fmt.Errorf("unsupported type %T", value)
+}
+
+// tomlString quotes s as a TOML basic string.
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,218 @@
+package converters
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const tomlExample = `# This is a TOML document
+title = "TOML \"Example\"" # comment
+"quoted key" = 'C:\Users\nodejs'
+site."google.com" = true
+
+[owner]
+name = "Tom Preston-Werner"
+dob = 1979-05-27T07:32:00-08:00
+birthday = 1979-05-27
+alarm = 07:32:00
+local = 1979-05-27 07:32:00
+
+[database]
+enabled = true
+ports = [ 8000, 8001, 8002, ]
+data = [ ["delta", "phi"], [3.14] ]
+temp_targets = { cpu = 79.5, case = 72.0 }
+hex = 0xDEAD_BEEF
+oct = 0o755
+bin = 0b1101
+big = 1_000_000
+exp = -5e+22
+inf = -inf
+multi = """
+Roses are red \
+   Violets are blue\n"""
+raw = '''
+first line
+  second "line"'''
+
+[servers.alpha]
+ip = "10.0.0.1"
+
+[[products]]
+name = "Hammer"
+sku = 738594937
+
+[[products]]
+
+[[products]]
+name = "Nail"
+color = "gray"
+`
+
+func TestUnmarshalToml(t *testing.T) {
+	var doc map[string]interface{}
+	if err := UnmarshalToml([]byte(tomlExample), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["title"] != `TOML "Example"` || doc["quoted key"] != `C:\Users\nodejs` {
+		t.Errorf("Unexpected strings %q, %q", doc["title"], doc["quoted key"])
+	}
+	if !reflect.DeepEqual(doc["site"], map[string]interface{}{"google.com": true}) {
+		t.Errorf("Unexpected dotted key %v", doc["site"])
+	}
+
+	owner := doc["owner"].(map[string]interface{})
+	dob, ok := owner["dob"].(time.Time)
+	if !ok || !dob.Equal(time.Date(1979, 5, 27, 15, 32, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected offset date-time %v", owner["dob"])
+	}
+	if owner["alarm"] != "07:32:00" || owner["local"] != "1979-05-27 07:32:00" {
+		t.Errorf("Expected local times as text, got %v", owner)
+	}
+
+	db := doc["database"].(map[string]interface{})
+	expected := map[string]interface{}{
+		"enabled":      true,
+		"ports":        []interface{}{8000, 8001, 8002},
+		"data":         []interface{}{[]interface{}{"delta", "phi"}, []interface{}{3.14}},
+		"temp_targets": map[string]interface{}{"cpu": 79.5, "case": 72.0},
+		"hex":          3735928559,
+		"oct":          493,
+		"bin":          13,
+		"big":          1000000,
+		"exp":          -5e+22,
+		"multi":        "Roses are red Violets are blue\n",
+		"raw":          "first line\n  second \"line\"",
+	}
+	inf := db["inf"]
+	delete(db, "inf")
+	if !reflect.DeepEqual(db, expected) {
+		t.Errorf("Expected %v, got %v", expected, db)
+	}
+	if f, ok := inf.(float64); !ok || !math.IsInf(f, -1) {
+		t.Errorf("Expected -inf, got %v", inf)
+	}
+
+	products := doc["products"].([]interface{})
+	if len(products) != 3 || products[0].(map[string]interface{})["sku"] != 738594937 || len(products[1].(map[string]interface{})) != 0 {
+		t.Errorf("Unexpected array of tables %v", products)
+	}
+	if doc["servers"].(map[string]interface{})["alpha"].(map[string]interface{})["ip"] != "10.0.0.1" {
+		t.Errorf("Unexpected nested table %v", doc["servers"])
+	}
+
+	var typed struct {
+		Owner struct {
+			Name string    `yaml:"name"`
+			Dob  time.Time `yaml:"dob"`
+		} `yaml:"owner"`
+		Database struct {
+			Ports []int `yaml:"ports"`
+		} `yaml:"database"`
+	}
+	if err := UnmarshalToml([]byte(tomlExample), &typed); err != nil || typed.Owner.Name != "Tom Preston-Werner" || typed.Database.Ports[2] != 8002 {
+		t.Errorf("Unexpected struct %+v, %v", typed, err)
+	}
+}
+
+func TestUnmarshalTomlErrors(t *testing.T) {
+	for content, message := range map[string]string{
+		"a = 1\na = 2":             "line 2: key \"a\" is already defined",
+		"[a]\n[a]":                 "line 2: table \"a\" is defined twice",
+		"a = 1\n[a.b]":             "line 2: key \"a\" is not a table",
+		"a = \"open":               "line 1: unterminated string",
+		"a = 1 2":                  "line 1: unexpected '2' after value",
+		"a = [1, 2":                "expected , or ] in array",
+		"a = { b = 1 ":             "expected , or } in inline table",
+		"a = 1__0":                 "invalid number",
+		"a = 1979-13-45":           "invalid date-time",
+		"a = \"\\q\"":              "invalid escape \\q",
+		"= 1":                      "expected a key",
+		"a 1":                      "expected = after key",
+		"[a\nb = 1":                "expected ] to close the table header",
+		"a = [1]\n[[a]]":           "key \"a\" is already defined",
+		"a = .5":                   "invalid value",
+		"a = \"\\uD800\"":          "invalid unicode escape",
+		"a = \"\"\"never closed\n": "unterminated string",
+	} {
+		var doc map[string]interface{}
+		err := UnmarshalToml([]byte(content), &doc)
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Content %q: expected %q, got %v", content, message, err)
+		}
+	}
+}
+
+func TestMarshalToml(t *testing.T) {
+	type server struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	data := map[string]interface{}{
+		"title":   "line\n\"quoted\"\t\x01",
+		"ratio":   2.0,
+		"small":   float32(0.5),
+		"count":   uint8(3),
+		"enabled": true,
+		"empty":   nil,
+		"tags":    []string{"a", "b"},
+		"mixed":   []interface{}{1, map[string]interface{}{"k": "v"}},
+		"db":      map[string]interface{}{"host": "localhost", "pool": map[string]interface{}{"size": 5}},
+		"servers": []server{{Host: "a", Port: 1}, {Host: "b", Port: 2}},
+		"odd key": map[string]interface{}{},
+		"started": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	out, err := MarshalToml(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `count = 3
+enabled = true
+mixed = [1, { k = "v" }]
+ratio = 2.0
+small = 0.5
+started = "2024-01-02T03:04:05Z"
+tags = ["a", "b"]
+title = "line\n\"quoted\"\t\u0001"
+
+[db]
+host = "localhost"
+
+[db.pool]
+size = 5
+
+["odd key"]
+
+[[servers]]
+host = "a"
+port = 1
+
+[[servers]]
+host = "b"
+port = 2
+`
+	if string(out) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	var back map[string]interface{}
+	if err := UnmarshalToml(out, &back); err != nil || back["title"] != data["title"] || back["ratio"] != 2.0 {
+		t.Errorf("Expected a round trip, got %v, %v", back, err)
+	}
+
+	if _, err := MarshalToml([]int{1}); err == nil {
+		t.Error("Expected error for a list")
+	}
+	if _, err := MarshalToml(map[string]interface{}{"a": []interface{}{nil}}); err == nil || !strings.Contains(err.Error(), "a: toml cannot represent null") {
+		t.Errorf("Expected null error, got %v", err)
+	}
+	if _, err := MarshalToml(map[string]interface{}{"big": uint64(math.MaxUint64)}); err == nil {
+		t.Error("Expected range error")
+	}
+	if out, err := MarshalToml(map[string]interface{}{"nan": math.NaN(), "inf": math.Inf(-1)}); err != nil || string(out) != "inf = -inf\nnan = nan\n" {
+		t.Errorf("Unexpected special floats %q, %v", out, err)
+	}
+}
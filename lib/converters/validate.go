@@ -0,0 +1,247 @@
+package converters
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skhatri/go-fns/lib/types"
+)
+
+// Validate checks the struct pointed to by t against the rules in its `validate:"..."`
+// tags, recursing into nested structs, pointers, slices and maps. Rules are comma separated:
+//
+//	required      the field must not be zero; slices and maps must not be empty
+//	omitempty     skip the rules that follow when the field is zero
+//	min=N, max=N  bounds for numbers and durations, or for the length of strings,
+//	              slices and maps
+//	oneof=a b c   the field must equal one of the space separated values
+//	pattern=RE    strings must match the regular expression, compiled with types.Compile.
+//	              As the expression may contain commas, pattern must be the last rule.
+//
+// Rules apply to zero values too, so min=1 rejects 0 and oneof=a b rejects "". Put omitempty
+// first to let an optional field be left unset; nil pointers and interfaces, which hold no
+// value, are only checked by required. Returns FieldErrors naming the path of every field
+// that fails a rule.
+func Validate(t interface{}) error {
+	v := reflect.ValueOf(t)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return fmt.Errorf("error validating %T, expected a non-nil value", t)
+		}
+		v = v.Elem()
+	}
+	errs := make(FieldErrors, 0)
+	validateValue(v, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// UnmarshalFileValidated reads a file with UnmarshalFile, applies tag defaults with
+// ApplyDefaults and then checks the result with Validate.
+func UnmarshalFileValidated(file string, t interface{}) error {
+	if err := UnmarshalFile(file, t); err != nil {
+		return err
+	}
+	if err := ApplyDefaults(t); err != nil {
+		return err
+	}
+	return Validate(t)
+}
+
+func validateValue(v reflect.Value, path string, errs *FieldErrors) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			validateValue(v.Elem(), path, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateValue(v.Index(i), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			validateValue(v.MapIndex(key), joinFieldPath(path, fmt.Sprint(key.Interface())), errs)
+		}
+	case reflect.Struct:
+		if isTextValue(v.Type()) {
+			return
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() && !field.Anonymous {
+				continue
+			}
+			fieldValue := v.Field(i)
+			if !fieldValue.CanInterface() {
+				continue
+			}
+			fieldPath := structFieldPath(path, field)
+			if rules, ok := field.Tag.Lookup("validate"); ok {
+				for _, message := range checkRules(fieldValue, rules) {
+					*errs = append(*errs, &FieldError{Path: fieldPath, Message: message})
+				}
+			}
+			validateValue(fieldValue, fieldPath, errs)
+		}
+	}
+}
+
+// checkRules evaluates a validate tag against v and returns a message for every failed rule.
+func checkRules(v reflect.Value, tag string) []string {
+	messages := make([]string, 0)
+	zero := isEmptyValue(v)
+	for tag != "" {
+		name, arg := nextRule(&tag)
+		switch {
+		case name == "required":
+			if zero {
+				messages = append(messages, "is required")
+			}
+			continue
+		case name == "omitempty":
+			if zero {
+				return messages
+			}
+			continue
+		case isNilValue(v):
+			continue
+		}
+		if message := checkRule(v, name, arg); message != "" {
+			messages = append(messages, message)
+		}
+	}
+	return messages
+}
+
+// nextRule takes the first rule off tag and returns its name and argument. A pattern rule
+// takes the rest of the tag, commas included, as its expression.
+func nextRule(tag *string) (string, string) {
+	rest := strings.TrimLeft(*tag, " ")
+	if pattern, ok := strings.CutPrefix(rest, "pattern="); ok {
+		*tag = ""
+		return "pattern", pattern
+	}
+	rule, rest, _ := strings.Cut(rest, ",")
+	*tag = rest
+	name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+	return name, arg
+}
+
+// isNilValue reports whether v is a nil pointer or interface, possibly behind others.
+func isNilValue(v reflect.Value) bool {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return true
+		}
+		v = v.Elem()
+	}
+	return false
+}
+
+func checkRule(v reflect.Value, name string, arg string) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	switch name {
+	case "min", "max":
+		actual, bound, unit, err := ruleBounds(v, arg)
+		if err != nil {
+			return fmt.Sprintf("invalid rule %s=%s: %v", name, arg, err)
+		}
+		if name == "min" && actual < bound {
+			return fmt.Sprintf("must be at least %s%s", arg, unit)
+		}
+		if name == "max" && actual > bound {
+			return fmt.Sprintf("must be at most %s%s", arg, unit)
+		}
+	case "oneof":
+		actual := fmt.Sprint(v.Interface())
+		for _, option := range strings.Fields(arg) {
+			if actual == option {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of [%s]", strings.Join(strings.Fields(arg), ", "))
+	case "pattern":
+		re, err := compileRulePattern(arg)
+		if err != nil {
+			return fmt.Sprintf("invalid rule pattern=%s: %v", arg, err)
+		}
+		if !re.MatchString(fmt.Sprint(v.Interface())) {
+			return fmt.Sprintf("must match pattern %s", arg)
+		}
+	default:
+		return fmt.Sprintf("unknown rule %q", name)
+	}
+	return ""
+}
+
+// ruleBounds returns the value a min or max rule compares against its bound, along with
+// the parsed bound and a unit suffix for messages.
+func ruleBounds(v reflect.Value, arg string) (float64, float64, string, error) {
+	if v.Type() == durationType {
+		bound, err := time.ParseDuration(arg)
+		return float64(v.Int()), float64(bound), "", err
+	}
+	bound, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return 0, 0, "", err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), bound, "", nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), bound, "", nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), bound, "", nil
+	case reflect.String:
+		return float64(len([]rune(v.String()))), bound, " characters", nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), bound, " items", nil
+	}
+	return 0, 0, "", fmt.Errorf("not supported for %s", v.Type())
+}
+
+// isEmptyValue reports whether v is zero, treating empty slices and maps as zero too.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// rulePatterns caches the compiled expressions of pattern rules, and the errors of invalid
+// ones, so each is compiled once.
+var rulePatterns = struct {
+	sync.Mutex
+	compiled map[string]rulePattern
+}{compiled: make(map[string]rulePattern)}
+
+type rulePattern struct {
+	re  *types.Regex
+	err error
+}
+
+func compileRulePattern(pattern string) (*types.Regex, error) {
+	rulePatterns.Lock()
+	defer rulePatterns.Unlock()
+	compiled, ok := rulePatterns.compiled[pattern]
+	if !ok {
+		compiled.re, compiled.err = types.Compile(pattern)
+		rulePatterns.compiled[pattern] = compiled
+	}
+	return compiled.re, compiled.err
+}
@@ -0,0 +1,200 @@
+package converters
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type validateServer struct {
+	Host string `yaml:"host" validate:"required"`
+	Port int    `yaml:"port" validate:"min=1,max=65535"`
+}
+
+type validateConfig struct {
+	Name     string                    `yaml:"name" validate:"required,min=3,pattern=^[a-z]+(-[a-z]+){0,2}$"`
+	Mode     string                    `yaml:"mode" validate:"oneof=dev prod"`
+	Timeout  time.Duration             `yaml:"timeout" validate:"max=1m"`
+	Tags     []string                  `yaml:"tags" validate:"max=2"`
+	Servers  []validateServer          `yaml:"servers" validate:"required"`
+	Backends map[string]validateServer `yaml:"backends"`
+	Optional *validateServer           `yaml:"optional"`
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("valid config", func(t *testing.T) {
+		cfg := validateConfig{
+			Name:    "my-app",
+			Mode:    "prod",
+			Timeout: 30 * time.Second,
+			Servers: []validateServer{{Host: "a", Port: 80}},
+		}
+		if err := Validate(&cfg); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("every failure is reported with its path", func(t *testing.T) {
+		cfg := validateConfig{
+			Name:    "A",
+			Mode:    "test",
+			Timeout: time.Hour,
+			Tags:    []string{"a", "b", "c"},
+			Backends: map[string]validateServer{
+				"b": {Host: "b", Port: 70000},
+				"a": {Port: 1},
+			},
+		}
+		err := Validate(&cfg)
+		var errs FieldErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("Expected FieldErrors, got %v", err)
+		}
+		expected := []struct{ path, message string }{
+			{"name", "must be at least 3 characters"},
+			{"name", "must match pattern ^[a-z]+(-[a-z]+){0,2}$"},
+			{"mode", "must be one of [dev, prod]"},
+			{"timeout", "must be at most 1m"},
+			{"tags", "must be at most 2 items"},
+			{"servers", "is required"},
+			{"backends.a.host", "is required"},
+			{"backends.b.port", "must be at most 65535"},
+		}
+		if len(errs) != len(expected) {
+			t.Fatalf("Expected %d errors, got %d:\n%v", len(expected), len(errs), errs)
+		}
+		for i, exp := range expected {
+			if errs[i].Path != exp.path || errs[i].Message != exp.message {
+				t.Errorf("Expected %s: %s, got %v", exp.path, exp.message, errs[i])
+			}
+		}
+	})
+
+	t.Run("invalid rules are reported", func(t *testing.T) {
+		var cfg struct {
+			A string `validate:"min=x"`
+			B string `validate:"pattern=["`
+			C bool   `validate:"max=1"`
+			D string `validate:"unknown"`
+		}
+		cfg.A, cfg.B, cfg.C, cfg.D = "a", "b", true, "d"
+		var errs FieldErrors
+		if !errors.As(Validate(&cfg), &errs) || len(errs) != 4 {
+			t.Errorf("Expected 4 errors, got %v", errs)
+		}
+	})
+
+	t.Run("pattern after spaces takes the rest of the tag", func(t *testing.T) {
+		var cfg struct {
+			Hosts string `validate:"required, pattern=^[a-z]+(, [a-z]+)*$"`
+			Ports string `validate:"min=1,  pattern= [0-9]{2,4}"`
+		}
+		cfg.Hosts, cfg.Ports = "a, b", "80"
+		var errs FieldErrors
+		if !errors.As(Validate(&cfg), &errs) || len(errs) != 1 || errs[0].Path != "Ports" || errs[0].Message != "must match pattern  [0-9]{2,4}" {
+			t.Errorf("Expected only the space in the Ports pattern to fail, got %v", errs)
+		}
+		re, err := compileRulePattern("^[a-z]+(, [a-z]+)*$")
+		if again, _ := compileRulePattern("^[a-z]+(, [a-z]+)*$"); err != nil || again != re {
+			t.Errorf("Expected the compiled pattern to be reused, got %v, %v", again, err)
+		}
+	})
+
+	t.Run("zero values are checked unless omitempty", func(t *testing.T) {
+		port := 0
+		var cfg struct {
+			Port     int     `validate:"min=1"`
+			Mode     string  `validate:"oneof=a b"`
+			Tags     []int   `validate:"min=1"`
+			Unknown  int     `validate:"gte=1"`
+			Optional string  `validate:"omitempty,min=3"`
+			Short    string  `validate:"omitempty,min=3"`
+			Unset    *int    `validate:"min=1"`
+			Zero     *int    `validate:"min=1"`
+			Nested   *string `validate:"omitempty,oneof=x"`
+		}
+		cfg.Short, cfg.Zero = "ab", &port
+		var errs FieldErrors
+		if !errors.As(Validate(&cfg), &errs) {
+			t.Fatalf("Expected FieldErrors, got %v", errs)
+		}
+		expected := []struct{ path, message string }{
+			{"Port", "must be at least 1"},
+			{"Mode", "must be one of [a, b]"},
+			{"Tags", "must be at least 1 items"},
+			{"Unknown", `unknown rule "gte"`},
+			{"Short", "must be at least 3 characters"},
+			{"Zero", "must be at least 1"},
+		}
+		if len(errs) != len(expected) {
+			t.Fatalf("Expected %d errors, got %d:\n%v", len(expected), len(errs), errs)
+		}
+		for i, exp := range expected {
+			if errs[i].Path != exp.path || errs[i].Message != exp.message {
+				t.Errorf("Expected %s: %s, got %v", exp.path, exp.message, errs[i])
+			}
+		}
+	})
+
+	t.Run("error on nil", func(t *testing.T) {
+		var cfg *validateConfig
+		if err := Validate(cfg); err == nil {
+			t.Error("Expected error for nil pointer")
+		}
+	})
+}
+
+func TestUnmarshalFileValidated(t *testing.T) {
+	type config struct {
+		Name string `yaml:"name" validate:"required"`
+		Port int    `yaml:"port" default:"8080" validate:"min=1024"`
+	}
+
+	t.Run("defaults then validation", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(testFile, []byte("name: app\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var cfg config
+		if err := UnmarshalFileValidated(testFile, &cfg); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if cfg.Port != 8080 {
+			t.Errorf("Expected default port, got %d", cfg.Port)
+		}
+	})
+
+	t.Run("validation failure", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(testFile, []byte("port: 80\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var cfg config
+		var errs FieldErrors
+		if err := UnmarshalFileValidated(testFile, &cfg); !errors.As(err, &errs) || len(errs) != 2 {
+			t.Errorf("Expected 2 field errors, got %v", err)
+		}
+	})
+
+	t.Run("error on non-existent file", func(t *testing.T) {
+		var cfg config
+		if err := UnmarshalFileValidated("nonexistent.yaml", &cfg); err == nil {
+			t.Error("Expected error for non-existent file")
+		}
+	})
+
+	t.Run("error on invalid default", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(testFile, []byte("name: app\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var cfg struct {
+			Port int `yaml:"port" default:"http"`
+		}
+		if err := UnmarshalFileValidated(testFile, &cfg); err == nil {
+			t.Error("Expected error for invalid default")
+		}
+	})
+}
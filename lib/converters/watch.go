@@ -0,0 +1,241 @@
+package converters
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultWatchInterval is how often a Watcher polls its file unless WatchOptions.Interval
+// says otherwise.
+const DefaultWatchInterval = 2 * time.Second
+
+// DefaultWatchBuffer is the capacity of a Watcher's Changes channel unless
+// WatchOptions.Buffer says otherwise.
+const DefaultWatchBuffer = 16
+
+// WatchOptions controls Watch.
+type WatchOptions[T any] struct {
+	// Interval between polls. Zero means DefaultWatchInterval.
+	Interval time.Duration
+	// Validate, when set, checks a decoded value after tag defaults and validation have been
+	// applied. A value it rejects is reported as an error and never swapped in.
+	Validate func(value T) error
+	// OnChange, when set, is called with every change after it is published on the Changes
+	// channel, from the polling goroutine or from Reload. It runs without holding the
+	// watcher's locks, so it may call Current, Reload or Close; changes from a Reload running
+	// at the same time as a poll may be passed to it concurrently.
+	OnChange func(change WatchChange[T])
+	// Buffer is the capacity of the Changes channel. Zero means DefaultWatchBuffer.
+	Buffer int
+}
+
+// WatchChange reports a reload of a watched file. On success Old and New hold the previous
+// and the new value; when the file cannot be read, decoded or validated Err is set and both
+// hold the value still in use.
+type WatchChange[T any] struct {
+	Old T
+	New T
+	Err error
+}
+
+// Watcher keeps the decoded contents of a file current, polling it for changes so it works
+// on any file system, including container volumes where inotify events are not delivered.
+//
+//	w, err := converters.Watch[Config]("config.yaml", converters.WatchOptions[Config]{})
+//	if err != nil {
+//		return err
+//	}
+//	defer w.Close()
+//	go func() {
+//		for change := range w.Changes() {
+//			if change.Err != nil {
+//				log.Println(change.Err)
+//			}
+//		}
+//	}()
+//	cfg := w.Current()
+type Watcher[T any] struct {
+	path    string
+	opts    WatchOptions[T]
+	changes chan WatchChange[T]
+	stop    chan struct{}
+	once    sync.Once
+
+	mu      sync.RWMutex
+	current T
+
+	// reloading serialises reloads and the publishing of their changes; the fields below
+	// are only used while holding it.
+	reloading sync.Mutex
+	// digest of the content last decoded, so rewrites of identical content are not reloads.
+	digest [sha256.Size]byte
+	// lastErr suppresses reporting the same error on every poll until the file changes.
+	lastErr string
+	closed  bool
+}
+
+// Watch decodes path into T, as UnmarshalFileValidated does, and starts polling the file.
+// When its content changes it is decoded into a fresh T, defaults are applied and it is
+// validated, and only a value that passes replaces the current one. Every reload and every
+// new error is published on Changes and passed to OnChange; an error leaves the current value
+// in place, so a half-written or invalid file never reaches the service.
+// Returns an error if the file cannot be decoded and validated initially.
+func Watch[T any](path string, opts WatchOptions[T]) (*Watcher[T], error) {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultWatchInterval
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = DefaultWatchBuffer
+	}
+	w := &Watcher[T]{
+		path:    path,
+		opts:    opts,
+		changes: make(chan WatchChange[T], opts.Buffer),
+		stop:    make(chan struct{}),
+	}
+	content, err := readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("file: [%s], error: [%w]", path, err)
+	}
+	value, err := w.load(content)
+	if err != nil {
+		return nil, err
+	}
+	w.current, w.digest = value, sha256.Sum256(content)
+	go w.poll()
+	return w, nil
+}
+
+// Current returns the value decoded from the last valid version of the file.
+func (w *Watcher[T]) Current() T {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.current
+}
+
+// Changes returns the channel changes are published on. It is closed by Close. When the
+// channel is full the oldest pending change is dropped, so a slow reader never stalls reloads.
+func (w *Watcher[T]) Changes() <-chan WatchChange[T] {
+	return w.changes
+}
+
+// Reload checks the file immediately instead of waiting for the next poll and reports
+// whether a new value was swapped in.
+// Returns the error that kept the current value in place, if any.
+func (w *Watcher[T]) Reload() (bool, error) {
+	return w.check()
+}
+
+// Close stops polling and closes the Changes channel, waiting for a reload in progress to
+// finish. It is safe to call more than once, including from OnChange.
+func (w *Watcher[T]) Close() error {
+	w.once.Do(func() {
+		close(w.stop)
+		w.reloading.Lock()
+		defer w.reloading.Unlock()
+		w.closed = true
+		close(w.changes)
+	})
+	return nil
+}
+
+func (w *Watcher[T]) poll() {
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check reloads the file when its content differs from the last decoded version and passes
+// the change, if any, to OnChange once the reload has released its lock.
+func (w *Watcher[T]) check() (bool, error) {
+	change, swapped, err := w.reload()
+	if change != nil && w.opts.OnChange != nil {
+		w.opts.OnChange(*change)
+	}
+	return swapped, err
+}
+
+// reload decodes the file when its content differs from the last decoded version, swaps in
+// a valid value and publishes the change on the Changes channel.
+func (w *Watcher[T]) reload() (*WatchChange[T], bool, error) {
+	w.reloading.Lock()
+	defer w.reloading.Unlock()
+	if w.closed {
+		return nil, false, fmt.Errorf("file: [%s], error: [watcher is closed]", w.path)
+	}
+	content, err := readFile(w.path)
+	if err != nil {
+		err = fmt.Errorf("file: [%s], error: [%w]", w.path, err)
+	} else if sha256.Sum256(content) == w.digest {
+		w.lastErr = ""
+		return nil, false, nil
+	}
+	var value T
+	if err == nil {
+		value, err = w.load(content)
+	}
+	old := w.Current()
+	if err != nil {
+		// Report an error once rather than on every poll while the file stays broken.
+		if err.Error() == w.lastErr {
+			return nil, false, err
+		}
+		w.lastErr = err.Error()
+		change := WatchChange[T]{Old: old, New: old, Err: err}
+		w.publish(change)
+		return &change, false, err
+	}
+
+	w.mu.Lock()
+	w.current = value
+	w.mu.Unlock()
+	w.digest, w.lastErr = sha256.Sum256(content), ""
+	change := WatchChange[T]{Old: old, New: value}
+	w.publish(change)
+	return &change, true, nil
+}
+
+// load decodes, defaults and validates content into a fresh T.
+func (w *Watcher[T]) load(content []byte) (T, error) {
+	var value T
+	if err := unmarshalFileContent(w.path, content, &value); err != nil {
+		return value, err
+	}
+	if err := ApplyDefaults(&value); err != nil {
+		return value, fmt.Errorf("file: [%s], error: [%w]", w.path, err)
+	}
+	if err := Validate(&value); err != nil {
+		return value, fmt.Errorf("file: [%s], error: [%w]", w.path, err)
+	}
+	if w.opts.Validate != nil {
+		if err := w.opts.Validate(value); err != nil {
+			return value, fmt.Errorf("file: [%s], error: [%w]", w.path, err)
+		}
+	}
+	return value, nil
+}
+
+// publish sends a change on the Changes channel, dropping the oldest pending change when
+// the channel is full.
+func (w *Watcher[T]) publish(change WatchChange[T]) {
+	for {
+		select {
+		case w.changes <- change:
+			return
+		default:
+		}
+		select {
+		case <-w.changes:
+		default:
+		}
+	}
+}
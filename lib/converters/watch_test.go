@@ -0,0 +1,180 @@
+package converters
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type watchConfig struct {
+	Host string `yaml:"host" validate:"required"`
+	Port int    `yaml:"port" default:"8080"`
+}
+
+func TestWatchReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.yaml")
+	writeTestFile(t, file, "host: a\n")
+
+	var mu sync.Mutex
+	seen := make([]WatchChange[watchConfig], 0)
+	w, err := Watch[watchConfig](file, WatchOptions[watchConfig]{
+		Interval: time.Hour,
+		Validate: func(c watchConfig) error {
+			if c.Port == 1 {
+				return errors.New("port 1 is reserved")
+			}
+			return nil
+		},
+		OnChange: func(change WatchChange[watchConfig]) {
+			mu.Lock()
+			defer mu.Unlock()
+			seen = append(seen, change)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if w.Current() != (watchConfig{Host: "a", Port: 8080}) {
+		t.Errorf("Expected defaults applied, got %+v", w.Current())
+	}
+
+	if changed, err := w.Reload(); changed || err != nil {
+		t.Errorf("Expected no change for the same content, got %v, %v", changed, err)
+	}
+
+	writeTestFile(t, file, "host: b\nport: 9090\n")
+	if changed, err := w.Reload(); !changed || err != nil {
+		t.Fatalf("Expected a change, got %v, %v", changed, err)
+	}
+	change := <-w.Changes()
+	if change.Err != nil || change.Old.Host != "a" || change.New != (watchConfig{Host: "b", Port: 9090}) {
+		t.Errorf("Unexpected change %+v", change)
+	}
+
+	writeTestFile(t, file, "port: 9091\n")
+	if changed, err := w.Reload(); changed || err == nil || !strings.Contains(err.Error(), "required") {
+		t.Errorf("Expected validation error, got %v, %v", changed, err)
+	}
+	writeTestFile(t, file, "host: c\nport: 1\n")
+	if _, err := w.Reload(); err == nil || !strings.Contains(err.Error(), "port 1 is reserved") {
+		t.Errorf("Expected custom validation error, got %v", err)
+	}
+	// The same failure is reported once, not on every poll.
+	w.Reload()
+	writeTestFile(t, file, "host: [")
+	w.Reload()
+	if w.Current().Host != "b" {
+		t.Errorf("Expected invalid values to be kept out, got %+v", w.Current())
+	}
+
+	mu.Lock()
+	if len(seen) != 4 || seen[1].Err == nil || seen[1].New.Host != "b" || seen[3].Err == nil {
+		t.Errorf("Unexpected callbacks %+v", seen)
+	}
+	mu.Unlock()
+	if len(w.Changes()) != 3 {
+		t.Errorf("Expected 3 pending changes, got %d", len(w.Changes()))
+	}
+}
+
+func TestWatchPolling(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.json")
+	writeTestFile(t, file, `{"host": "a"}`)
+	w, err := Watch[map[string]interface{}](file, WatchOptions[map[string]interface{}]{Interval: 10 * time.Millisecond, Buffer: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, file, `{"host": "b"}`)
+	select {
+	case change := <-w.Changes():
+		if change.Err != nil || change.New["host"] != "b" || change.Old["host"] != "a" {
+			t.Errorf("Unexpected change %+v", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a change to be published")
+	}
+	if w.Current()["host"] != "b" {
+		t.Errorf("Expected the new value, got %v", w.Current())
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	if _, open := <-w.Changes(); open {
+		t.Error("Expected the channel to be closed")
+	}
+	if _, err := w.Reload(); err == nil || !strings.Contains(err.Error(), "watcher is closed") {
+		t.Errorf("Expected closed error, got %v", err)
+	}
+}
+
+func TestWatchErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Watch[watchConfig](filepath.Join(dir, "missing.yaml"), WatchOptions[watchConfig]{}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+	file := filepath.Join(dir, "app.yaml")
+	writeTestFile(t, file, "port: 1\n")
+	if _, err := Watch[watchConfig](file, WatchOptions[watchConfig]{}); err == nil || !strings.Contains(err.Error(), "field: [host], error: [is required]") {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+}
+
+func TestWatchCallbackReentry(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.yaml")
+	writeTestFile(t, file, "host: a\n")
+	reloaded := make(chan error, 1)
+	closed := make(chan error, 1)
+	var watcher atomic.Pointer[Watcher[watchConfig]]
+	w, err := Watch[watchConfig](file, WatchOptions[watchConfig]{
+		Interval: 10 * time.Millisecond,
+		OnChange: func(change WatchChange[watchConfig]) {
+			_, err := watcher.Load().Reload()
+			if change.New.Host != "c" {
+				reloaded <- err
+				return
+			}
+			if err == nil {
+				err = watcher.Load().Close()
+			}
+			closed <- err
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher.Store(w)
+
+	// A callback run by Reload may reload again.
+	writeTestFile(t, file, "host: b\n")
+	if changed, err := w.Reload(); !changed || err != nil {
+		t.Fatalf("Expected a change, got %v, %v", changed, err)
+	}
+	if err := <-reloaded; err != nil {
+		t.Errorf("Unexpected reload error %v", err)
+	}
+
+	// A callback run by the polling goroutine may reload and close the watcher.
+	writeTestFile(t, file, "host: c\n")
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Errorf("Unexpected error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the callback to reload and close without deadlocking")
+	}
+	if _, open := <-w.Changes(); !open {
+		t.Error("Expected the change to stay readable after Close")
+	}
+	if _, err := w.Reload(); err == nil || !strings.Contains(err.Error(), "watcher is closed") {
+		t.Errorf("Expected closed error, got %v", err)
+	}
+}
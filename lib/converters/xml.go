@@ -0,0 +1,419 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultXmlAttributePrefix marks the keys of generic trees that hold XML attributes.
+	DefaultXmlAttributePrefix = "@"
+	// DefaultXmlTextKey is the key of generic trees that holds the text of an element that
+	// also has attributes or child elements.
+	DefaultXmlTextKey = "#text"
+	// DefaultXmlRoot names the root element written for lists and for maps with more than
+	// one key.
+	DefaultXmlRoot = "root"
+	// DefaultXmlItem names the elements written for the items of a list root that have no
+	// element name of their own, such as scalars and maps.
+	DefaultXmlItem = "item"
+)
+
+var xmlNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+// XmlOptions controls how XML maps to and from generic trees. The zero value uses the defaults.
+type XmlOptions struct {
+	// AttributePrefix is prepended to attribute names. Empty means DefaultXmlAttributePrefix.
+	AttributePrefix string
+	// TextKey holds the text of elements with attributes or children. Empty means DefaultXmlTextKey.
+	TextKey string
+	// Root names the element that wraps a list, or a map with several keys, on output. Empty
+	// means DefaultXmlRoot.
+	Root string
+	// Item names the elements of a list root's scalars and maps on output. Empty means
+	// DefaultXmlItem.
+	Item string
+}
+
+func (opts XmlOptions) withDefaults() XmlOptions {
+	if opts.AttributePrefix == "" {
+		opts.AttributePrefix = DefaultXmlAttributePrefix
+	}
+	if opts.TextKey == "" {
+		opts.TextKey = DefaultXmlTextKey
+	}
+	if opts.Root == "" {
+		opts.Root = DefaultXmlRoot
+	}
+	if opts.Item == "" {
+		opts.Item = DefaultXmlItem
+	}
+	return opts
+}
+
+// UnmarshalXml decodes XML into t using the default XmlOptions. Structs and other typed values
+// are decoded with encoding/xml, following their xml tags. Maps and interfaces receive the same
+// generic tree YAML and JSON decode into, keyed by the root element:
+//
+//	<server id="1"><host>a</host><port>80</port><port>81</port></server>
+//
+// becomes {"server": {"@id": 1, "host": "a", "port": [80, 81]}}. Elements holding only text
+// become scalars typed like plain YAML, repeated elements become lists and namespace prefixes
+// are dropped. An element appearing once is never a list, so a single <port> gives a scalar.
+// Slices receive the child elements of the root, one item each, as MarshalXml writes lists.
+// Returns an error if the content is not well-formed XML or cannot be decoded into t.
+func UnmarshalXml(content []byte, t interface{}) error {
+	return UnmarshalXmlWithOptions(content, t, XmlOptions{})
+}
+
+// UnmarshalXmlWithOptions decodes XML into t like UnmarshalXml, naming attributes and text
+// in generic trees as opts says.
+func UnmarshalXmlWithOptions(content []byte, t interface{}, opts XmlOptions) error {
+	v := reflect.ValueOf(t)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return fmt.Errorf("error unmarshalling to %T, expected a non-nil pointer", t)
+	}
+	if isXmlList(v.Elem().Type()) {
+		if err := unmarshalXmlList(content, v.Elem(), opts.withDefaults()); err != nil {
+			return fmt.Errorf("error unmarshalling to %T, with error %w", t, err)
+		}
+		return nil
+	}
+	if kind := v.Elem().Kind(); kind != reflect.Map && kind != reflect.Interface {
+		if err := xml.Unmarshal(content, t); err != nil {
+			return fmt.Errorf("error unmarshalling to %T, with error %w", t, err)
+		}
+		return nil
+	}
+	tree, err := parseXml(content, opts.withDefaults())
+	if err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %w", t, err)
+	}
+	if err := plainScalarNode(tree).Decode(t); err != nil {
+		return fmt.Errorf("error unmarshalling to %T, with error %v", t, err)
+	}
+	return nil
+}
+
+// MarshalXml encodes t as an indented XML document using the default XmlOptions. Structs are
+// encoded with encoding/xml, following their xml tags. Maps, converted with ToMap, are written
+// with their single key as the root element, or wrapped in DefaultXmlRoot when they have
+// several or their single value is a list; keys with the attribute prefix become attributes,
+// lists become repeated elements and keys are sorted. Lists are wrapped in DefaultXmlRoot
+// with one child element per item: structs named as encoding/xml names them, and other
+// items as DefaultXmlItem elements.
+// Returns an error if t cannot be encoded or a key is not a valid XML name.
+func MarshalXml(t interface{}) ([]byte, error) {
+	return MarshalXmlWithOptions(t, XmlOptions{})
+}
+
+// MarshalXmlWithOptions encodes t like MarshalXml, reading attributes and text of maps and
+// naming the root as opts says.
+func MarshalXmlWithOptions(t interface{}, opts XmlOptions) ([]byte, error) {
+	opts = opts.withDefaults()
+	bff := bytes.Buffer{}
+	bff.WriteString(xml.Header)
+
+	v := reflect.ValueOf(t)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.IsValid() && isXmlList(v.Type()) {
+		encoder := xml.NewEncoder(&bff)
+		encoder.Indent("", "  ")
+		if err := writeXmlList(encoder, v, opts); err != nil {
+			return nil, fmt.Errorf("error marshalling %T, with error %v", t, err)
+		}
+		bff.WriteByte('\n')
+		return bff.Bytes(), nil
+	}
+	if v.Kind() != reflect.Map {
+		out, err := xml.MarshalIndent(t, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling %T, with error %v", t, err)
+		}
+		bff.Write(out)
+		bff.WriteByte('\n')
+		return bff.Bytes(), nil
+	}
+
+	tree, err := ToMap(t)
+	if err != nil {
+		return nil, err
+	}
+	name, value := opts.Root, interface{}(tree)
+	if len(tree) == 1 {
+		for key, item := range tree {
+			if _, isList := item.([]interface{}); !isList {
+				name, value = key, item
+			}
+		}
+	}
+	encoder := xml.NewEncoder(&bff)
+	encoder.Indent("", "  ")
+	if err := writeXmlElement(encoder, name, value, opts); err != nil {
+		return nil, fmt.Errorf("error marshalling %T, with error %v", t, err)
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, fmt.Errorf("error marshalling %T, with error %v", t, err)
+	}
+	bff.WriteByte('\n')
+	return bff.Bytes(), nil
+}
+
+// isXmlList reports whether t is written as a list root: a slice or array other than bytes.
+func isXmlList(t reflect.Type) bool {
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8
+}
+
+// isXmlGeneric reports whether values of t are decoded from generic trees rather than by
+// encoding/xml: maps and interfaces.
+func isXmlGeneric(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Map || t.Kind() == reflect.Interface
+}
+
+// writeXmlList writes the items of list v as the children of an opts.Root element. Structs
+// are encoded by encoding/xml under their own names, other items as opts.Item elements.
+func writeXmlList(encoder *xml.Encoder, v reflect.Value, opts XmlOptions) error {
+	start := xml.StartElement{Name: xml.Name{Local: opts.Root}}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i)
+		for (item.Kind() == reflect.Pointer || item.Kind() == reflect.Interface) && !item.IsNil() {
+			item = item.Elem()
+		}
+		if item.Kind() == reflect.Struct && !isTextValue(item.Type()) {
+			if err := encoder.Encode(item.Interface()); err != nil {
+				return err
+			}
+			continue
+		}
+		value, err := toMapValue(item, "json")
+		if err != nil {
+			return err
+		}
+		if err := writeXmlElement(encoder, opts.Item, value, opts); err != nil {
+			return err
+		}
+	}
+	if err := encoder.EncodeToken(start.End()); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+// unmarshalXmlList decodes each child element of the root of content into a new item of the
+// slice or array target. Maps and interfaces receive generic trees, other items are decoded
+// by encoding/xml.
+func unmarshalXmlList(content []byte, target reflect.Value, opts XmlOptions) error {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	itemType := target.Type().Elem()
+	items := make([]reflect.Value, 0)
+	rooted, open := false, false
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if !open {
+				if rooted {
+					return fmt.Errorf("line %d: unexpected second root element <%s>", xmlLine(decoder, content), token.Name.Local)
+				}
+				rooted, open = true, true
+				continue
+			}
+			item := reflect.New(itemType)
+			if isXmlGeneric(itemType) {
+				value, err := parseXmlElement(decoder, token, opts)
+				if err != nil {
+					return err
+				}
+				if err := plainScalarNode(value).Decode(item.Interface()); err != nil {
+					return err
+				}
+			} else if err := decoder.DecodeElement(item.Interface(), &token); err != nil {
+				return err
+			}
+			items = append(items, item.Elem())
+		case xml.EndElement:
+			open = false
+		case xml.CharData:
+			if !open && len(bytes.TrimSpace(token)) > 0 {
+				return fmt.Errorf("line %d: unexpected text outside the root element", xmlLine(decoder, content))
+			}
+		}
+	}
+	if !rooted {
+		return fmt.Errorf("no root element")
+	}
+	switch target.Kind() {
+	case reflect.Slice:
+		list := reflect.MakeSlice(target.Type(), 0, len(items))
+		target.Set(reflect.Append(list, items...))
+	default:
+		for i := 0; i < target.Len(); i++ {
+			if i < len(items) {
+				target.Index(i).Set(items[i])
+			} else {
+				target.Index(i).Set(reflect.Zero(itemType))
+			}
+		}
+	}
+	return nil
+}
+
+// parseXml reads the root element of content into a generic tree keyed by its name.
+func parseXml(content []byte, opts XmlOptions) (map[string]interface{}, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	var tree map[string]interface{}
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if tree != nil {
+				return nil, fmt.Errorf("line %d: unexpected second root element <%s>", xmlLine(decoder, content), token.Name.Local)
+			}
+			value, err := parseXmlElement(decoder, token, opts)
+			if err != nil {
+				return nil, err
+			}
+			tree = map[string]interface{}{token.Name.Local: value}
+		case xml.CharData:
+			if len(bytes.TrimSpace(token)) > 0 {
+				return nil, fmt.Errorf("line %d: unexpected text outside the root element", xmlLine(decoder, content))
+			}
+		}
+	}
+	if tree == nil {
+		return nil, fmt.Errorf("no root element")
+	}
+	return tree, nil
+}
+
+func parseXmlElement(decoder *xml.Decoder, start xml.StartElement, opts XmlOptions) (interface{}, error) {
+	element := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		element[opts.AttributePrefix+attr.Name.Local] = attr.Value
+	}
+	text := strings.Builder{}
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			child, err := parseXmlElement(decoder, token, opts)
+			if err != nil {
+				return nil, err
+			}
+			name := token.Name.Local
+			switch existing := element[name].(type) {
+			case nil:
+				element[name] = child
+			case []interface{}:
+				element[name] = append(existing, child)
+			default:
+				element[name] = []interface{}{existing, child}
+			}
+		case xml.CharData:
+			text.Write(token)
+		case xml.EndElement:
+			trimmed := strings.TrimSpace(text.String())
+			if len(element) == 0 {
+				return trimmed, nil
+			}
+			if trimmed != "" {
+				element[opts.TextKey] = trimmed
+			}
+			return element, nil
+		}
+	}
+}
+
+// xmlLine returns the line the decoder has read up to.
+func xmlLine(decoder *xml.Decoder, content []byte) int {
+	line, _ := offsetPosition(content, int(decoder.InputOffset()))
+	return line
+}
+
+func writeXmlElement(encoder *xml.Encoder, name string, value interface{}, opts XmlOptions) error {
+	if items, ok := value.([]interface{}); ok {
+		for _, item := range items {
+			if err := writeXmlElement(encoder, name, item, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if !xmlNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid element name %q", name)
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	element, isMap := value.(map[string]interface{})
+	if !isMap {
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		if value != nil {
+			if err := encoder.EncodeToken(xml.CharData(csvCell(value))); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	}
+
+	children := make([]string, 0, len(element))
+	for _, key := range sortedKeys(element) {
+		switch {
+		case key == opts.TextKey:
+		case strings.HasPrefix(key, opts.AttributePrefix):
+			attr := strings.TrimPrefix(key, opts.AttributePrefix)
+			if !xmlNamePattern.MatchString(attr) {
+				return fmt.Errorf("invalid attribute name %q", attr)
+			}
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: attr}, Value: csvCell(element[key])})
+		default:
+			children = append(children, key)
+		}
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	if text, ok := element[opts.TextKey]; ok && text != nil {
+		if err := encoder.EncodeToken(xml.CharData(csvCell(text))); err != nil {
+			return err
+		}
+	}
+	for _, key := range children {
+		if err := writeXmlElement(encoder, key, element[key], opts); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
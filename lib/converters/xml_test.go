@@ -0,0 +1,270 @@
+package converters
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/skhatri/go-fns/lib/types"
+)
+
+const xmlExample = `<?xml version="1.0" encoding="UTF-8"?>
+<!-- vendor feed -->
+<catalog xmlns="urn:example" xmlns:v="urn:vendor" version="2">
+  <title>Parts &amp; tools</title>
+  <item id="1" v:sku="A-1">
+    <name>Hammer</name>
+    <price currency="USD">9.50</price>
+    <tag>tools</tag>
+    <tag>steel</tag>
+  </item>
+  <item id="2">
+    <name>Nail</name>
+    <empty/>
+  </item>
+  <note>
+    mixed <b>bold</b> text
+  </note>
+</catalog>
+`
+
+func TestUnmarshalXmlGeneric(t *testing.T) {
+	var doc map[string]interface{}
+	if err := UnmarshalXml([]byte(xmlExample), &doc); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"catalog": map[string]interface{}{
+			"@version": 2,
+			"title":    "Parts & tools",
+			"item": []interface{}{
+				map[string]interface{}{
+					"@id":   1,
+					"@sku":  "A-1",
+					"name":  "Hammer",
+					"price": map[string]interface{}{"@currency": "USD", "#text": 9.5},
+					"tag":   []interface{}{"tools", "steel"},
+				},
+				map[string]interface{}{
+					"@id":   2,
+					"name":  "Nail",
+					"empty": "",
+				},
+			},
+			"note": map[string]interface{}{"b": "bold", "#text": "mixed  text"},
+		},
+	}
+	if !reflect.DeepEqual(doc, expected) {
+		t.Errorf("Expected %v, got %v", expected, doc)
+	}
+
+	var custom interface{}
+	if err := UnmarshalXmlWithOptions([]byte(`<a id="1">x<b>2</b></a>`), &custom, XmlOptions{AttributePrefix: "-", TextKey: "_"}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(custom, map[string]interface{}{"a": map[string]interface{}{"-id": 1, "_": "x", "b": 2}}) {
+		t.Errorf("Unexpected custom tree %v", custom)
+	}
+}
+
+type xmlCatalog struct {
+	XMLName xml.Name `xml:"catalog"`
+	Version int      `xml:"version,attr"`
+	Title   string   `xml:"title"`
+	Items   []struct {
+		ID   int      `xml:"id,attr"`
+		Name string   `xml:"name"`
+		Tags []string `xml:"tag"`
+	} `xml:"item"`
+}
+
+func TestUnmarshalXmlStruct(t *testing.T) {
+	var catalog xmlCatalog
+	if err := UnmarshalXml([]byte(xmlExample), &catalog); err != nil {
+		t.Fatal(err)
+	}
+	if catalog.Version != 2 || catalog.Title != "Parts & tools" || len(catalog.Items) != 2 || catalog.Items[0].Tags[1] != "steel" || catalog.Items[1].ID != 2 {
+		t.Errorf("Unexpected struct %+v", catalog)
+	}
+}
+
+func TestUnmarshalXmlErrors(t *testing.T) {
+	var doc map[string]interface{}
+	for content, message := range map[string]string{
+		"<a><b></a>":    "element <b> closed by </a>",
+		"<a/><b/>":      "line 1: unexpected second root element <b>",
+		"text<a/>":      "unexpected text outside the root element",
+		"<!-- only -->": "no root element",
+		"<a>":           "unexpected EOF",
+	} {
+		if err := UnmarshalXml([]byte(content), &doc); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Content %q: expected %q, got %v", content, message, err)
+		}
+	}
+	var syntaxErr *xml.SyntaxError
+	if err := UnmarshalXml([]byte("<catalog>\n<b></catalog>"), &xmlCatalog{}); !errors.As(err, &syntaxErr) || syntaxErr.Line != 2 {
+		t.Errorf("Expected xml.SyntaxError, got %v", err)
+	}
+	if err := UnmarshalXml([]byte("<a/>"), doc); err == nil {
+		t.Error("Expected error for a non-pointer")
+	}
+}
+
+func TestMarshalXml(t *testing.T) {
+	out, err := MarshalXml(map[string]interface{}{
+		"catalog": map[string]interface{}{
+			"@version": 2,
+			"title":    "Parts & tools",
+			"item": []interface{}{
+				map[string]interface{}{"@id": 1, "name": "Hammer", "tag": []string{"a", "b"}},
+				map[string]interface{}{"@id": 2, "price": map[string]interface{}{"@currency": "USD", "#text": 9.5}, "none": nil},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<catalog version="2">
+  <item id="1">
+    <name>Hammer</name>
+    <tag>a</tag>
+    <tag>b</tag>
+  </item>
+  <item id="2">
+    <none></none>
+    <price currency="USD">9.5</price>
+  </item>
+  <title>Parts &amp; tools</title>
+</catalog>
+`
+	if string(out) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	var back map[string]interface{}
+	if err := UnmarshalXml(out, &back); err != nil || back["catalog"].(map[string]interface{})["title"] != "Parts & tools" {
+		t.Errorf("Expected a round trip, got %v, %v", back, err)
+	}
+
+	if out, err := MarshalXml(map[string]interface{}{"a": 1, "b": "x"}); err != nil || !strings.Contains(string(out), "<root>\n  <a>1</a>\n  <b>x</b>\n</root>") {
+		t.Errorf("Expected a root element, got %s, %v", out, err)
+	}
+	if out, err := MarshalXmlWithOptions(map[string]interface{}{"a": 1, "b": 2}, XmlOptions{Root: "config"}); err != nil || !strings.Contains(string(out), "<config>") {
+		t.Errorf("Expected a custom root element, got %s, %v", out, err)
+	}
+	if _, err := MarshalXml(map[string]interface{}{"bad key": 1}); err == nil || !strings.Contains(err.Error(), `invalid element name "bad key"`) {
+		t.Errorf("Expected name error, got %v", err)
+	}
+	if _, err := MarshalXml(map[string]interface{}{"a": map[string]interface{}{"@1": 1}}); err == nil || !strings.Contains(err.Error(), `invalid attribute name "1"`) {
+		t.Errorf("Expected attribute error, got %v", err)
+	}
+}
+
+func TestMarshalXmlStruct(t *testing.T) {
+	type credentials struct {
+		XMLName  xml.Name     `xml:"credentials"`
+		User     string       `xml:"user,attr"`
+		Password string       `xml:"password" secret:"true"`
+		Token    types.Secret `xml:"token"`
+	}
+	out, err := MarshalXml(&credentials{User: "admin", Password: "hunter2", Token: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<credentials user="admin">
+  <password>hunter2</password>
+  <token>abc</token>
+</credentials>
+`
+	if string(out) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out)
+	}
+	if _, err := MarshalXml(make(chan int)); err == nil {
+		t.Error("Expected marshal error")
+	}
+}
+
+func TestConvertXml(t *testing.T) {
+	out, err := Convert([]byte(`<server id="1"><host>a</host><port>80</port></server>`), "", "yaml", ConvertOptions{})
+	if err != nil || string(out) != "server:\n  '@id': 1\n  host: a\n  port: 80\n" {
+		t.Errorf("Unexpected yaml %q, %v", out, err)
+	}
+	out, err = Convert([]byte("server:\n  '@id': 1\n  host: a\n"), "yaml", "text/xml", ConvertOptions{})
+	if err != nil || string(out) != "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<server id=\"1\">\n  <host>a</host>\n</server>\n" {
+		t.Errorf("Unexpected xml %q, %v", out, err)
+	}
+}
+
+func TestXmlListRoundTrip(t *testing.T) {
+	type server struct {
+		XMLName xml.Name `xml:"server"`
+		ID      int      `xml:"id,attr"`
+		Host    string   `xml:"host"`
+	}
+	servers := []server{{ID: 1, Host: "a"}, {ID: 2, Host: "b"}}
+	file := filepath.Join(t.TempDir(), "servers.xml")
+	if err := MarshalFile(servers, file); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(file)
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<root>
+  <server id="1">
+    <host>a</host>
+  </server>
+  <server id="2">
+    <host>b</host>
+  </server>
+</root>
+`
+	if string(content) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, content)
+	}
+	var decoded []server
+	if err := UnmarshalFile(file, &decoded); err != nil || len(decoded) != 2 || decoded[0].Host != "a" || decoded[1].ID != 2 {
+		t.Errorf("Expected %+v, got %+v, %v", servers, decoded, err)
+	}
+
+	out, err := Convert([]byte(`[1, 2]`), "json", "xml", ConvertOptions{})
+	if err != nil || string(out) != "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<root>\n  <item>1</item>\n  <item>2</item>\n</root>\n" {
+		t.Errorf("Unexpected xml %q, %v", out, err)
+	}
+	var numbers []int
+	if err := UnmarshalXml(out, &numbers); err != nil || !reflect.DeepEqual(numbers, []int{1, 2}) {
+		t.Errorf("Expected [1 2], got %v, %v", numbers, err)
+	}
+	out, err = MarshalXmlWithOptions([]interface{}{map[string]interface{}{"@id": 1, "name": "a"}, "b"}, XmlOptions{Root: "list", Item: "entry"})
+	if err != nil || string(out) != "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<list>\n  <entry id=\"1\">\n    <name>a</name>\n  </entry>\n  <entry>b</entry>\n</list>\n" {
+		t.Errorf("Unexpected xml %q, %v", out, err)
+	}
+	var generic []interface{}
+	if err := UnmarshalXml(out, &generic); err != nil || !reflect.DeepEqual(generic, []interface{}{map[string]interface{}{"@id": 1, "name": "a"}, "b"}) {
+		t.Errorf("Unexpected items %v, %v", generic, err)
+	}
+	var single [1]int
+	if err := UnmarshalXml([]byte("<root><item>7</item></root>"), &single); err != nil || single[0] != 7 {
+		t.Errorf("Expected an array item, got %v, %v", single, err)
+	}
+
+	out, err = MarshalXml(map[string]interface{}{"port": []int{80, 443}})
+	if err != nil || string(out) != "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<root>\n  <port>80</port>\n  <port>443</port>\n</root>\n" {
+		t.Errorf("Expected a single list key to be wrapped, got %q, %v", out, err)
+	}
+
+	for content, message := range map[string]string{
+		"<a></a><b></b>":              "unexpected second root element <b>",
+		"<a></a>text":                 "unexpected text outside the root element",
+		"":                            "no root element",
+		"<root><item>x</item></root>": "strconv.ParseInt",
+	} {
+		if err := UnmarshalXml([]byte(content), &numbers); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Content %q: expected %q, got %v", content, message, err)
+		}
+	}
+}
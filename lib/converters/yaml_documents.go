@@ -0,0 +1,113 @@
+package converters
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/skhatri/go-fns/lib/fs"
+	"gopkg.in/yaml.v3"
+)
+
+// UnmarshalYamlNodes splits a YAML stream on its "---" document separators and returns
+// the parsed document nodes in order. Empty documents, such as the one produced by a
+// trailing separator, are skipped.
+// Returns an error, naming the 1-based document index, if any document cannot be parsed.
+func UnmarshalYamlNodes(content []byte) ([]*yaml.Node, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	nodes := make([]*yaml.Node, 0)
+	for index := 1; ; index++ {
+		node := &yaml.Node{}
+		err := decoder.Decode(node)
+		if errors.Is(err, io.EOF) {
+			return nodes, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("document: [%d], error: [%w]", index, err)
+		}
+		if isEmptyDocument(node) {
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+}
+
+// UnmarshalYamlDocuments decodes every document of a multi-document YAML stream into T,
+// so Kubernetes-style manifests keep everything after the first "---".
+// Returns an error, naming the 1-based document index, if any document cannot be decoded.
+func UnmarshalYamlDocuments[T any](content []byte) ([]T, error) {
+	nodes, err := UnmarshalYamlNodes(content)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]T, 0, len(nodes))
+	for i, node := range nodes {
+		var item T
+		if err := node.Decode(&item); err != nil {
+			return nil, fmt.Errorf("document: [%d], error: [error unmarshalling to %T, with error %w]", i+1, &item, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// UnmarshalYamlDocumentsFile reads a file and decodes each of its YAML documents into T.
+// Returns an error if the file cannot be read or any document cannot be decoded.
+func UnmarshalYamlDocumentsFile[T any](file string) ([]T, error) {
+	content, err := readFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("file: [%s], error: [%w]", file, err)
+	}
+	items, err := UnmarshalYamlDocuments[T](content)
+	if err != nil {
+		return nil, fmt.Errorf("file: [%s], error: [%w]", file, err)
+	}
+	return items, nil
+}
+
+// MarshalYamlDocuments marshals each item as its own YAML document, separated by "---".
+// Items may also be *yaml.Node values, such as those returned by UnmarshalYamlNodes.
+// Returns an error if any item cannot be marshaled.
+func MarshalYamlDocuments[T any](items []T) ([]byte, error) {
+	bff := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&bff)
+	for i, item := range items {
+		if err := encoder.Encode(item); err != nil {
+			return nil, fmt.Errorf("document: [%d], error: [%w]", i+1, err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return bff.Bytes(), nil
+}
+
+// MarshalToYamlDocumentsFile marshals each item as its own YAML document and writes the
+// resulting stream to a file, replacing it atomically with permission 0644.
+// Returns an error if the data cannot be marshaled or if the file cannot be written.
+func MarshalToYamlDocumentsFile[T any](items []T, path string) error {
+	return MarshalToYamlDocumentsFileWithOptions(items, path, fs.WriteOptions{})
+}
+
+// MarshalToYamlDocumentsFileWithOptions marshals each item as its own YAML document and
+// writes the stream to a file atomically, with the mode, backup and directory creation
+// given in opts.
+// Returns an error if the data cannot be marshaled or if the file cannot be written.
+func MarshalToYamlDocumentsFileWithOptions[T any](items []T, path string, opts fs.WriteOptions) error {
+	data, err := MarshalYamlDocuments(items)
+	if err != nil {
+		return err
+	}
+	return fs.WriteFileAtomicWithOptions(path, data, opts)
+}
+
+// isEmptyDocument reports whether a document node holds nothing but an implicit null,
+// as produced by "---" separators with no content between them.
+func isEmptyDocument(node *yaml.Node) bool {
+	if node.Kind != yaml.DocumentNode || len(node.Content) != 1 {
+		return false
+	}
+	content := node.Content[0]
+	return content.Kind == yaml.ScalarNode && content.Tag == "!!null" && content.Value == ""
+}
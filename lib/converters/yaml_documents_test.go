@@ -0,0 +1,165 @@
+package converters
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const manifests = `---
+name: first
+value: 1
+---
+# an empty document is skipped
+---
+name: second
+value: 2
+---
+`
+
+func TestUnmarshalYamlDocuments(t *testing.T) {
+	t.Run("decode every document", func(t *testing.T) {
+		items, err := UnmarshalYamlDocuments[TestStruct]([]byte(manifests))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(items) != 2 {
+			t.Fatalf("Expected 2 documents, got %d", len(items))
+		}
+		if items[0].Name != "first" || items[1].Value != 2 {
+			t.Errorf("Unexpected documents %v", items)
+		}
+	})
+
+	t.Run("decode into generic maps", func(t *testing.T) {
+		items, err := UnmarshalYamlDocuments[map[string]interface{}]([]byte("a: 1\n---\nb: 2\n"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(items) != 2 || items[1]["b"] != 2 {
+			t.Errorf("Unexpected documents %v", items)
+		}
+	})
+
+	t.Run("empty input", func(t *testing.T) {
+		items, err := UnmarshalYamlDocuments[TestStruct](nil)
+		if err != nil || len(items) != 0 {
+			t.Errorf("Expected no documents and no error, got %v %v", items, err)
+		}
+	})
+
+	t.Run("error names the failing document", func(t *testing.T) {
+		_, err := UnmarshalYamlDocuments[TestStruct]([]byte("name: a\n---\nvalue: invalid\n"))
+		if err == nil || !strings.Contains(err.Error(), "document: [2]") {
+			t.Errorf("Expected error for document 2, got %v", err)
+		}
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			t.Errorf("Expected the decode error to be wrapped, got %v", err)
+		}
+	})
+
+	t.Run("error on invalid yaml", func(t *testing.T) {
+		_, err := UnmarshalYamlDocuments[TestStruct]([]byte("name: a\n---\n[unclosed\n"))
+		if err == nil {
+			t.Error("Expected error for invalid yaml")
+		}
+	})
+}
+
+func TestUnmarshalYamlNodes(t *testing.T) {
+	nodes, err := UnmarshalYamlNodes([]byte(manifests))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("Expected 2 nodes, got %d", len(nodes))
+	}
+	if nodes[0].Kind != yaml.DocumentNode || nodes[0].Content[0].Kind != yaml.MappingNode {
+		t.Errorf("Expected mapping document, got kind %v", nodes[0].Kind)
+	}
+}
+
+func TestUnmarshalYamlDocumentsFile(t *testing.T) {
+	t.Run("read documents from file", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "manifests.yaml")
+		if err := os.WriteFile(testFile, []byte(manifests), 0644); err != nil {
+			t.Fatal(err)
+		}
+		items, err := UnmarshalYamlDocumentsFile[TestStruct](testFile)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(items) != 2 {
+			t.Errorf("Expected 2 documents, got %d", len(items))
+		}
+	})
+
+	t.Run("error on non-existent file", func(t *testing.T) {
+		_, err := UnmarshalYamlDocumentsFile[TestStruct]("nonexistent.yaml")
+		if err == nil {
+			t.Error("Expected error for non-existent file")
+		}
+	})
+
+	t.Run("error on invalid document", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "invalid.yaml")
+		if err := os.WriteFile(testFile, []byte("value: invalid"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := UnmarshalYamlDocumentsFile[TestStruct](testFile)
+		if err == nil {
+			t.Error("Expected error for invalid document")
+		}
+	})
+}
+
+func TestMarshalToYamlDocumentsFile(t *testing.T) {
+	t.Run("write and read back", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "out.yaml")
+		items := []TestStruct{{Name: "a", Value: 1}, {Name: "b", Value: 2}}
+		if err := MarshalToYamlDocumentsFile(items, testFile); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		content, err := os.ReadFile(testFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Count(string(content), "---") != 1 {
+			t.Errorf("Expected one separator, got %q", content)
+		}
+		result, err := UnmarshalYamlDocuments[TestStruct](content)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result) != 2 || result[1] != items[1] {
+			t.Errorf("Expected %v, got %v", items, result)
+		}
+	})
+
+	t.Run("nodes round trip with comments", func(t *testing.T) {
+		nodes, err := UnmarshalYamlNodes([]byte("# keep me\na: 1\n---\nb: 2\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := MarshalYamlDocuments(nodes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "# keep me") {
+			t.Errorf("Expected comment to be preserved, got %q", data)
+		}
+	})
+
+	t.Run("error on marshal failure", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "out.yaml")
+		err := MarshalToYamlDocumentsFile([]interface{}{TestStruct{}, errorMarshaler{}}, testFile)
+		if err == nil || !strings.Contains(err.Error(), "document: [2]") {
+			t.Errorf("Expected error for document 2, got %v", err)
+		}
+	})
+}
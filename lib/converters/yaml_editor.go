@@ -0,0 +1,551 @@
+package converters
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/skhatri/go-fns/lib/fs"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultYamlIndent is the indentation used when re-encoding a document that has no
+// nesting to learn from.
+const DefaultYamlIndent = 2
+
+// YamlEditor edits YAML documents in place on their yaml.v3 node trees. Paths are dotted,
+// as for Get. Set, Delete and Node work on the first document; use Document for the others.
+//
+// When the edits only replace scalar values, such as bumping an image tag, saving splices
+// the new values into the original text and keeps everything else byte for byte. Any other
+// change, such as adding or deleting keys, re-encodes the documents with yaml.v3: comments,
+// key order, anchors, aliases and quoting are kept and nested blocks use the indentation
+// width detected in the original, but blank lines are dropped, sequences are indented
+// under their key and comment spacing is normalised.
+type YamlEditor struct {
+	file      string
+	mode      os.FileMode
+	indent    int
+	documents []*yaml.Node
+	// source is the content parsed, and original its documents as parsed, to find the
+	// scalars that changed since.
+	source   []byte
+	original []*yaml.Node
+}
+
+// YamlDocument edits one document of a YamlEditor.
+type YamlDocument struct {
+	node *yaml.Node
+}
+
+// OpenYamlEditor reads a YAML file, which may hold several "---" separated documents, for
+// editing.
+// Returns an error if the file cannot be read or parsed.
+func OpenYamlEditor(file string) (*YamlEditor, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, fmt.Errorf("file: [%s], error: [%w]", file, err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("file: [%s], error: [%w]", file, err)
+	}
+	editor, err := NewYamlEditor(content)
+	if err != nil {
+		return nil, fmt.Errorf("file: [%s], error: [%w]", file, err)
+	}
+	editor.file = file
+	editor.mode = info.Mode().Perm()
+	return editor, nil
+}
+
+// NewYamlEditor parses YAML content for editing.
+// Returns an error, naming the 1-based document index, if any document cannot be parsed.
+func NewYamlEditor(content []byte) (*YamlEditor, error) {
+	documents, err := parseYamlEditorDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+	original, err := parseYamlEditorDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+	return &YamlEditor{
+		indent:    detectYamlIndent(content),
+		documents: documents,
+		source:    bytes.Clone(content),
+		original:  original,
+	}, nil
+}
+
+// parseYamlEditorDocuments parses the documents of content, skipping empty ones without
+// comments.
+func parseYamlEditorDocuments(content []byte) ([]*yaml.Node, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	documents := make([]*yaml.Node, 0)
+	for index := 1; ; index++ {
+		node := &yaml.Node{}
+		err := decoder.Decode(node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("document: [%d], error: [%w]", index, err)
+		}
+		if isEmptyDocument(node) && node.HeadComment == "" && node.Content[0].HeadComment == "" {
+			continue
+		}
+		documents = append(documents, node)
+	}
+	return documents, nil
+}
+
+// Documents returns the number of documents being edited.
+func (e *YamlEditor) Documents() int {
+	return len(e.documents)
+}
+
+// Document returns the 0-based index document for editing.
+// Returns an error if there is no such document.
+func (e *YamlEditor) Document(index int) (*YamlDocument, error) {
+	if index < 0 || index >= len(e.documents) {
+		return nil, fmt.Errorf("document: [%d], error: [%w, editor holds %d documents]", index, ErrPathNotFound, len(e.documents))
+	}
+	return &YamlDocument{node: e.documents[index]}, nil
+}
+
+// first returns the first document, creating an empty mapping document for empty content.
+func (e *YamlEditor) first() *YamlDocument {
+	if len(e.documents) == 0 {
+		e.documents = append(e.documents, &yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		})
+	}
+	return &YamlDocument{node: e.documents[0]}
+}
+
+// Node returns the node at path in the first document.
+// Returns an error wrapping ErrPathNotFound if the path does not exist.
+func (e *YamlEditor) Node(path string) (*yaml.Node, error) {
+	return e.first().Node(path)
+}
+
+// Get decodes the value at path in the first document into t.
+// Returns an error if the path does not exist or the value cannot be decoded.
+func (e *YamlEditor) Get(path string, t interface{}) error {
+	return e.first().Get(path, t)
+}
+
+// Set stores value at path in the first document. See YamlDocument.Set.
+func (e *YamlEditor) Set(path string, value interface{}) error {
+	return e.first().Set(path, value)
+}
+
+// Delete removes the key or item at path in the first document.
+// Returns an error wrapping ErrPathNotFound if the path does not exist.
+func (e *YamlEditor) Delete(path string) error {
+	return e.first().Delete(path)
+}
+
+// Bytes renders the edited documents, splicing changed scalars into the original text when
+// nothing else changed, see YamlEditor.
+// Returns an error if the documents cannot be encoded.
+func (e *YamlEditor) Bytes() ([]byte, error) {
+	if content, ok := e.splice(); ok {
+		return content, nil
+	}
+	bff := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&bff)
+	encoder.SetIndent(e.indent)
+	for i, document := range e.documents {
+		if err := encoder.Encode(document); err != nil {
+			return nil, fmt.Errorf("document: [%d], error: [%w]", i+1, err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return bff.Bytes(), nil
+}
+
+// yamlScalarEdit is a scalar whose value changed since it was parsed.
+type yamlScalarEdit struct {
+	original *yaml.Node
+	edited   *yaml.Node
+	flow     bool
+}
+
+// splice returns the original content with the changed scalars replaced in place, or false
+// when anything but scalar values changed or a scalar cannot be replaced in place.
+func (e *YamlEditor) splice() ([]byte, bool) {
+	if len(e.documents) != len(e.original) {
+		return nil, false
+	}
+	edits := make([]yamlScalarEdit, 0)
+	for i, document := range e.documents {
+		if !collectYamlEdits(e.original[i], document, false, &edits) {
+			return nil, false
+		}
+	}
+	content := e.source
+	// Replace from the end so the offsets of earlier scalars stay valid.
+	sort.Slice(edits, func(i, j int) bool {
+		a, b := edits[i].original, edits[j].original
+		return a.Line > b.Line || a.Line == b.Line && a.Column > b.Column
+	})
+	for _, edit := range edits {
+		start, end, ok := yamlScalarSpan(content, edit)
+		if !ok {
+			return nil, false
+		}
+		text, ok := inlineYamlScalar(edit.edited, edit.flow)
+		if !ok {
+			return nil, false
+		}
+		content = append(append(append([]byte{}, content[:start]...), text...), content[end:]...)
+	}
+	return content, true
+}
+
+// collectYamlEdits compares an edited node tree with the tree originally parsed and adds the
+// scalars whose value, tag or style changed to edits. It reports false when anything else
+// differs: the kind or number of nodes, anchors, aliases or comments.
+func collectYamlEdits(original *yaml.Node, edited *yaml.Node, flow bool, edits *[]yamlScalarEdit) bool {
+	if original.Kind != edited.Kind || len(original.Content) != len(edited.Content) || original.Anchor != edited.Anchor ||
+		original.HeadComment != edited.HeadComment || original.LineComment != edited.LineComment ||
+		original.FootComment != edited.FootComment {
+		return false
+	}
+	switch original.Kind {
+	case yaml.AliasNode:
+		return original.Value == edited.Value
+	case yaml.ScalarNode:
+		if original.Value != edited.Value || original.Tag != edited.Tag || original.Style != edited.Style {
+			*edits = append(*edits, yamlScalarEdit{original: original, edited: edited, flow: flow})
+		}
+		return true
+	}
+	flow = flow || original.Style&yaml.FlowStyle != 0
+	for i := range original.Content {
+		if !collectYamlEdits(original.Content[i], edited.Content[i], flow, edits) {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlScalarSpan finds the text of the scalar edit replaces in content, from its line and
+// column, and reports false unless that text is a quoted or plain scalar on one line that
+// parses back to the original value.
+func yamlScalarSpan(content []byte, edit yamlScalarEdit) (int, int, bool) {
+	start, ok := lineColumnOffset(content, edit.original.Line, edit.original.Column)
+	if !ok || start >= len(content) {
+		return 0, 0, false
+	}
+	end := start
+	switch content[start] {
+	case '"':
+		for end = start + 1; end < len(content) && content[end] != '"' && content[end] != '\n'; end++ {
+			if content[end] == '\\' {
+				end++
+			}
+		}
+		end++
+	case '\'':
+		for end = start + 1; end < len(content) && content[end] != '\n'; end++ {
+			if content[end] == '\'' {
+				if end+1 < len(content) && content[end+1] == '\'' {
+					end++
+					continue
+				}
+				break
+			}
+		}
+		end++
+	case '&', '*', '!', '|', '>':
+		return 0, 0, false
+	default:
+		for end < len(content) && content[end] != '\n' && content[end] != '\r' {
+			rest := content[end:]
+			if bytes.HasPrefix(rest, []byte(" #")) || bytes.HasPrefix(rest, []byte(": ")) || bytes.Equal(rest, []byte(":")) ||
+				bytes.HasPrefix(rest, []byte(":\n")) || edit.flow && bytes.IndexByte([]byte(",[]{}"), rest[0]) >= 0 {
+				break
+			}
+			end++
+		}
+		end = start + len(bytes.TrimRight(content[start:end], " \t"))
+	}
+	if end > len(content) || end <= start {
+		return 0, 0, false
+	}
+	var parsed yaml.Node
+	if err := yaml.Unmarshal(content[start:end], &parsed); err != nil || len(parsed.Content) != 1 ||
+		parsed.Content[0].Kind != yaml.ScalarNode || parsed.Content[0].Value != edit.original.Value {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// inlineYamlScalar renders a scalar as it is written inline, quoting plain text that holds a
+// flow indicator in flow collections, and reports false when it needs more than one line.
+func inlineYamlScalar(node *yaml.Node, flow bool) ([]byte, bool) {
+	scalar := &yaml.Node{Kind: yaml.ScalarNode, Tag: node.Tag, Value: node.Value, Style: node.Style}
+	if flow && scalar.Style == 0 && strings.ContainsAny(scalar.Value, ",[]{}") {
+		scalar.Style = yaml.SingleQuotedStyle
+	}
+	out, err := yaml.Marshal(scalar)
+	if err != nil {
+		return nil, false
+	}
+	out = bytes.TrimSuffix(out, []byte("\n"))
+	if len(out) == 0 || bytes.ContainsAny(out, "\n\r") {
+		return nil, false
+	}
+	return out, true
+}
+
+// lineColumnOffset returns the byte offset of a 1-based line and column, counted in
+// characters as yaml.v3 counts them.
+func lineColumnOffset(content []byte, line int, column int) (int, bool) {
+	offset := 0
+	for i := 1; i < line; i++ {
+		next := bytes.IndexByte(content[offset:], '\n')
+		if next < 0 {
+			return 0, false
+		}
+		offset += next + 1
+	}
+	for i := 1; i < column; i++ {
+		if offset >= len(content) || content[offset] == '\n' {
+			return 0, false
+		}
+		_, size := utf8.DecodeRune(content[offset:])
+		offset += size
+	}
+	return offset, true
+}
+
+// Save writes the edited documents back to the file they were opened from, keeping its permissions.
+// Returns an error if the editor was not opened from a file or the file cannot be written.
+func (e *YamlEditor) Save() error {
+	if e.file == "" {
+		return errors.New("editor was not opened from a file, use SaveAs")
+	}
+	return e.SaveAs(e.file)
+}
+
+// SaveAs atomically writes the edited documents to file, with the permissions of the file
+// the editor was opened from, or 0644.
+// Returns an error if the documents cannot be encoded or the file cannot be written.
+func (e *YamlEditor) SaveAs(file string) error {
+	content, err := e.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := fs.WriteFileAtomicWithOptions(file, content, fs.WriteOptions{Mode: e.mode}); err != nil {
+		return fmt.Errorf("file: [%s], error: [%w]", file, err)
+	}
+	return nil
+}
+
+// Node returns the node at path, following aliases to the anchored node.
+// Returns an error wrapping ErrPathNotFound if the path does not exist.
+func (d *YamlDocument) Node(path string) (*yaml.Node, error) {
+	current := d.root()
+	segments := splitDottedPath(path)
+	for i, segment := range segments {
+		child, _, err := yamlChild(current, segment)
+		if err != nil {
+			return nil, missingPath(path, segments[:i], "%v", err)
+		}
+		current = child
+	}
+	return current, nil
+}
+
+// Get decodes the value at path into t.
+// Returns an error if the path does not exist or the value cannot be decoded.
+func (d *YamlDocument) Get(path string, t interface{}) error {
+	node, err := d.Node(path)
+	if err != nil {
+		return err
+	}
+	if err := node.Decode(t); err != nil {
+		return fmt.Errorf("path: [%s], error: [error unmarshalling to %T, with error %w]", path, t, err)
+	}
+	return nil
+}
+
+// Set stores value, which may also be a *yaml.Node, at path. Missing mapping keys are
+// appended, creating intermediate mappings, and an index equal to a sequence's length
+// appends an item. Replacing a value keeps its comments and anchor, and its quoting style
+// when the new value has the same type. Values reached through an alias are changed on
+// the anchored node, so every alias sees the change.
+// Returns an error if the path runs through a scalar, an index is out of range, or the
+// value cannot be encoded.
+func (d *YamlDocument) Set(path string, value interface{}) error {
+	replacement, ok := value.(*yaml.Node)
+	if !ok {
+		replacement = &yaml.Node{}
+		if err := replacement.Encode(value); err != nil {
+			return fmt.Errorf("path: [%s], error: [error marshalling %T, with error %w]", path, value, err)
+		}
+	}
+
+	segments := splitDottedPath(path)
+	if len(segments) == 0 {
+		replaceYamlNode(d.root(), replacement)
+		return nil
+	}
+	current := d.root()
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		child, _, err := yamlChild(current, segment)
+		if err == nil {
+			if last {
+				replaceYamlNode(child, replacement)
+				return nil
+			}
+			current = child
+			continue
+		}
+		next := replacement
+		if !last {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		switch {
+		case current.Kind == yaml.MappingNode:
+			current.Content = append(current.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: segment}, next)
+		case current.Kind == yaml.SequenceNode && segment == strconv.Itoa(len(current.Content)):
+			current.Content = append(current.Content, next)
+		default:
+			return missingPath(path, segments[:i], "%v", err)
+		}
+		current = next
+	}
+	return nil
+}
+
+// Delete removes the key or item at path, along with the comments attached to it.
+// Returns an error wrapping ErrPathNotFound if the path does not exist.
+func (d *YamlDocument) Delete(path string) error {
+	segments := splitDottedPath(path)
+	if len(segments) == 0 {
+		return fmt.Errorf("path: [%s], error: [cannot delete the document root]", path)
+	}
+	parent, err := d.Node(strings.Join(escapeDottedSegments(segments[:len(segments)-1]), "."))
+	if err != nil {
+		return err
+	}
+	_, position, err := yamlChild(parent, segments[len(segments)-1])
+	if err != nil {
+		return missingPath(path, segments[:len(segments)-1], "%v", err)
+	}
+	if parent.Kind == yaml.MappingNode {
+		parent.Content = append(parent.Content[:position-1], parent.Content[position+1:]...)
+	} else {
+		parent.Content = append(parent.Content[:position], parent.Content[position+1:]...)
+	}
+	return nil
+}
+
+// root returns the top-level content node of the document.
+func (d *YamlDocument) root() *yaml.Node {
+	if d.node.Kind == yaml.DocumentNode && len(d.node.Content) > 0 {
+		return resolveAlias(d.node.Content[0])
+	}
+	return resolveAlias(d.node)
+}
+
+// yamlChild looks up a mapping key or sequence index in node, returning the child, with
+// aliases resolved, and its position in node.Content. The last matching key wins, as on decode.
+func yamlChild(node *yaml.Node, segment string) (*yaml.Node, int, error) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := len(node.Content) - 2; i >= 0; i -= 2 {
+			if node.Content[i].Value == segment {
+				return resolveAlias(node.Content[i+1]), i + 1, nil
+			}
+		}
+		return nil, 0, fmt.Errorf("key %q does not exist", segment)
+	case yaml.SequenceNode:
+		index, err := strconv.Atoi(segment)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%q is not an index into a sequence", segment)
+		}
+		if index < 0 {
+			index += len(node.Content)
+		}
+		if index < 0 || index >= len(node.Content) {
+			return nil, 0, fmt.Errorf("index %s out of range for sequence of length %d", segment, len(node.Content))
+		}
+		return resolveAlias(node.Content[index]), index, nil
+	}
+	return nil, 0, fmt.Errorf("cannot look up %q in a scalar", segment)
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// replaceYamlNode overwrites target's value with replacement's, keeping target's comments
+// and anchor, and its style when the type is unchanged.
+func replaceYamlNode(target *yaml.Node, replacement *yaml.Node) {
+	if replacement.Kind == yaml.DocumentNode && len(replacement.Content) > 0 {
+		replacement = replacement.Content[0]
+	}
+	style := replacement.Style
+	if style == 0 && target.Kind == replacement.Kind && target.ShortTag() == replacement.ShortTag() {
+		style = target.Style
+	}
+	target.Kind = replacement.Kind
+	target.Tag = replacement.Tag
+	target.Value = replacement.Value
+	target.Content = replacement.Content
+	target.Alias = replacement.Alias
+	target.Style = style
+}
+
+func escapeDottedSegments(segments []string) []string {
+	escaped := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		escaped = append(escaped, strings.ReplaceAll(segment, ".", `\.`))
+	}
+	return escaped
+}
+
+// detectYamlIndent returns the smallest step by which a line is indented deeper than the
+// line before it, ignoring blank lines and comments and counting a "- " item marker as
+// part of the indentation of the lines that follow it.
+func detectYamlIndent(content []byte) int {
+	indent := 0
+	previous := 0
+	for _, line := range strings.Split(string(content), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		depth := len(line) - len(trimmed)
+		if step := depth - previous; step > 0 && (indent == 0 || step < indent) {
+			indent = step
+		}
+		previous = depth
+		if strings.HasPrefix(trimmed, "- ") {
+			previous = depth + 2
+		}
+	}
+	if indent < 2 {
+		return DefaultYamlIndent
+	}
+	return indent
+}
@@ -0,0 +1,269 @@
+package converters
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const editorDocument = `# deployment settings
+name: api # service name
+defaults: &defaults
+  replicas: 2
+  image:
+    repository: example/api
+    tag: "v1" # bumped by automation
+services:
+  - name: web
+    settings: *defaults
+  - name: worker
+zone: eu
+`
+
+func TestYamlEditor(t *testing.T) {
+	t.Run("set preserves comments, order, anchors and quoting", func(t *testing.T) {
+		editor, err := NewYamlEditor([]byte(editorDocument))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := editor.Set("defaults.image.tag", "v2"); err != nil {
+			t.Fatal(err)
+		}
+		if err := editor.Set("services.1.replicas", 3); err != nil {
+			t.Fatal(err)
+		}
+		if err := editor.Set("services.2", map[string]string{"name": "cron"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := editor.Set("monitoring.alerts.enabled", true); err != nil {
+			t.Fatal(err)
+		}
+		content, err := editor.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := `# deployment settings
+name: api # service name
+defaults: &defaults
+  replicas: 2
+  image:
+    repository: example/api
+    tag: "v2" # bumped by automation
+services:
+  - name: web
+    settings: *defaults
+  - name: worker
+    replicas: 3
+  - name: cron
+zone: eu
+monitoring:
+  alerts:
+    enabled: true
+`
+		if string(content) != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, content)
+		}
+
+		var tag string
+		if err := editor.Get("services.0.settings.image.tag", &tag); err != nil || tag != "v2" {
+			t.Errorf("Expected the alias to see the change, got %q, %v", tag, err)
+		}
+	})
+
+	t.Run("type change drops the old quoting", func(t *testing.T) {
+		editor, _ := NewYamlEditor([]byte("port: \"8080\"\n"))
+		_ = editor.Set("port", 9090)
+		content, _ := editor.Bytes()
+		if string(content) != "port: 9090\n" {
+			t.Errorf("Unexpected output %q", content)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		editor, _ := NewYamlEditor([]byte(editorDocument))
+		if err := editor.Delete("name"); err != nil {
+			t.Fatal(err)
+		}
+		if err := editor.Delete("services.0"); err != nil {
+			t.Fatal(err)
+		}
+		if err := editor.Delete("defaults.image.repository"); err != nil {
+			t.Fatal(err)
+		}
+		content, _ := editor.Bytes()
+		expected := `defaults: &defaults
+  replicas: 2
+  image:
+    tag: "v1" # bumped by automation
+services:
+  - name: worker
+zone: eu
+`
+		if string(content) != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, content)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		editor, _ := NewYamlEditor([]byte(editorDocument))
+		for path, expected := range map[string]string{
+			"services.5":      "index 5 out of range for sequence of length 2 at services",
+			"missing.key":     `key "missing" does not exist at $`,
+			"name.first":      `cannot look up "first" in a scalar at name`,
+			"services.first":  `"first" is not an index into a sequence at services`,
+			"defaults.absent": `key "absent" does not exist at defaults`,
+		} {
+			if err := editor.Delete(path); !errors.Is(err, ErrPathNotFound) || !strings.Contains(err.Error(), expected) {
+				t.Errorf("Delete %s: expected %q, got %v", path, expected, err)
+			}
+		}
+		if err := editor.Set("name.first", 1); err == nil || !strings.Contains(err.Error(), "in a scalar") {
+			t.Errorf("Expected scalar error, got %v", err)
+		}
+		if err := editor.Set("services.4", 1); err == nil || !strings.Contains(err.Error(), "out of range") {
+			t.Errorf("Expected range error, got %v", err)
+		}
+		if err := editor.Delete(""); err == nil {
+			t.Error("Expected error deleting the root")
+		}
+		if err := editor.Save(); err == nil {
+			t.Error("Expected error saving an editor without a file")
+		}
+		if _, err := NewYamlEditor([]byte("a: [")); err == nil || !strings.Contains(err.Error(), "document: [1]") {
+			t.Errorf("Expected parse error, got %v", err)
+		}
+	})
+
+	t.Run("file round trip", func(t *testing.T) {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "values.yaml")
+		original := "# first\nimage:\n    tag: v1\n---\n# second\nkind: Service\nspec:\n    ports:\n    - port: 80\n"
+		if err := os.WriteFile(file, []byte(original), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		editor, err := OpenYamlEditor(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if editor.Documents() != 2 {
+			t.Fatalf("Expected 2 documents, got %d", editor.Documents())
+		}
+		_ = editor.Set("image.tag", "v2")
+		second, err := editor.Document(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := second.Set("spec.ports.0.port", 8080); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := editor.Document(2); !errors.Is(err, ErrPathNotFound) {
+			t.Errorf("Expected missing document error, got %v", err)
+		}
+		if err := editor.Save(); err != nil {
+			t.Fatal(err)
+		}
+		content, _ := os.ReadFile(file)
+		expected := "# first\nimage:\n    tag: v2\n---\n# second\nkind: Service\nspec:\n    ports:\n    - port: 8080\n"
+		if string(content) != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, content)
+		}
+		info, _ := os.Stat(file)
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("Expected permissions to be kept, got %v", info.Mode().Perm())
+		}
+		if _, err := OpenYamlEditor(filepath.Join(dir, "missing.yaml")); err == nil {
+			t.Error("Expected error for missing file")
+		}
+	})
+
+	t.Run("scalar edits keep the original layout", func(t *testing.T) {
+		manifest := `apiVersion: apps/v1
+kind: Deployment
+
+metadata:
+  name: api   # kept as written
+spec:
+  template:
+    spec:
+      containers:
+      - name: api
+        image: 'example/api:1.0'
+        args: [--port, "8080"]
+
+      - name: sidecar
+        image: example/proxy:2.1 #pinned
+`
+		editor, err := NewYamlEditor([]byte(manifest))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := editor.Set("spec.template.spec.containers.0.image", "example/api:1.1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := editor.Set("spec.template.spec.containers.0.args.1", "9090"); err != nil {
+			t.Fatal(err)
+		}
+		if err := editor.Set("spec.template.spec.containers.1.image", "example/proxy:2.2"); err != nil {
+			t.Fatal(err)
+		}
+		content, err := editor.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := strings.NewReplacer("api:1.0", "api:1.1", `"8080"`, `"9090"`, "proxy:2.1", "proxy:2.2").Replace(manifest)
+		if string(content) != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, content)
+		}
+	})
+
+	t.Run("scalar edits in later documents", func(t *testing.T) {
+		original := "a: 1\n\n---\n# second\nb:   x # note\n---\nc: [1, 2]\n"
+		editor, _ := NewYamlEditor([]byte(original))
+		second, _ := editor.Document(1)
+		third, _ := editor.Document(2)
+		_ = second.Set("b", "y")
+		_ = third.Set("c.1", "a, b")
+		content, err := editor.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "a: 1\n\n---\n# second\nb:   \"y\" # note\n---\nc: [1, 'a, b']\n"
+		if string(content) != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, content)
+		}
+	})
+
+	t.Run("structural edits re-encode with detected indentation", func(t *testing.T) {
+		// Adding or deleting keys re-encodes the document, which drops blank lines, indents
+		// sequences under their key and normalises comment spacing.
+		original := "spec:\n    ports:\n    - port: 80   # http\n\nname: api\n"
+		editor, _ := NewYamlEditor([]byte(original))
+		if err := editor.Set("spec.ports.0.protocol", "TCP"); err != nil {
+			t.Fatal(err)
+		}
+		content, err := editor.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "spec:\n    ports:\n        - port: 80 # http\n          protocol: TCP\nname: api\n"
+		if string(content) != expected {
+			t.Errorf("Expected:\n%s\ngot:\n%s", expected, content)
+		}
+	})
+
+	t.Run("empty content and nodes", func(t *testing.T) {
+		editor, _ := NewYamlEditor(nil)
+		node := &yaml.Node{Kind: yaml.ScalarNode, Value: "x", Style: yaml.SingleQuotedStyle}
+		if err := editor.Set("a.b", node); err != nil {
+			t.Fatal(err)
+		}
+		content, _ := editor.Bytes()
+		if string(content) != "a:\n  b: 'x'\n" {
+			t.Errorf("Unexpected output %q", content)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+// Package fs provides utilities for file system operations, including directory management,
+// file reading, and zip file handling.
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DefaultFileMode is the permission used for files written without an explicit mode.
+const DefaultFileMode os.FileMode = 0o644
+
+// DefaultDirMode is the permission used for directories created on demand.
+const DefaultDirMode os.FileMode = 0o755
+
+// DefaultBackupSuffix is appended to a file's name to form its backup's name.
+const DefaultBackupSuffix = ".bak"
+
+// WriteOptions controls how WriteFileAtomicWithOptions writes a file.
+type WriteOptions struct {
+	// Mode is the permission of the written file. When zero, an existing file keeps its
+	// permission and a new file gets DefaultFileMode.
+	Mode os.FileMode
+	// Backup keeps the previous content of an existing file next to it, named with BackupSuffix.
+	Backup bool
+	// BackupSuffix names the backup, DefaultBackupSuffix when empty.
+	BackupSuffix string
+	// CreateDirs creates missing parent directories with DefaultDirMode.
+	CreateDirs bool
+}
+
+// WriteFileAtomic writes data to a file, keeping its permission or using DefaultFileMode for
+// a new file, so that readers see either the previous content or the new content and never
+// a partial write.
+// Returns an error if the file cannot be written.
+func WriteFileAtomic(name string, data []byte) error {
+	return WriteFileAtomicWithOptions(name, data, WriteOptions{})
+}
+
+// WriteFileAtomicWithOptions writes data to a temporary file in the target's directory,
+// syncs it to disk and renames it over the target, then syncs the directory so the rename
+// survives a crash. The temporary file is removed if any step fails. A symlinked target is
+// resolved first, so the file it points to is replaced and the link is kept.
+// Returns an error if a directory, the backup or the file cannot be written.
+func WriteFileAtomicWithOptions(name string, data []byte, opts WriteOptions) error {
+	target, err := resolveSymlinks(name)
+	if err != nil {
+		return err
+	}
+	mode := opts.Mode
+	if mode == 0 {
+		mode = DefaultFileMode
+		if info, err := os.Stat(target); err == nil {
+			mode = info.Mode().Perm()
+		}
+	}
+	dir := filepath.Dir(target)
+	if opts.CreateDirs {
+		if err := os.MkdirAll(dir, DefaultDirMode); err != nil {
+			return err
+		}
+	}
+	if opts.Backup {
+		if err := backupFile(name, opts.BackupSuffix); err != nil {
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			CloseSafely(tmp)
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return err
+	}
+	committed = true
+	syncDir(dir)
+	return nil
+}
+
+// resolveSymlinks follows name through any symlinks to the file they point to, which need
+// not exist yet. Relative links are resolved from the directory holding the link.
+func resolveSymlinks(name string) (string, error) {
+	for i := 0; i < 255; i++ {
+		info, err := os.Lstat(name)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			return name, nil
+		}
+		link, err := os.Readlink(name)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(link) {
+			link = filepath.Join(filepath.Dir(name), link)
+		}
+		name = link
+	}
+	return "", fmt.Errorf("too many symlinks resolving %s", name)
+}
+
+// backupFile atomically copies an existing file, with its permissions, to name+suffix.
+// A missing file needs no backup.
+func backupFile(name string, suffix string) error {
+	info, err := os.Stat(name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	content, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if suffix == "" {
+		suffix = DefaultBackupSuffix
+	}
+	if err := WriteFileAtomicWithOptions(name+suffix, content, WriteOptions{Mode: info.Mode().Perm()}); err != nil {
+		return fmt.Errorf("backup of %s failed: %w", name, err)
+	}
+	return nil
+}
+
+// syncDir flushes a directory entry to disk. Platforms that cannot sync directories are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	defer CloseSafely(d)
+	_ = d.Sync()
+}
@@ -0,0 +1,183 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	t.Run("write new file with default mode", func(t *testing.T) {
+		tempDir := t.TempDir()
+		testFile := filepath.Join(tempDir, "config.yaml")
+
+		if err := WriteFileAtomic(testFile, []byte("a: 1\n")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		content, err := os.ReadFile(testFile)
+		if err != nil || string(content) != "a: 1\n" {
+			t.Errorf("Unexpected content %q, %v", content, err)
+		}
+		info, _ := os.Stat(testFile)
+		if info.Mode().Perm() != DefaultFileMode {
+			t.Errorf("Expected mode %v, got %v", DefaultFileMode, info.Mode().Perm())
+		}
+		entries, _ := os.ReadDir(tempDir)
+		if len(entries) != 1 {
+			t.Errorf("Expected no temporary files to remain, got %d entries", len(entries))
+		}
+	})
+
+	t.Run("replace with mode and backup", func(t *testing.T) {
+		tempDir := t.TempDir()
+		testFile := filepath.Join(tempDir, "config.yaml")
+		if err := os.WriteFile(testFile, []byte("old"), 0o640); err != nil {
+			t.Fatal(err)
+		}
+
+		err := WriteFileAtomicWithOptions(testFile, []byte("new"), WriteOptions{Mode: 0o600, Backup: true})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		content, _ := os.ReadFile(testFile)
+		if string(content) != "new" {
+			t.Errorf("Expected new content, got %q", content)
+		}
+		info, _ := os.Stat(testFile)
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+		}
+		backup, err := os.ReadFile(testFile + DefaultBackupSuffix)
+		if err != nil || string(backup) != "old" {
+			t.Errorf("Expected backup of the old content, got %q, %v", backup, err)
+		}
+		backupInfo, _ := os.Stat(testFile + DefaultBackupSuffix)
+		if backupInfo.Mode().Perm() != 0o640 {
+			t.Errorf("Expected backup to keep mode 0640, got %v", backupInfo.Mode().Perm())
+		}
+	})
+
+	t.Run("replace keeps the existing mode", func(t *testing.T) {
+		tempDir := t.TempDir()
+		testFile := filepath.Join(tempDir, "secrets.env")
+		if err := os.WriteFile(testFile, []byte("old"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteFileAtomic(testFile, []byte("new")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		info, _ := os.Stat(testFile)
+		if info.Mode().Perm() != 0o600 {
+			t.Errorf("Expected mode 0600 to be kept, got %v", info.Mode().Perm())
+		}
+	})
+
+	t.Run("replace through a symlink keeps the link", func(t *testing.T) {
+		tempDir := t.TempDir()
+		if err := os.MkdirAll(filepath.Join(tempDir, "shared"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		realFile := filepath.Join(tempDir, "shared", "config.yaml")
+		if err := os.WriteFile(realFile, []byte("old"), 0o640); err != nil {
+			t.Fatal(err)
+		}
+		link := filepath.Join(tempDir, "config.yaml")
+		if err := os.Symlink(filepath.Join("shared", "config.yaml"), link); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := WriteFileAtomic(link, []byte("new")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		info, err := os.Lstat(link)
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			t.Fatalf("Expected the symlink to be kept, got %v, %v", info, err)
+		}
+		content, _ := os.ReadFile(realFile)
+		if string(content) != "new" {
+			t.Errorf("Expected the linked file to be replaced, got %q", content)
+		}
+		realInfo, _ := os.Stat(realFile)
+		if realInfo.Mode().Perm() != 0o640 {
+			t.Errorf("Expected mode 0640 to be kept, got %v", realInfo.Mode().Perm())
+		}
+
+		dangling := filepath.Join(tempDir, "new.yaml")
+		if err := os.Symlink("created.yaml", dangling); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteFileAtomic(dangling, []byte("created")); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		content, _ = os.ReadFile(filepath.Join(tempDir, "created.yaml"))
+		if string(content) != "created" {
+			t.Errorf("Expected the link target to be created, got %q", content)
+		}
+	})
+
+	t.Run("backup with custom suffix skips missing file", func(t *testing.T) {
+		tempDir := t.TempDir()
+		testFile := filepath.Join(tempDir, "config.yaml")
+		opts := WriteOptions{Backup: true, BackupSuffix: ".orig"}
+
+		if err := WriteFileAtomicWithOptions(testFile, []byte("first"), opts); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := os.Stat(testFile + ".orig"); !os.IsNotExist(err) {
+			t.Error("Expected no backup for a new file")
+		}
+		if err := WriteFileAtomicWithOptions(testFile, []byte("second"), opts); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		backup, _ := os.ReadFile(testFile + ".orig")
+		if string(backup) != "first" {
+			t.Errorf("Expected backup of the first write, got %q", backup)
+		}
+	})
+
+	t.Run("create directories on demand", func(t *testing.T) {
+		tempDir := t.TempDir()
+		testFile := filepath.Join(tempDir, "a", "b", "config.json")
+
+		if err := WriteFileAtomic(testFile, []byte("{}")); err == nil {
+			t.Error("Expected error for missing directory")
+		}
+		if err := WriteFileAtomicWithOptions(testFile, []byte("{}"), WriteOptions{CreateDirs: true}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		info, _ := os.Stat(filepath.Dir(testFile))
+		if !info.IsDir() || info.Mode().Perm() != DefaultDirMode {
+			t.Errorf("Expected directory with mode %v, got %v", DefaultDirMode, info.Mode())
+		}
+	})
+
+	t.Run("error when directory cannot be created", func(t *testing.T) {
+		tempDir := t.TempDir()
+		blocker := filepath.Join(tempDir, "file")
+		if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		err := WriteFileAtomicWithOptions(filepath.Join(blocker, "config.json"), []byte("{}"), WriteOptions{CreateDirs: true})
+		if err == nil {
+			t.Error("Expected error when a parent is a file")
+		}
+	})
+
+	t.Run("error when target is a directory", func(t *testing.T) {
+		tempDir := t.TempDir()
+		target := filepath.Join(tempDir, "target")
+		if err := os.MkdirAll(filepath.Join(target, "child"), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := WriteFileAtomic(target, []byte("{}")); err == nil {
+			t.Error("Expected error replacing a non-empty directory")
+		}
+		if err := WriteFileAtomicWithOptions(target, []byte("{}"), WriteOptions{Backup: true}); err == nil {
+			t.Error("Expected error backing up a directory")
+		}
+		entries, _ := os.ReadDir(tempDir)
+		if len(entries) != 1 {
+			t.Errorf("Expected temporary files to be removed, got %d entries", len(entries))
+		}
+	})
+}
@@ -0,0 +1,32 @@
+// Package types provides custom types and their implementations, including
+// specialized types for regular expressions and other data structures.
+package types
+
+import "fmt"
+
+// Redacted is the text that secrets are printed and masked as.
+const Redacted = "***"
+
+// Secret is a string, such as a password or token, that prints as Redacted with fmt, so
+// logging it with %v or %+v does not leak it. It marshals its real value, so files written
+// with it read back; converters.Redact, and the Redact options of converters' JSON and YAML
+// marshaling, mask it as Redacted for logs. An empty secret stays empty.
+type Secret string
+
+// Value returns the secret's real value.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String implements fmt.Stringer, hiding the secret's value.
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return Redacted
+}
+
+// GoString implements fmt.GoStringer, hiding the secret's value from %#v.
+func (s Secret) GoString() string {
+	return fmt.Sprintf("types.Secret(%q)", s.String())
+}
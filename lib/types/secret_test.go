@@ -0,0 +1,62 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+type secretConfig struct {
+	User     string `json:"user" yaml:"user"`
+	Password Secret `json:"password" yaml:"password"`
+	Empty    Secret `json:"empty" yaml:"empty"`
+}
+
+func TestSecret(t *testing.T) {
+	t.Run("marshal keeps the value", func(t *testing.T) {
+		config := secretConfig{User: "admin", Password: "hunter2"}
+
+		data, err := json.Marshal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != `{"user":"admin","password":"hunter2","empty":""}` {
+			t.Errorf("Unexpected json %s", data)
+		}
+
+		data, err = yaml.Marshal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "user: admin\npassword: hunter2\nempty: \"\"\n" {
+			t.Errorf("Unexpected yaml %q", data)
+		}
+	})
+
+	t.Run("printing hides the value", func(t *testing.T) {
+		secret := Secret("hunter2")
+		for format, expected := range map[string]string{"%v": Redacted, "%s": Redacted, "%+v": Redacted, "%#v": `types.Secret("***")`} {
+			if text := fmt.Sprintf(format, secret); text != expected {
+				t.Errorf("Format %s: expected %s, got %s", format, expected, text)
+			}
+		}
+		if text := fmt.Sprintf("%+v", secretConfig{Password: "hunter2"}); text != "{User: Password:*** Empty:}" {
+			t.Errorf("Unexpected struct output %s", text)
+		}
+	})
+
+	t.Run("unmarshal keeps the value", func(t *testing.T) {
+		var fromJson, fromYaml secretConfig
+		if err := json.Unmarshal([]byte(`{"password":"hunter2"}`), &fromJson); err != nil {
+			t.Fatal(err)
+		}
+		if err := yaml.Unmarshal([]byte("password: hunter2\n"), &fromYaml); err != nil {
+			t.Fatal(err)
+		}
+		if fromJson.Password.Value() != "hunter2" || fromYaml.Password.Value() != "hunter2" {
+			t.Errorf("Expected real values, got %q and %q", fromJson.Password.Value(), fromYaml.Password.Value())
+		}
+	})
+}